@@ -12,10 +12,12 @@ import (
 
 var (
 	configPath string
+	migrate    bool
 )
 
 func init() {
 	flag.StringVar(&configPath, "config", "config/acg_dev.json", "Path to config file")
+	flag.BoolVar(&migrate, "migrate", false, "Apply pending database migrations and exit")
 }
 
 func main() {
@@ -40,6 +42,15 @@ func main() {
 	}
 
 	s := acg.NewServer(config)
+
+	if migrate {
+		if err := s.Migrate(); err != nil {
+			log.Fatalf("[ERROR] %v\n", err)
+		}
+
+		return
+	}
+
 	if err := s.Start(); err != nil {
 		log.Fatalf("[ERROR] %v\n", err)
 	}
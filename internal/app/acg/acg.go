@@ -1,38 +1,81 @@
 package acg
 
 import (
+	"context"
+	"fmt"
+	"html/template"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/go-pkgz/lgr"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/render"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+	"github.com/the-NZA/acg-nikolaev/internal/app/search"
+	"github.com/the-NZA/acg-nikolaev/internal/app/slug"
 	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/cachestore"
 	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongostore"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/redirectstore"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/sqlstore"
+	"github.com/the-NZA/acg-nikolaev/internal/app/typograph"
 )
 
+// viewsGlob matches templates of public pages relative to working directory of server
+const viewsGlob = "internal/*/views/*.gohtml"
+
 // Server contains all things to run website
 type Server struct {
-	config *Config
-	logger *lgr.Logger
-	router *chi.Mux
-	store  store.Storer
+	config     *Config
+	logger     *lgr.Logger
+	router     *chi.Mux
+	store      store.Storer
+	cache      *cachestore.CacheStore // Cache of store reads, nil when disabled
+	search     *search.Store          // Search indexer wrapping store
+	slugs      *slug.Generator
+	typograph  typograph.Mode
+	sanitizer  *sanitize.Policy   // Policy of inline markup of content with host of site
+	render     *render.Registry   // Renderer of content blocks used by views
+	tmpl       *template.Template // Views of public pages
+	httpServer *http.Server
+	jobs       sync.WaitGroup     // Background jobs started by Start
+	stopJobs   context.CancelFunc // Cancels context of background jobs
 }
 
 // NewServer returns Server object with router, logger and config
 func NewServer(config *Config) *Server {
-	return &Server{
+	s := &Server{
 		config: config,
 		router: chi.NewRouter(),
 	}
+
+	s.logger = s.configureLogger(config.LogDebug)
+
+	s.httpServer = &http.Server{
+		Addr:           config.BindAddr,
+		Handler:        s.router,
+		ReadTimeout:    time.Duration(config.HTTPReadTimeout) * time.Second,
+		WriteTimeout:   time.Duration(config.HTTPWriteTimeout) * time.Second,
+		IdleTimeout:    time.Duration(config.HTTPIdleTimeout) * time.Second,
+		MaxHeaderBytes: config.HTTPMaxHeaderBytes,
+	}
+
+	return s
 }
 
 func (s *Server) configureRouter() {
 	// CORS
 	s.router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match"},
+		ExposedHeaders:   []string{"Link", "ETag"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -54,6 +97,8 @@ func (s *Server) configureRouter() {
 
 	s.router.Get("/contacts", s.handleContactsPage())
 
+	s.router.Get("/search", s.handleSearchPage())
+
 	s.router.Route("/posts", func(r chi.Router) {
 		r.Get("/", s.handlePostsPage())
 	})
@@ -69,6 +114,10 @@ func (s *Server) configureRouter() {
 		r.Get("/{categorySlug:[a-z0-9_-]+}/{postSlug:[a-z0-9_-]+}", s.handleSinglePostPage())
 	})
 
+	s.router.Get("/tag/{tagSlug:[a-z0-9_-]+}", s.handleSingleTagPage())
+
+	s.router.Get("/author/{authorSlug:[a-z0-9_-]+}", s.handleAuthorPage())
+
 	s.router.Get("/404", func(w http.ResponseWriter, r *http.Request) {
 		s.respond(w, r, http.StatusNotFound, map[string]string{
 			"page": "not found",
@@ -80,6 +129,11 @@ func (s *Server) configureRouter() {
 
 	// Not Found
 	s.router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		// Pages are found by whole URL
+		if s.redirectOldURL(w, r, models.RedirectPage, r.URL.Path) {
+			return
+		}
+
 		s.logger.Logf("[DEBUG] 404 at %v\n", r.URL.Path)
 		s.respond(w, r, http.StatusNotFound, map[string]string{
 			"page": "not found",
@@ -89,6 +143,11 @@ func (s *Server) configureRouter() {
 	// Not Found END
 
 	// API Routes
+	bins := s.trashBins()
+	docs := s.revisionedDocs()
+	patches := s.patchables()
+	reviews := s.reviewables()
+
 	s.router.Route("/api", func(r chi.Router) {
 		// ! REMOVE BEFORE GOING LIVE
 		if !s.config.LogDebug {
@@ -100,13 +159,18 @@ func (s *Server) configureRouter() {
 		})
 
 		r.Post("/upload", s.handleUpload())
+		r.Get("/cache", s.handleCacheStats())
+		r.Get("/search", s.handleSearch())
 
 		r.Route("/category", func(r chi.Router) {
 			r.Get("/", s.handleCategoryGetByID())
 			r.Post("/", s.handleCategoryCreate())
 			r.Put("/", s.handleCategoryUpdate())
+			r.Patch("/", s.handlePatch(patches["category"]))
 			r.Delete("/", s.handleCategoryDelete())
 			r.Get("/all", s.handleCategoryGetAll())
+
+			s.mountTrash(r, bins["category"])
 		})
 
 		r.Route("/post", func(r chi.Router) {
@@ -114,16 +178,25 @@ func (s *Server) configureRouter() {
 			r.Post("/", s.handlePostCreate())
 			r.Delete("/", s.handlePostDelete())
 			r.Put("/", s.handlePostUpdate())
+			r.Patch("/", s.handlePatch(patches["post"]))
 			r.Get("/all", s.handlePostGetAll())
 			r.Get("/count", s.handlePostCount())
+			r.Get("/calendar", s.handlePostCalendar())
+
+			s.mountTrash(r, bins["post"])
+			s.mountRevisions(r, docs["post"])
+			s.mountReview(r, reviews["post"])
 		})
 
 		r.Route("/service", func(r chi.Router) {
 			r.Get("/", s.handleServiceGetByID())
 			r.Post("/", s.handleServiceCreate())
 			r.Put("/", s.handleServiceUpdate())
+			r.Patch("/", s.handlePatch(patches["service"]))
 			r.Delete("/", s.handleServiceDelete())
 			r.Get("/all", s.handleServiceGetAll())
+
+			s.mountTrash(r, bins["service"])
 		})
 
 		r.Route("/matcategory", func(r chi.Router) {
@@ -131,7 +204,10 @@ func (s *Server) configureRouter() {
 			r.Get("/", s.handleMatCategoryGetByID())
 			r.Delete("/", s.handleMatCategoryDelete())
 			r.Put("/", s.handleMatCategoryUpdate())
+			r.Patch("/", s.handlePatch(patches["matcategory"]))
 			r.Get("/all", s.handleMatCategoryGetAll())
+
+			s.mountTrash(r, bins["matcategory"])
 		})
 
 		r.Route("/material", func(r chi.Router) {
@@ -139,8 +215,11 @@ func (s *Server) configureRouter() {
 			r.Get("/", s.handleMaterialGetByID())
 			r.Delete("/", s.handleMaterialDelete())
 			r.Put("/", s.handleMaterialUpdate())
+			r.Patch("/", s.handlePatch(patches["material"]))
 			r.Get("/all", s.handleMaterialGetAll())
 			r.Get("/count", s.handleMaterialCount())
+
+			s.mountTrash(r, bins["material"])
 		})
 
 		r.Route("/page", func(r chi.Router) {
@@ -149,12 +228,30 @@ func (s *Server) configureRouter() {
 			r.Post("/", s.handlePageCreate())
 			r.Delete("/", s.handlePageDelete())
 			r.Put("/", s.handlePageUpdate())
+			r.Patch("/", s.handlePatch(patches["page"]))
 			r.Get("/all", s.handlePageGetAll())
+
+			s.mountTrash(r, bins["page"])
+			s.mountRevisions(r, docs["page"])
+			s.mountReview(r, reviews["page"])
+		})
+
+		r.Route("/tag", func(r chi.Router) {
+			r.Get("/", s.handleTagGetByID())
+			r.Post("/", s.handleTagCreate())
+			r.Put("/", s.handleTagUpdate())
+			r.Patch("/", s.handlePatch(patches["tag"]))
+			r.Delete("/", s.handleTagDelete())
+			r.Get("/all", s.handleTagGetAll())
+
+			s.mountTrash(r, bins["tag"])
 		})
 
 		r.Route("/user", func(r chi.Router) {
 			r.Post("/", s.handleUserCreate())
 			r.Delete("/", s.handleUserDelete())
+			r.Get("/author", s.handleAuthorGet())
+			r.Put("/author", s.handleAuthorUpdate())
 		})
 	})
 	// API END
@@ -170,7 +267,22 @@ func (s *Server) configureRouter() {
 
 // configureStore creates new Store and try to establish connection
 func (s *Server) configureStore() error {
-	st, err := mongostore.NewStore(s.config.DatabaseURL)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.DBConnectTimeout)*time.Second)
+	defer cancel()
+
+	var (
+		st  store.Storer
+		err error
+	)
+
+	switch s.config.DatabaseDriver {
+	case "", "mongodb":
+		st, err = mongostore.NewStore(ctx, s.config.DatabaseURL, s.config.storeTimeouts())
+	case "sqlite", "postgres":
+		st, err = sqlstore.NewStore(ctx, s.config.DatabaseDriver, s.config.DatabaseURL, s.config.storeTimeouts())
+	default:
+		err = fmt.Errorf("unknown db_driver %q", s.config.DatabaseDriver)
+	}
 	if err != nil {
 		return err
 	}
@@ -179,6 +291,106 @@ func (s *Server) configureStore() error {
 	return nil
 }
 
+// configureSlugs creates generator of slugs with standard from config
+func (s *Server) configureSlugs() error {
+	standard, err := slug.ParseStandard(s.config.SlugStandard)
+	if err != nil {
+		return err
+	}
+
+	s.slugs = slug.New(standard, s.config.SlugMaxLength)
+	return nil
+}
+
+// configureTypograph sets when typography is applied to content by mode from config
+func (s *Server) configureTypograph() error {
+	mode, err := typograph.ParseMode(s.config.Typograph)
+	if err != nil {
+		return err
+	}
+
+	s.typograph = mode
+	return nil
+}
+
+// configureSanitizer creates policy of content markup with host of site, links to other hosts in content are external
+func (s *Server) configureSanitizer() {
+	s.sanitizer = sanitize.New(s.config.AppDomain)
+}
+
+// configureTemplates parses views with renderer of blocks, which sanitizes them by policy of server
+// and applies typography when config asks for it on render
+func (s *Server) configureTemplates() error {
+	s.render = render.NewRegistry(s.sanitizer)
+	s.render.Typograph = s.typograph == typograph.OnRender
+
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"blocks":    s.render.Render,
+		"typograph": s.render.Text,
+	}).ParseGlob(viewsGlob)
+	if err != nil {
+		return err
+	}
+
+	s.tmpl = tmpl
+	return nil
+}
+
+// configureRedirects wraps store with recorder of old slugs
+// Recorder is the innermost decorator, so its transactions don't request rebuild of search index
+func (s *Server) configureRedirects() {
+	s.store = redirectstore.NewStore(s.store)
+}
+
+// configureCache wraps store with cache of reads when it is enabled by config
+// It is done after store is migrated, because cache hides Migrator of wrapped store
+func (s *Server) configureCache() {
+	if s.config.CacheSize <= 0 {
+		return
+	}
+
+	s.cache = cachestore.NewStore(s.store, s.config.CacheSize, time.Duration(s.config.CacheTTL)*time.Second)
+	s.store = s.cache
+}
+
+// migrateStore applies pending migrations and then creates indexes if store manages its schema
+// Migrations go first, because they bring data and old indexes to state which new index specs expect
+func (s *Server) migrateStore() error {
+	m, ok := s.store.(store.Migrator)
+	if !ok {
+		s.logger.Logf("[INFO] Store has no migrations\n")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.DBMigrateTimeout)*time.Second)
+	defer cancel()
+
+	applied, err := m.Migrate(ctx)
+	for _, desc := range applied {
+		s.logger.Logf("[INFO] Applied migration %s\n", desc)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		s.logger.Logf("[INFO] Database is up to date\n")
+	}
+
+	return m.EnsureIndexes(ctx)
+}
+
+// Migrate applies pending store migrations, creates indexes and closes the store
+// Start does the same, it is used to upgrade database before deploying new version
+func (s *Server) Migrate() error {
+	if err := s.configureStore(); err != nil {
+		return err
+	}
+	defer s.closeStore()
+
+	return s.migrateStore()
+}
+
 // newLogger configure logger in DEBUG or PRODUCTION mode
 // Possible log levels TRACE, DEBUG, INFO, WARN, ERROR, PANIC and FATAL
 func (s *Server) configureLogger(dbg bool) *lgr.Logger {
@@ -190,8 +402,25 @@ func (s *Server) configureLogger(dbg bool) *lgr.Logger {
 }
 
 // Start performs pre-run configuration and starts server
+// It blocks until SIGINT or SIGTERM is received or Shutdown is called
 func (s *Server) Start() error {
-	s.logger = s.configureLogger(s.config.LogDebug)
+	if err := s.configureSlugs(); err != nil {
+		return err
+	}
+
+	if err := s.configureTypograph(); err != nil {
+		return err
+	}
+
+	s.configureSanitizer()
+
+	if err := s.configureTemplates(); err != nil {
+		return err
+	}
+
+	if len(s.config.Reviewers) == 0 {
+		s.logger.Logf("[WARN] No reviewers are configured, submitted content can't be approved\n")
+	}
 
 	s.configureRouter()
 
@@ -199,7 +428,114 @@ func (s *Server) Start() error {
 		return err
 	}
 
-	s.logger.Logf("[INFO] Server is starting at %v...\n", s.config.BindAddr)
+	if err := s.migrateStore(); err != nil {
+		s.closeStore()
+		return err
+	}
+
+	s.configureRedirects()
+	s.configureCache()
+	s.configureSearch()
+
+	s.startJobs()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Logf("[INFO] Server is starting at %v...\n", s.config.BindAddr)
+		errCh <- s.httpServer.ListenAndServe()
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	select {
+	case err := <-errCh:
+		// ErrServerClosed means Shutdown was called and it takes care of the rest
+		if err == http.ErrServerClosed {
+			return nil
+		}
+
+		s.waitJobs()
+		s.closeStore()
+		return err
+	case sig := <-sigCh:
+		s.logger.Logf("[INFO] Got %v, shutting down...\n", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.ShutdownTimeout)*time.Second)
+		defer cancel()
+
+		return s.Shutdown(ctx)
+	}
+}
+
+// Shutdown stops accepting new connections, waits for active requests until ctx is done
+// and closes the store after that
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		s.logger.Logf("[WARN] Connections were not drained: %v\n", err)
+	}
 
-	return http.ListenAndServe(s.config.BindAddr, s.router)
+	s.waitJobs()
+
+	// Drain may have used up ctx, so store is closed with its own timeout
+	if cerr := s.closeStore(); err == nil {
+		err = cerr
+	}
+
+	s.logger.Logf("[INFO] Server stopped\n")
+
+	return err
+}
+
+// startJobs runs background jobs which use store until waitJobs is called
+func (s *Server) startJobs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopJobs = cancel
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.runTrashPurger(ctx)
+	}()
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.runSearchRebuilder(ctx)
+	}()
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.runPublisher(ctx)
+	}()
+}
+
+// waitJobs stops background jobs and waits for them to finish
+func (s *Server) waitJobs() {
+	if s.stopJobs != nil {
+		s.stopJobs()
+	}
+
+	s.jobs.Wait()
+}
+
+// closeStore closes store connection if it was established
+// Disconnect is limited by timeout of connection
+func (s *Server) closeStore() error {
+	if s.store == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.DBConnectTimeout)*time.Second)
+	defer cancel()
+
+	if err := s.store.Close(ctx); err != nil {
+		s.logger.Logf("[ERROR] During store close: %v\n", err)
+		return err
+	}
+
+	return nil
 }
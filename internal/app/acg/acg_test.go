@@ -0,0 +1,72 @@
+package acg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-pkgz/lgr"
+	"github.com/the-NZA/acg-nikolaev/internal/app/auth"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/memstore"
+)
+
+// testServer returns server with routes and decorators of Start on top of empty in-memory store
+// Views are not parsed, so tests don't reach rendering of public pages
+func testServer(t *testing.T, reviewers ...string) *Server {
+	t.Helper()
+
+	config := NewConfig()
+	config.CacheSize = 0
+	config.Reviewers = reviewers
+
+	s := NewServer(config)
+	s.logger = lgr.New(lgr.Out(io.Discard), lgr.Err(io.Discard))
+
+	if err := s.configureSlugs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.configureTypograph(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.configureSanitizer()
+	s.configureRouter()
+
+	s.store = memstore.NewStore()
+	s.configureRedirects()
+	s.configureSearch()
+
+	return s
+}
+
+// serve sends request on behalf of editor with username, empty username sends it without token
+func serve(t *testing.T, s *Server, username, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, values := range header {
+		r.Header[key] = values
+	}
+
+	if username != "" {
+		token, _, err := auth.CreateToken(username, s.config.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r.AddCookie(&http.Cookie{Name: "TKN", Value: token})
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, r)
+
+	return w
+}
+
+// ifMatchHeader returns header which sends version of document read by editor
+func ifMatchHeader(version int64) http.Header {
+	return http.Header{"If-Match": {etag(version)}}
+}
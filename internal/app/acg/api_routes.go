@@ -1,6 +1,7 @@
 package acg
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,21 +13,23 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const maxFileSize = 32 << 20 // Set max upload file size to 32MB
+const (
+	maxFileSize = 32 << 20     // Set max upload file size to 32MB
+	dateLayout  = "2006-01-02" // Layout of dates in query params
+)
 
 /*
  * Response helpers
  */
 // respond method manage response with json encoding and optional data
-func (s Server) respond(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 
@@ -36,10 +39,111 @@ func (s Server) respond(w http.ResponseWriter, r *http.Request, code int, data i
 }
 
 // error method manage response with error with wrapping it
-func (s Server) error(w http.ResponseWriter, r *http.Request, code int, err error) {
+// Validation errors are given in fields too, e.g. pagedata errors keyed by block index point editor at broken blocks
+func (s *Server) error(w http.ResponseWriter, r *http.Request, code int, err error) {
+	if fields, ok := err.(validation.Errors); ok {
+		s.respond(w, r, code, map[string]interface{}{"error": err.Error(), "fields": fields})
+		return
+	}
+
 	s.respond(w, r, code, map[string]string{"error": err.Error()})
 }
 
+// respondConflict responds to update of stale version with current document and its ETag, so client can merge changes
+func (s *Server) respondConflict(w http.ResponseWriter, r *http.Request, current interface{}, version int64) {
+	s.logger.Logf("[DEBUG] %v\n", store.ErrVersionConflict)
+
+	w.Header().Set("ETag", etag(version))
+	s.respond(w, r, http.StatusPreconditionFailed, map[string]interface{}{
+		"error":   store.ErrVersionConflict.Error(),
+		"current": current,
+	})
+}
+
+// etag returns strong entity tag of document version
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch reads version of document from If-Match header, which is required by updates
+// Returned code is status of response to request without valid header
+func ifMatch(r *http.Request) (int64, int, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" {
+		return 0, http.StatusPreconditionRequired, helpers.ErrNoIfMatch
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(tag, "W/"), `"`), 10, 64)
+	if err != nil {
+		return 0, http.StatusBadRequest, helpers.ErrInvalidIfMatch
+	}
+
+	return version, http.StatusOK, nil
+}
+
+// listQuery reads optional limit, skip, q (part of title), from and to (YYYY-MM-DD), tag (ID), author (username) and status params of listing request
+func listQuery(r *http.Request) (store.ListQuery, error) {
+	var (
+		q   store.ListQuery
+		err error
+	)
+
+	params := r.URL.Query()
+
+	if params.Has("limit") {
+		if q.Limit, err = strconv.ParseInt(params.Get("limit"), 10, 64); err != nil {
+			return q, err
+		}
+
+		if q.Limit < 0 {
+			return q, helpers.ErrNegativeListParam
+		}
+	}
+
+	if params.Has("skip") {
+		if q.Offset, err = strconv.ParseInt(params.Get("skip"), 10, 64); err != nil {
+			return q, err
+		}
+
+		if q.Offset < 0 {
+			return q, helpers.ErrNegativeListParam
+		}
+	}
+
+	if params.Has("from") {
+		if q.From, err = time.Parse(dateLayout, params.Get("from")); err != nil {
+			return q, err
+		}
+	}
+
+	// Whole day of upper bound is included
+	if params.Has("to") {
+		if q.To, err = time.Parse(dateLayout, params.Get("to")); err != nil {
+			return q, err
+		}
+		q.To = q.To.AddDate(0, 0, 1)
+	}
+
+	if params.Has("tag") {
+		if q.TagID, err = primitive.ObjectIDFromHex(params.Get("tag")); err != nil {
+			return q, helpers.ErrInvalidObjectID
+		}
+	}
+
+	q.Author = params.Get("author")
+
+	if params.Has("status") {
+		q.Status = models.Status(params.Get("status"))
+		if !q.Status.Valid() {
+			return q, helpers.ErrUnknownStatus
+		}
+	}
+
+	q.Text = params.Get("q")
+
+	return q, nil
+}
+
 /*
  * Upload handler
  */
@@ -88,6 +192,19 @@ func (s *Server) handleUpload() http.HandlerFunc {
 	}
 }
 
+// handleCacheStats returns hit and miss statistics of store cache
+func (s *Server) handleCacheStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.cache == nil {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCache)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoCache)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, s.cache.Stats())
+	}
+}
+
 /*
  * Categories handlers
  */
@@ -105,9 +222,13 @@ func (s *Server) handleCategoryCreate() http.HandlerFunc {
 			return
 		}
 
-		cat.Slug = helpers.GenerateSlug(cat.Title)
+		if cat.Slug, err = s.slugs.Unique(r.Context(), cat.Title, s.categorySlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
-		if err = s.store.Categories().Create(cat); err != nil {
+		if err = s.store.Categories().Create(r.Context(), cat); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -129,12 +250,38 @@ func (s *Server) handleCategoryUpdate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Categories().Update(category); err != nil {
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		category.Version = version
+
+		switch err = s.store.Categories().Update(r.Context(), category); err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.Categories().FindByID(r.Context(), category.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCategory)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoCategory)
+			return
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(category.Version))
 		s.respond(w, r, http.StatusOK, fmt.Sprintf("Category (%v) successfully updated", category.ID.Hex()))
 	}
 }
@@ -149,14 +296,15 @@ func (s *Server) handleCategoryGetBySlug() http.HandlerFunc {
 			return
 		}
 
-		cat, err := s.store.Categories().FindBySlug(slug)
+		cat, err := s.store.Categories().FindBySlug(r.Context(), slug)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCategory)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoCategory)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(cat.Version))
 			s.respond(w, r, http.StatusOK, cat)
 			return
 		default:
@@ -184,14 +332,15 @@ func (s *Server) handleCategoryGetByID() http.HandlerFunc {
 			return
 		}
 
-		category, err := s.store.Categories().FindByID(objID)
+		category, err := s.store.Categories().FindByID(r.Context(), objID)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCategory)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoCategory)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(category.Version))
 			s.respond(w, r, http.StatusOK, category)
 			return
 		default:
@@ -204,7 +353,7 @@ func (s *Server) handleCategoryGetByID() http.HandlerFunc {
 
 func (s *Server) handleCategoryGetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		cats, err := s.store.Categories().FindAll(bson.M{"deleted": false})
+		cats, err := s.store.Categories().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -215,9 +364,13 @@ func (s *Server) handleCategoryGetAll() http.HandlerFunc {
 	}
 }
 
+// handleCategoryDelete moves category to trash
+// Policy of request decides what happens with its children, see store.DeletePolicy
 func (s *Server) handleCategoryDelete() http.HandlerFunc {
 	type req struct {
-		ID primitive.ObjectID `json:"deletedID"`
+		ID         primitive.ObjectID `json:"deletedID"`
+		Policy     store.DeletePolicy `json:"policy"`
+		ReassignTo primitive.ObjectID `json:"reassignTo"`
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := &req{}
@@ -235,13 +388,24 @@ func (s *Server) handleCategoryDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Categories().Delete(req.ID); err != nil {
+		err = store.DeleteCategory(r.Context(), s.store, req.ID, usernameFromContext(r.Context()), req.Policy, req.ReassignTo)
+
+		switch err {
+		case nil:
+			s.respond(w, r, http.StatusOK, fmt.Sprintf("Category (%s) successfully deleted", req.ID.Hex()))
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCategory)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoCategory)
+		case helpers.ErrCategoryNotEmpty:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+		case helpers.ErrUnknownDeletePolicy, helpers.ErrNoReassignTarget:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
-			return
 		}
-
-		s.respond(w, r, http.StatusOK, fmt.Sprintf("Category (%s) successfully deleted", req.ID.Hex()))
 	}
 }
 
@@ -267,10 +431,14 @@ func (s *Server) handlePostCreate() http.HandlerFunc {
 			return
 		}
 
-		_, err = s.store.Categories().FindByID(post.CategoryID)
+		// New post goes through review before publication
+		post.Status = models.StatusDraft
+		post.Author = usernameFromContext(r.Context())
+
+		_, err = s.store.Categories().FindByID(r.Context(), post.CategoryID)
 		if err != nil {
 			switch err {
-			case mongo.ErrNoDocuments:
+			case store.ErrNotFound:
 				s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCategory)
 				s.error(w, r, http.StatusNotFound, helpers.ErrNoCategory)
 			default:
@@ -280,9 +448,30 @@ func (s *Server) handlePostCreate() http.HandlerFunc {
 			return
 		}
 
-		post.Slug = helpers.GenerateSlug(post.Title)
+		if err = s.checkTags(r.Context(), post.TagIDs); err != nil {
+			switch err {
+			case helpers.ErrNoTag:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusNotFound, err)
+			default:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
 
-		if err = s.store.Posts().Create(post); err != nil {
+		if post.Slug, err = s.slugs.Unique(r.Context(), post.Title, s.postSlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.prepareContent(post)
+
+		err = s.saveRevision(r.Context(), s.revisionedDocs()["post"], usernameFromContext(r.Context()), "", func(ctx context.Context) (interface{}, *models.Transition, error) {
+			return post, nil, s.store.Posts().Create(ctx, post)
+		})
+		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -302,14 +491,15 @@ func (s *Server) handlePostGetBySlug() http.HandlerFunc {
 			return
 		}
 
-		post, err := s.store.Posts().FindBySlug(slug)
+		post, err := s.store.Posts().FindBySlug(r.Context(), slug)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPost)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoPost)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(post.Version))
 			s.respond(w, r, http.StatusOK, post)
 			return
 		default:
@@ -337,14 +527,15 @@ func (s *Server) handlePostGetByID() http.HandlerFunc {
 			return
 		}
 
-		post, err := s.store.Posts().FindByID(objID)
+		post, err := s.store.Posts().FindByID(r.Context(), objID)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPost)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoPost)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(post.Version))
 			s.respond(w, r, http.StatusOK, post)
 			return
 		default:
@@ -368,12 +559,80 @@ func (s *Server) handlePostUpdate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Posts().Update(post); err != nil {
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		current, err := s.store.Posts().FindByID(r.Context(), post.ID)
+
+		switch err {
+		case nil:
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPost)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoPost)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if post.Status, err = keepStatus(post.Status, current.Status); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		post.Version, post.Author = version, current.Author
+		post.ApplySchedule(time.Now())
+
+		if err = s.checkTags(r.Context(), post.TagIDs); err != nil {
+			switch err {
+			case helpers.ErrNoTag:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusNotFound, err)
+			default:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		s.prepareContent(post)
+
+		var t *models.Transition
+		post.Status, t = reviewEdit(post.Status)
+
+		err = s.saveRevision(r.Context(), s.revisionedDocs()["post"], usernameFromContext(r.Context()), "", func(ctx context.Context) (interface{}, *models.Transition, error) {
+			return post, t, s.store.Posts().Update(ctx, post)
+		})
+
+		switch err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.Posts().FindByID(r.Context(), post.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPost)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoPost)
+			return
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(post.Version))
 		s.respond(w, r, http.StatusOK, fmt.Sprintf("Post (%v) successfully updated", post.ID.Hex()))
 	}
 }
@@ -398,7 +657,7 @@ func (s *Server) handlePostDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Posts().Delete(req.ID); err != nil {
+		if err = s.store.Posts().Delete(r.Context(), req.ID, usernameFromContext(r.Context())); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -410,40 +669,14 @@ func (s *Server) handlePostDelete() http.HandlerFunc {
 
 func (s *Server) handlePostGetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			err    error
-			val    int64
-			strVal string
-		)
-
-		findOpts := options.Find()
-		findOpts.SetSort(bson.D{{Key: "time", Value: -1}})
-
-		if r.URL.Query().Has("limit") {
-			strVal = r.URL.Query().Get("limit")
-			val, err = strconv.ParseInt(strVal, 10, 64)
-			if err != nil {
-				s.logger.Logf("[DEBUG] during parse limit: %v\n", err)
-				s.error(w, r, http.StatusBadRequest, err)
-				return
-			}
-
-			findOpts.SetLimit(val)
-		}
-
-		if r.URL.Query().Has("skip") {
-			strVal = r.URL.Query().Get("skip")
-			val, err = strconv.ParseInt(strVal, 10, 64)
-			if err != nil {
-				s.logger.Logf("[DEBUG] during parse skip: %v\n", err)
-				s.error(w, r, http.StatusBadRequest, err)
-				return
-			}
-
-			findOpts.SetSkip(val)
+		q, err := listQuery(r)
+		if err != nil {
+			s.logger.Logf("[DEBUG] during parse list query: %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
 		}
 
-		posts, err := s.store.Posts().Find(bson.M{"deleted": false}, findOpts)
+		posts, err := s.store.Posts().List(r.Context(), q)
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -456,7 +689,7 @@ func (s *Server) handlePostGetAll() http.HandlerFunc {
 
 func (s *Server) handlePostCount() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		numOfPosts, err := s.store.Posts().Count(bson.D{{Key: "deleted", Value: false}})
+		numOfPosts, err := s.store.Posts().Count(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -488,9 +721,13 @@ func (s *Server) handleServiceCreate() http.HandlerFunc {
 			return
 		}
 
-		service.Slug = helpers.GenerateSlug(service.Title)
+		if service.Slug, err = s.slugs.Unique(r.Context(), service.Title, s.serviceSlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
-		if err = s.store.Services().Create(service); err != nil {
+		if err = s.store.Services().Create(r.Context(), service); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -517,14 +754,15 @@ func (s *Server) handleServiceGetByID() http.HandlerFunc {
 			return
 		}
 
-		service, err := s.store.Services().FindByID(objID)
+		service, err := s.store.Services().FindByID(r.Context(), objID)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoService)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoService)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(service.Version))
 			s.respond(w, r, http.StatusOK, service)
 			return
 		default:
@@ -546,12 +784,38 @@ func (s *Server) handleServiceUpdate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Services().Update(service); err != nil {
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		service.Version = version
+
+		switch err = s.store.Services().Update(r.Context(), service); err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.Services().FindByID(r.Context(), service.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoService)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoService)
+			return
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(service.Version))
 		s.respond(w, r, http.StatusOK, fmt.Sprintf("Service (%v) successfully updated", service.ID.Hex()))
 	}
 }
@@ -576,7 +840,7 @@ func (s *Server) handleServiceDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Services().Delete(req.ID); err != nil {
+		if err = s.store.Services().Delete(r.Context(), req.ID, usernameFromContext(r.Context())); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -588,7 +852,7 @@ func (s *Server) handleServiceDelete() http.HandlerFunc {
 
 func (s *Server) handleServiceGetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		services, err := s.store.Services().FindAll(bson.M{"deleted": false})
+		services, err := s.store.Services().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -621,9 +885,13 @@ func (s *Server) handleMatCategoryCreate() http.HandlerFunc {
 			return
 		}
 
-		matcat.Slug = helpers.GenerateSlug(matcat.Title)
+		if matcat.Slug, err = s.slugs.Unique(r.Context(), matcat.Title, s.matCategorySlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
-		if err = s.store.MatCategories().Create(matcat); err != nil {
+		if err = s.store.MatCategories().Create(r.Context(), matcat); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -651,14 +919,15 @@ func (s *Server) handleMatCategoryGetByID() http.HandlerFunc {
 			return
 		}
 
-		matcategory, err := s.store.MatCategories().FindByID(objID)
+		matcategory, err := s.store.MatCategories().FindByID(r.Context(), objID)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoMatCategory)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoMatCategory)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(matcategory.Version))
 			s.respond(w, r, http.StatusOK, matcategory)
 			return
 		default:
@@ -679,14 +948,15 @@ func (s *Server) handleMatCategoryGetBySlug() http.HandlerFunc {
 			return
 		}
 
-		matcat, err := s.store.MatCategories().FindBySlug(slug)
+		matcat, err := s.store.MatCategories().FindBySlug(r.Context(), slug)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoMatCategory)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoMatCategory)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(matcat.Version))
 			s.respond(w, r, http.StatusOK, matcat)
 			return
 		default:
@@ -708,18 +978,49 @@ func (s *Server) handleMatCategoryUpdate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.MatCategories().Update(matcategory); err != nil {
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		matcategory.Version = version
+
+		switch err = s.store.MatCategories().Update(r.Context(), matcategory); err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.MatCategories().FindByID(r.Context(), matcategory.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoMatCategory)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoMatCategory)
+			return
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(matcategory.Version))
 		s.respond(w, r, http.StatusOK, fmt.Sprintf("MatCategory (%v) successfully updated", matcategory.ID.Hex()))
 	}
 }
+
+// handleMatCategoryDelete moves material category to trash
+// Policy of request decides what happens with its children, see store.DeletePolicy
 func (s *Server) handleMatCategoryDelete() http.HandlerFunc {
 	type req struct {
-		ID primitive.ObjectID `json:"deletedID"`
+		ID         primitive.ObjectID `json:"deletedID"`
+		Policy     store.DeletePolicy `json:"policy"`
+		ReassignTo primitive.ObjectID `json:"reassignTo"`
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := &req{}
@@ -737,19 +1038,30 @@ func (s *Server) handleMatCategoryDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.MatCategories().Delete(req.ID); err != nil {
+		err = store.DeleteMatCategory(r.Context(), s.store, req.ID, usernameFromContext(r.Context()), req.Policy, req.ReassignTo)
+
+		switch err {
+		case nil:
+			s.respond(w, r, http.StatusOK, fmt.Sprintf("Material category (%s) successfully deleted", req.ID.Hex()))
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoMatCategory)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoMatCategory)
+		case helpers.ErrMatCategoryNotEmpty:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+		case helpers.ErrUnknownDeletePolicy, helpers.ErrNoReassignTarget:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
-			return
 		}
-
-		s.respond(w, r, http.StatusOK, fmt.Sprintf("Material category (%s) successfully deleted", req.ID.Hex()))
 	}
 }
 
 func (s *Server) handleMatCategoryGetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		services, err := s.store.MatCategories().FindAll(bson.M{"deleted": false})
+		services, err := s.store.MatCategories().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -783,10 +1095,10 @@ func (s *Server) handleMaterialCreate() http.HandlerFunc {
 			return
 		}
 
-		_, err = s.store.MatCategories().FindByID(material.MatCategoryID)
+		_, err = s.store.MatCategories().FindByID(r.Context(), material.MatCategoryID)
 		if err != nil {
 			switch err {
-			case mongo.ErrNoDocuments:
+			case store.ErrNotFound:
 				s.logger.Logf("[ERROR] %v\n", helpers.ErrNoCategory)
 				s.error(w, r, http.StatusNotFound, helpers.ErrNoCategory)
 			default:
@@ -796,9 +1108,25 @@ func (s *Server) handleMaterialCreate() http.HandlerFunc {
 			return
 		}
 
-		material.Slug = helpers.GenerateSlug(material.Title)
+		if err = s.checkTags(r.Context(), material.TagIDs); err != nil {
+			switch err {
+			case helpers.ErrNoTag:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusNotFound, err)
+			default:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		if material.Slug, err = s.slugs.Unique(r.Context(), material.Title, s.materialSlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
-		if err = s.store.Materials().Create(material); err != nil {
+		if err = s.store.Materials().Create(r.Context(), material); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -825,14 +1153,15 @@ func (s *Server) handleMaterialGetByID() http.HandlerFunc {
 			return
 		}
 
-		material, err := s.store.Materials().FindByID(objID)
+		material, err := s.store.Materials().FindByID(r.Context(), objID)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoMaterial)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoMaterial)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(material.Version))
 			s.respond(w, r, http.StatusOK, material)
 			return
 		default:
@@ -856,12 +1185,50 @@ func (s *Server) handleMaterialUpdate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Materials().Update(material); err != nil {
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		material.Version = version
+
+		if err = s.checkTags(r.Context(), material.TagIDs); err != nil {
+			switch err {
+			case helpers.ErrNoTag:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusNotFound, err)
+			default:
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		switch err = s.store.Materials().Update(r.Context(), material); err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.Materials().FindByID(r.Context(), material.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoMaterial)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoMaterial)
+			return
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(material.Version))
 		s.respond(w, r, http.StatusOK, fmt.Sprintf("Post (%v) successfully updated", material.ID.Hex()))
 	}
 }
@@ -886,7 +1253,7 @@ func (s *Server) handleMaterialDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Materials().Delete(req.ID); err != nil {
+		if err = s.store.Materials().Delete(r.Context(), req.ID, usernameFromContext(r.Context())); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -898,40 +1265,14 @@ func (s *Server) handleMaterialDelete() http.HandlerFunc {
 
 func (s *Server) handleMaterialGetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var (
-			err    error
-			val    int64
-			strVal string
-		)
-
-		findOpts := options.Find()
-		findOpts.SetSort(bson.D{{Key: "time", Value: -1}})
-
-		if r.URL.Query().Has("limit") {
-			strVal = r.URL.Query().Get("limit")
-			val, err = strconv.ParseInt(strVal, 10, 64)
-			if err != nil {
-				s.logger.Logf("[DEBUG] during parse limit: %v\n", err)
-				s.error(w, r, http.StatusBadRequest, err)
-				return
-			}
-
-			findOpts.SetLimit(val)
-		}
-
-		if r.URL.Query().Has("skip") {
-			strVal = r.URL.Query().Get("skip")
-			val, err = strconv.ParseInt(strVal, 10, 64)
-			if err != nil {
-				s.logger.Logf("[DEBUG] during parse skip: %v\n", err)
-				s.error(w, r, http.StatusBadRequest, err)
-				return
-			}
-
-			findOpts.SetSkip(val)
+		q, err := listQuery(r)
+		if err != nil {
+			s.logger.Logf("[DEBUG] during parse list query: %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
 		}
 
-		materials, err := s.store.Materials().Find(bson.M{"deleted": false}, findOpts)
+		materials, err := s.store.Materials().List(r.Context(), q)
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -949,14 +1290,20 @@ func (s *Server) handleMaterialGetAllBySlug() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		matcat, err := s.store.MatCategories().FindBySlug(chi.URLParam(r, "matCatSlug"))
+		matCatSlug := chi.URLParam(r, "matCatSlug")
+
+		matcat, err := s.store.MatCategories().FindBySlug(r.Context(), matCatSlug)
 		if err != nil {
+			if s.redirectOldURL(w, r, models.RedirectMatCategory, matCatSlug) {
+				return
+			}
+
 			s.logger.Logf("[DEBUG] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		materials, err := s.store.Materials().FindAll(bson.M{"matcategory_id": matcat.ID, "deleted": false})
+		materials, err := s.store.Materials().List(r.Context(), store.ListQuery{CategoryID: matcat.ID})
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -972,7 +1319,7 @@ func (s *Server) handleMaterialGetAllBySlug() http.HandlerFunc {
 
 func (s *Server) handleMaterialCount() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		numOfMaterials, err := s.store.Materials().Count(bson.D{{Key: "deleted", Value: false}})
+		numOfMaterials, err := s.store.Materials().Count(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -1004,9 +1351,23 @@ func (s *Server) handlePageCreate() http.HandlerFunc {
 			return
 		}
 
-		page.URL = "/" + helpers.GenerateSlug(page.Title)
+		slug, err := s.slugs.Unique(r.Context(), page.Title, s.pageSlugTaken)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		page.URL = "/" + slug
 
-		if err = s.store.Pages().Create(page); err != nil {
+		// New page goes through review before publication
+		page.Status = models.StatusDraft
+
+		s.prepareContent(page)
+
+		err = s.saveRevision(r.Context(), s.revisionedDocs()["page"], usernameFromContext(r.Context()), "", func(ctx context.Context) (interface{}, *models.Transition, error) {
+			return page, nil, s.store.Pages().Create(ctx, page)
+		})
+		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -1033,14 +1394,15 @@ func (s *Server) handlePageGetByID() http.HandlerFunc {
 			return
 		}
 
-		page, err := s.store.Pages().FindByID(objID)
+		page, err := s.store.Pages().FindByID(r.Context(), objID)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoService)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoService)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(page.Version))
 			s.respond(w, r, http.StatusOK, page)
 			return
 		default:
@@ -1060,14 +1422,15 @@ func (s *Server) handlePageGetByURL() http.HandlerFunc {
 			return
 		}
 
-		page, err := s.store.Pages().FindByURL(url)
+		page, err := s.store.Pages().FindByURL(r.Context(), url)
 
 		switch err {
-		case mongo.ErrNoDocuments:
+		case store.ErrNotFound:
 			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPage)
 			s.error(w, r, http.StatusNotFound, helpers.ErrNoPage)
 			return
 		case nil:
+			w.Header().Set("ETag", etag(page.Version))
 			s.respond(w, r, http.StatusOK, page)
 			return
 		default:
@@ -1091,12 +1454,67 @@ func (s *Server) handlePageUpdate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Pages().Update(page); err != nil {
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		current, err := s.store.Pages().FindByID(r.Context(), page.ID)
+
+		switch err {
+		case nil:
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPage)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoPage)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if page.Status, err = keepStatus(page.Status, current.Status); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		page.Version = version
+
+		s.prepareContent(page)
+
+		var t *models.Transition
+		page.Status, t = reviewEdit(page.Status)
+
+		err = s.saveRevision(r.Context(), s.revisionedDocs()["page"], usernameFromContext(r.Context()), "", func(ctx context.Context) (interface{}, *models.Transition, error) {
+			return page, t, s.store.Pages().Update(ctx, page)
+		})
+
+		switch err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.Pages().FindByID(r.Context(), page.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoPage)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoPage)
+			return
+		default:
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(page.Version))
 		s.respond(w, r, http.StatusOK, fmt.Sprintf("Page (%v) successfully updated", page.ID.Hex()))
 	}
 }
@@ -1121,7 +1539,7 @@ func (s *Server) handlePageDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Pages().Delete(req.ID); err != nil {
+		if err = s.store.Pages().Delete(r.Context(), req.ID, usernameFromContext(r.Context())); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -1133,7 +1551,7 @@ func (s *Server) handlePageDelete() http.HandlerFunc {
 
 func (s *Server) handlePageGetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		pages, err := s.store.Pages().FindAll(bson.M{})
+		pages, err := s.store.Pages().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
@@ -1148,6 +1566,207 @@ func (s *Server) handlePageGetAll() http.HandlerFunc {
  * Page handlers END
  */
 
+/*
+ * Tag handlers
+ */
+
+// checkTags returns helpers.ErrNoTag when any of tags is not live
+func (s *Server) checkTags(ctx context.Context, IDs []primitive.ObjectID) error {
+	if len(IDs) == 0 {
+		return nil
+	}
+
+	unique := make(map[primitive.ObjectID]bool, len(IDs))
+	for _, ID := range IDs {
+		unique[ID] = true
+	}
+
+	tags, err := s.store.Tags().ListByIDs(ctx, IDs)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) != len(unique) {
+		return helpers.ErrNoTag
+	}
+
+	return nil
+}
+
+func (s *Server) handleTagCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tag := &models.Tag{
+			ID: primitive.NewObjectID(),
+		}
+
+		var err error
+
+		if err = json.NewDecoder(r.Body).Decode(tag); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if tag.Slug, err = s.slugs.Unique(r.Context(), tag.Title, s.tagSlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err = s.store.Tags().Create(r.Context(), tag); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, fmt.Sprintf("Tag (%s) successfully created", tag.ID.Hex()))
+	}
+}
+
+func (s *Server) handleTagGetByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID := r.URL.Query().Get("ID")
+
+		if ID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		objID, err := primitive.ObjectIDFromHex(ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrInvalidObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrInvalidObjectID)
+			return
+		}
+
+		tag, err := s.store.Tags().FindByID(r.Context(), objID)
+
+		switch err {
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoTag)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoTag)
+			return
+		case nil:
+			w.Header().Set("ETag", etag(tag.Version))
+			s.respond(w, r, http.StatusOK, tag)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (s *Server) handleTagUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		tag := &models.Tag{}
+
+		if err = json.NewDecoder(r.Body).Decode(tag); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		tag.Version = version
+
+		switch err = s.store.Tags().Update(r.Context(), tag); err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, err := s.store.Tags().FindByID(r.Context(), tag.ID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, current.Version)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoTag)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoTag)
+			return
+		case helpers.ErrTagAlreadyExist:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(tag.Version))
+		s.respond(w, r, http.StatusOK, fmt.Sprintf("Tag (%v) successfully updated", tag.ID.Hex()))
+	}
+}
+
+// handleTagDelete moves tag to trash, posts and materials keep it until it is purged
+func (s *Server) handleTagDelete() http.HandlerFunc {
+	type req struct {
+		ID primitive.ObjectID `json:"deletedID"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &req{}
+		var err error
+
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if req.ID.IsZero() {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrEmptyObjectID)
+			s.error(w, r, http.StatusInternalServerError, helpers.ErrEmptyObjectID)
+			return
+		}
+
+		if err = s.store.Tags().Delete(r.Context(), req.ID, usernameFromContext(r.Context())); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, fmt.Sprintf("Tag (%s) successfully deleted", req.ID.Hex()))
+	}
+}
+
+func (s *Server) handleTagGetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := listQuery(r)
+		if err != nil {
+			s.logger.Logf("[DEBUG] during parse list query: %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		q.Sort = store.SortTitle
+
+		tags, err := s.store.Tags().List(r.Context(), q)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, tags)
+	}
+}
+
+/*
+ * Tag handlers END
+ */
+
 /*
  * User handlers
  */
@@ -1165,7 +1784,10 @@ func (s *Server) handleUserCreate() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Users().Create(usr); err != nil {
+		// Profile is filled separately, so its slug is generated
+		usr.Author = nil
+
+		if err = s.store.Users().Create(r.Context(), usr); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -1195,7 +1817,7 @@ func (s *Server) handleUserDelete() http.HandlerFunc {
 			return
 		}
 
-		if err = s.store.Users().Delete(req.ID); err != nil {
+		if err = s.store.Users().Delete(r.Context(), req.ID); err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
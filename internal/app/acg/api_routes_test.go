@@ -0,0 +1,61 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandlePostUpdate_IfMatch(t *testing.T) {
+	s := testServer(t)
+	post := storetest.Post("Первая запись", primitive.NewObjectID())
+	post.Status = models.StatusDraft
+	assert.NoError(t, s.store.Posts().Create(context.Background(), post))
+
+	update := func(title string, header http.Header) (int, http.Header, []byte) {
+		p := *post
+		p.Title = title
+
+		body, err := json.Marshal(&p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := serve(t, s, "first_editor", http.MethodPut, "/api/post/", string(body), header)
+		return w.Code, w.Header(), w.Body.Bytes()
+	}
+
+	code, _, _ := update("Правка без версии", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, code)
+
+	code, _, _ = update("Правка с неверной версией", http.Header{"If-Match": {"first"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, header, _ := update("Правка первого редактора", ifMatchHeader(0))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, etag(1), header.Get("ETag"))
+
+	// Second editor saves post read before the first update and gets current post to merge changes
+	code, header, body := update("Правка второго редактора", ifMatchHeader(0))
+	assert.Equal(t, http.StatusPreconditionFailed, code)
+	assert.Equal(t, etag(1), header.Get("ETag"))
+
+	var conflict struct {
+		Error   string      `json:"error"`
+		Current models.Post `json:"current"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &conflict))
+	assert.NotEmpty(t, conflict.Error)
+	assert.Equal(t, post.ID, conflict.Current.ID)
+	assert.Equal(t, "Правка первого редактора", conflict.Current.Title)
+
+	found, err := s.store.Posts().FindByID(context.Background(), post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Правка первого редактора", found.Title)
+}
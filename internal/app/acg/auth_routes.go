@@ -40,7 +40,7 @@ func (s *Server) handleAuthLogin() http.HandlerFunc {
 			return
 		}
 
-		token, expTime, err := s.store.Users().Login(cred.Username, cred.Password, s.config.SecretKey)
+		token, expTime, err := s.store.Users().Login(r.Context(), cred.Username, cred.Password, s.config.SecretKey)
 		if err != nil {
 			s.logger.Logf("[ERROR] %v\n", err)
 			s.error(w, r, http.StatusBadRequest, err)
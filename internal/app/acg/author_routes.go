@@ -0,0 +1,179 @@
+package acg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
+// handleAuthorGet returns author profile of authorized editor
+func (s *Server) handleAuthorGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		usr, err := s.store.Users().FindByUsername(r.Context(), usernameFromContext(r.Context()))
+
+		switch {
+		case err == store.ErrNotFound, err == nil && usr.Author == nil:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoAuthor)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoAuthor)
+			return
+		case err != nil:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, usr.Author)
+	}
+}
+
+// handleAuthorUpdate saves author profile of authorized editor
+// Slug is generated from name on first save, later changes of name keep it
+func (s *Server) handleAuthorUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		author := &models.Author{}
+		var err error
+
+		if err = json.NewDecoder(r.Body).Decode(author); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		usr, err := s.store.Users().FindByUsername(r.Context(), usernameFromContext(r.Context()))
+
+		switch err {
+		case nil:
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoUser)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoUser)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if usr.Author != nil && usr.Author.Slug != "" {
+			author.Slug = usr.Author.Slug
+		} else if author.Slug, err = s.slugs.Unique(r.Context(), author.Name, s.authorSlugTaken); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		switch err = s.store.Users().UpdateAuthor(r.Context(), usr.Username, author); err {
+		case nil:
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoUser)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNoUser)
+			return
+		case helpers.ErrAuthorAlreadyExist:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, author)
+	}
+}
+
+// findAuthor returns profile of post author for byline, nil when post has no author or author has no profile
+func (s *Server) findAuthor(r *http.Request, post *models.Post) *models.Author {
+	if post.Author == "" {
+		return nil
+	}
+
+	usr, err := s.store.Users().FindByUsername(r.Context(), post.Author)
+	if err != nil {
+		if err != store.ErrNotFound {
+			s.logger.Logf("[DEBUG] author of post %s: %v\n", post.ID.Hex(), err)
+		}
+		return nil
+	}
+
+	return usr.Author
+}
+
+// handleAuthorPage shows author profile and posts of author page by page
+func (s *Server) handleAuthorPage() http.HandlerFunc {
+	type authorPage struct {
+		Page          *models.Page
+		Author        *models.Author
+		Posts         []*models.Post
+		Pagination    []helpers.PaginationLink
+		NumberOfPages int
+		CurrentPage   string
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			pageNumber uint64
+			err        error
+		)
+
+		pNum := r.URL.Query().Get("page")
+		if pNum != "" {
+			pageNumber, err = strconv.ParseUint(pNum, 10, 64)
+			if err != nil {
+				s.logger.Logf("[DEBUG] %v\n", err)
+				http.Redirect(w, r, "/posts", http.StatusSeeOther)
+				return
+			}
+		} else {
+			pageNumber = 1
+		}
+
+		usr, err := s.store.Users().FindByAuthorSlug(r.Context(), chi.URLParam(r, "authorSlug"))
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		posts, pageNumber, maxPageNumber, err := s.listPostsPage(r.Context(), store.ListQuery{Author: usr.Username}, pageNumber)
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		subtitle := usr.Author.Position
+		if subtitle == "" {
+			subtitle = "Автор"
+		}
+
+		buf := &bytes.Buffer{}
+
+		err = s.tmpl.ExecuteTemplate(buf, "author.gohtml", &authorPage{
+			Page: &models.Page{
+				Title:    usr.Author.Name,
+				Subtitle: subtitle,
+				MetaDesc: "Записи автора " + usr.Author.Name,
+				URL:      usr.Author.URL(),
+			},
+			Author:        usr.Author,
+			Posts:         posts,
+			Pagination:    helpers.GeneratePagination(uint(pageNumber), uint(maxPageNumber)),
+			NumberOfPages: int(maxPageNumber),
+			CurrentPage:   strconv.Itoa(int(pageNumber)),
+		})
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		io.Copy(w, buf)
+	}
+}
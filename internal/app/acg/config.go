@@ -1,21 +1,71 @@
 package acg
 
+import (
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
 // Config for ACG app
 type Config struct {
-	AppDomain   string `json:"app_domain"`
-	AppPort     string `json:"app_port"`
-	BindAddr    string `json:"bind_addr"`
-	DatabaseURL string `json:"db_url"`
-	LogDebug    bool   `json:"log_debug"`
-	SecretKey   string `json:"secret_key"`
+	AppDomain          string   `json:"app_domain"`
+	AppPort            string   `json:"app_port"`
+	BindAddr           string   `json:"bind_addr"`
+	DatabaseDriver     string   `json:"db_driver"`             // mongodb, sqlite or postgres
+	DatabaseURL        string   `json:"db_url"`                // Connection string or DSN for chosen driver
+	DBConnectTimeout   int      `json:"db_connect_timeout"`    // Seconds to establish db connection
+	DBReadTimeout      int      `json:"db_read_timeout"`       // Seconds for lookups, finds and counts
+	DBWriteTimeout     int      `json:"db_write_timeout"`      // Seconds for inserts and updates
+	DBAggregateTimeout int      `json:"db_aggregate_timeout"`  // Seconds for aggregation pipelines
+	DBMigrateTimeout   int      `json:"db_migrate_timeout"`    // Seconds for index creation and migrations
+	HTTPReadTimeout    int      `json:"http_read_timeout"`     // Seconds to read whole request including body
+	HTTPWriteTimeout   int      `json:"http_write_timeout"`    // Seconds to write response
+	HTTPIdleTimeout    int      `json:"http_idle_timeout"`     // Seconds to keep idle keep-alive connection
+	HTTPMaxHeaderBytes int      `json:"http_max_header_bytes"` // Maximum size of request headers
+	ShutdownTimeout    int      `json:"shutdown_timeout"`      // Seconds to drain connections on shutdown
+	TrashRetentionDays int      `json:"trash_retention_days"`  // Days to keep deleted content before purge, zero keeps it forever
+	CacheSize          int      `json:"cache_size"`            // Maximum number of cached store reads, zero disables cache
+	CacheTTL           int      `json:"cache_ttl"`             // Seconds to keep cached store reads, zero keeps them until eviction
+	SlugStandard       string   `json:"slug_standard"`         // Transliteration of new slugs: legacy, gost or iso9
+	SlugMaxLength      int      `json:"slug_max_length"`       // Maximum length of new slugs, zero means default
+	Typograph          string   `json:"typograph"`             // When typography is applied to content: off, save or render
+	LogDebug           bool     `json:"log_debug"`
+	SecretKey          string   `json:"secret_key"`
+	Reviewers          []string `json:"reviewers"` // Usernames of editors who approve and reject content, nothing is approved while it is empty
 }
 
 // NewConfig returns config with mocked values
 func NewConfig() *Config {
 	return &Config{
-		BindAddr:    ":9999",
-		DatabaseURL: "mongodb://test:27017",
-		LogDebug:    false,
-		SecretKey:   "Sample_Secret",
+		BindAddr:           ":9999",
+		DatabaseDriver:     "mongodb",
+		DatabaseURL:        "mongodb://test:27017",
+		DBConnectTimeout:   15,
+		DBReadTimeout:      10,
+		DBWriteTimeout:     10,
+		DBAggregateTimeout: 10,
+		DBMigrateTimeout:   300,
+		HTTPReadTimeout:    60,
+		HTTPWriteTimeout:   60,
+		HTTPIdleTimeout:    120,
+		HTTPMaxHeaderBytes: 1 << 20,
+		ShutdownTimeout:    30,
+		TrashRetentionDays: 30,
+		CacheSize:          1000,
+		CacheTTL:           300,
+		SlugStandard:       "legacy",
+		SlugMaxLength:      100,
+		Typograph:          "render",
+		LogDebug:           false,
+		SecretKey:          "Sample_Secret",
+	}
+}
+
+// storeTimeouts converts timeouts from config into store.Timeouts
+func (c Config) storeTimeouts() store.Timeouts {
+	return store.Timeouts{
+		Read:      time.Duration(c.DBReadTimeout) * time.Second,
+		Write:     time.Duration(c.DBWriteTimeout) * time.Second,
+		Aggregate: time.Duration(c.DBAggregateTimeout) * time.Second,
 	}
 }
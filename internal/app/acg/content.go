@@ -0,0 +1,45 @@
+package acg
+
+import (
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/typograph"
+)
+
+// prepareContent readies post or page for save: sanitizes inline markup by policy of site,
+// applies typography when config asks for it on save,
+// gives header blocks anchors and caches word count and reading time of post
+func (s *Server) prepareContent(doc interface{}) {
+	switch d := doc.(type) {
+	case *models.Post:
+		d.Sanitize(s.sanitizer)
+
+		if s.typograph == typograph.OnSave {
+			d.Typograph()
+		}
+
+		d.PageData = models.Anchored(d.PageData, s.slugs)
+		d.CountReading()
+	case *models.Page:
+		d.Sanitize(s.sanitizer)
+
+		if s.typograph == typograph.OnSave {
+			d.Typograph()
+		}
+
+		d.PageData = models.Anchored(d.PageData, s.slugs)
+	}
+}
+
+// displayedPost returns copy of post ready to show with anchors of headers and reading time
+// Posts saved before anchors and reading time were introduced get them here,
+// post itself is not changed as it may be shared by cache
+func (s *Server) displayedPost(post *models.Post) *models.Post {
+	displayed := *post
+	displayed.PageData = models.Anchored(post.PageData, s.slugs)
+
+	if displayed.WordCount == 0 {
+		displayed.CountReading()
+	}
+
+	return &displayed
+}
@@ -1,12 +1,43 @@
 package acg
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/auth"
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 )
 
+// ctxKey is type of context keys set by middlewares
+type ctxKey int
+
+// ctxKeyUsername holds username of authorized editor
+const ctxKeyUsername ctxKey = iota
+
+// usernameFromContext returns username of authorized editor or empty string
+func usernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value(ctxKeyUsername).(string)
+
+	return username
+}
+
+// isReviewer reports whether authorized editor may approve and reject content
+// Nobody is reviewer when no reviewers are configured
+func (s *Server) isReviewer(ctx context.Context) bool {
+	username := usernameFromContext(ctx)
+	if username == "" {
+		return false
+	}
+
+	for _, reviewer := range s.config.Reviewers {
+		if reviewer == username {
+			return true
+		}
+	}
+
+	return false
+}
+
 // authMiddleware check and varify cookie with token
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -52,6 +83,13 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			})
 		}
 
-		next.ServeHTTP(w, r)
+		username, err := auth.Username(token.Value, s.config.SecretKey)
+		if err != nil {
+			s.logger.Logf("[ERROR] During username extraction: %v\n", err)
+			s.error(w, r, http.StatusUnauthorized, helpers.ErrUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyUsername, username)))
 	})
 }
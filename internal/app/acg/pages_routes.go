@@ -2,7 +2,7 @@ package acg
 
 import (
 	"bytes"
-	"html/template"
+	"context"
 	"io"
 	"net/http"
 	"strconv"
@@ -10,20 +10,32 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-)
-
-var (
-	tmpl *template.Template
-	err  error
 )
 
 const postPerPage = 15
 
-func init() {
-	tmpl = template.Must(template.ParseGlob("internal/*/views/*.gohtml"))
+// findPublicPage returns page served at URL of request, draft and withdrawn pages are not found
+// When there is no such page it answers with 301 to page moved from this URL or redirects to not found page with code
+func (s *Server) findPublicPage(w http.ResponseWriter, r *http.Request, code int) (*models.Page, bool) {
+	page, err := s.store.Pages().FindByURL(r.Context(), r.URL.Path)
+	if err == nil && !page.IsPublished() {
+		err = store.ErrNotFound
+	}
+
+	if err != nil {
+		// Page moved from fixed route is found by its old URL
+		if s.redirectOldURL(w, r, models.RedirectPage, r.URL.Path) {
+			return nil, false
+		}
+
+		s.logger.Logf("[DEBUG] page: %v\n", err)
+		http.Redirect(w, r, "/404", code)
+		return nil, false
+	}
+
+	return page, true
 }
 
 func (s *Server) handleHomePage() http.HandlerFunc {
@@ -34,42 +46,25 @@ func (s *Server) handleHomePage() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		page, err := s.store.Pages().FindByURL(r.URL.Path)
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusNotFound)
+		page, ok := s.findPublicPage(w, r, http.StatusNotFound)
+		if !ok {
+			return
 		}
 
-		services, err := s.store.Services().FindAll(bson.M{"deleted": false})
+		services, err := s.store.Services().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[DEBUG] services: %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusNotFound)
 		}
 
-		posts, err := s.store.Posts().Aggregate(mongo.Pipeline{
-			{{Key: "$lookup", Value: bson.D{
-				{Key: "from", Value: "categories"},
-				{Key: "localField", Value: "category_id"},
-				{Key: "foreignField", Value: "_id"},
-				{Key: "as", Value: "category_slug"}}}},
-			{{Key: "$match", Value: bson.D{{Key: "deleted", Value: false}}}},
-			{{Key: "$project", Value: bson.D{
-				{Key: "title", Value: 1},
-				{Key: "snippet", Value: 1},
-				{Key: "postimg", Value: 1},
-				{Key: "time", Value: 1},
-				{Key: "slug", Value: 1},
-				{Key: "category_slug", Value: "$category_slug.slug"}}}},
-			{{Key: "$sort", Value: bson.D{{Key: "time", Value: -1}}}},
-			{{Key: "$limit", Value: 3}},
-			{{Key: "$unwind", Value: "$category_slug"}}})
+		posts, err := s.store.Posts().ListPublishedWithCategory(r.Context(), store.ListQuery{Limit: 3})
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
 			return
 		}
 
-		err = tmpl.ExecuteTemplate(w, "index.gohtml", &homepage{
+		err = s.tmpl.ExecuteTemplate(w, "index.gohtml", &homepage{
 			Page:     page,
 			Services: services,
 			Posts:    posts,
@@ -83,13 +78,12 @@ func (s *Server) handleHomePage() http.HandlerFunc {
 
 func (s *Server) handleAboutPage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		aboutpage, err := s.store.Pages().FindByURL(r.URL.Path)
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusNotFound)
+		aboutpage, ok := s.findPublicPage(w, r, http.StatusNotFound)
+		if !ok {
+			return
 		}
 
-		tmpl.ExecuteTemplate(w, "singlepage.gohtml", aboutpage)
+		s.tmpl.ExecuteTemplate(w, "singlepage.gohtml", aboutpage)
 	}
 }
 
@@ -104,12 +98,14 @@ func (s *Server) handlePostsPage() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		var pageNumber uint64
+		var (
+			pageNumber uint64
+			err        error
+		)
 
-		page, err := s.store.Pages().FindByURL(r.URL.Path)
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusSeeOther)
+		page, ok := s.findPublicPage(w, r, http.StatusSeeOther)
+		if !ok {
+			return
 		}
 
 		pNum := r.URL.Query().Get("page")
@@ -124,47 +120,8 @@ func (s *Server) handlePostsPage() http.HandlerFunc {
 			pageNumber = 1
 		}
 
-		numOfPosts, err := s.store.Posts().Count(bson.D{{Key: "deleted", Value: false}})
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusSeeOther)
-		}
-
-		// Calculate maximum number of pages
-		maxPageNumber := numOfPosts / postPerPage
-
-		// Fix number maximum number of pages for odd value
-		if numOfPosts%postPerPage != 0 {
-			maxPageNumber++
-		}
-
-		// If pageNumber out of maximum
-		if pageNumber > uint64(maxPageNumber) {
-			pageNumber = uint64(maxPageNumber)
-		}
-
-		// Number of posts to skip
-		numOfSkip := (pageNumber - 1) * postPerPage
-
 		// Find posts with joining information from categories colleciton
-		posts, err := s.store.Posts().Aggregate(mongo.Pipeline{
-			{{"$lookup", bson.D{
-				{"from", "categories"},
-				{"localField", "category_id"},
-				{"foreignField", "_id"},
-				{"as", "category_slug"}}}},
-			{{"$match", bson.D{{"deleted", false}}}},
-			{{"$project", bson.D{
-				{"title", 1},
-				{"snippet", 1},
-				{"postimg", 1},
-				{"time", 1},
-				{"slug", 1},
-				{"category_slug", "$category_slug.slug"}}}},
-			{{"$sort", bson.D{{"time", -1}}}},
-			{{"$skip", numOfSkip}},
-			{{"$limit", postPerPage}},
-			{{"$unwind", "$category_slug"}}})
+		posts, pageNumber, maxPageNumber, err := s.listPostsPage(r.Context(), store.ListQuery{}, pageNumber)
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
@@ -174,14 +131,14 @@ func (s *Server) handlePostsPage() http.HandlerFunc {
 		// Generate pagination slice
 		pagination := helpers.GeneratePagination(uint(pageNumber), uint(maxPageNumber))
 
-		categories, err := s.store.Categories().FindAll(bson.M{"deleted": false})
+		categories, err := s.store.Categories().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
 			return
 		}
 
-		err = tmpl.ExecuteTemplate(w, "posts.gohtml", &postsPage{
+		err = s.tmpl.ExecuteTemplate(w, "posts.gohtml", &postsPage{
 			Page:          page,
 			Posts:         posts,
 			Categories:    categories,
@@ -197,36 +154,100 @@ func (s *Server) handlePostsPage() http.HandlerFunc {
 	}
 }
 
+// listPostsPage returns live posts of page with joined categories and number of pages
+// Page out of range is replaced by the last one, it costs second query only in that case
+func (s *Server) listPostsPage(ctx context.Context, q store.ListQuery, pageNumber uint64) ([]*models.Post, uint64, uint64, error) {
+	if pageNumber == 0 {
+		pageNumber = 1
+	}
+
+	q.Limit = postPerPage
+	q.Offset = int64(pageNumber-1) * postPerPage
+
+	listing, err := s.store.Posts().ListPublishedWithTotal(ctx, q)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// Calculate maximum number of pages
+	maxPageNumber := uint64(listing.Total) / postPerPage
+
+	// Fix number maximum number of pages for odd value
+	if listing.Total%postPerPage != 0 {
+		maxPageNumber++
+	}
+
+	// If pageNumber out of maximum
+	if maxPageNumber > 0 && pageNumber > maxPageNumber {
+		return s.listPostsPage(ctx, q, maxPageNumber)
+	}
+
+	return listing.Posts, pageNumber, maxPageNumber, nil
+}
+
 func (s *Server) handleSinglePostPage() http.HandlerFunc {
 	type singlePost struct {
 		*models.Post
 		CategoryName string
 		CategoryURL  string
+		Tags         []*models.Tag
+		Author       *models.Author
+		TOC          []*models.TOCItem
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
 
-		category, err := s.store.Categories().FindBySlug(chi.URLParam(r, "categorySlug"))
+		postSlug := chi.URLParam(r, "postSlug")
+
+		post, err := s.store.Posts().FindBySlug(r.Context(), postSlug)
 		if err != nil {
+			if s.redirectOldURL(w, r, models.RedirectPost, postSlug) {
+				return
+			}
+
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
 			return
 		}
 
-		post, err := s.store.Posts().FindBySlug(chi.URLParam(r, "postSlug"))
+		if !post.IsPublished() {
+			s.logger.Logf("[DEBUG] Post %s is %s\n", post.ID.Hex(), post.Status)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		// Category is found by post, so post moved to another category or renamed category are redirected
+		category, err := s.store.Categories().FindByID(r.Context(), post.CategoryID)
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
 			return
 		}
 
+		if category.Slug != chi.URLParam(r, "categorySlug") {
+			post.CategorySlug = category.Slug
+			movedPermanently(w, r, post.GetURL())
+			return
+		}
+
+		tags, err := s.store.Tags().ListByIDs(r.Context(), post.TagIDs)
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		post = s.displayedPost(post)
 		buf := &bytes.Buffer{}
 
-		err = tmpl.ExecuteTemplate(buf, "singlepost.gohtml", &singlePost{
+		err = s.tmpl.ExecuteTemplate(buf, "singlepost.gohtml", &singlePost{
 			Post:         post,
 			CategoryName: category.Title,
 			CategoryURL:  category.URL(),
+			Tags:         tags,
+			Author:       s.findAuthor(r, post),
+			TOC:          models.BuildTOC(post.PageData),
 		})
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
@@ -250,7 +271,10 @@ func (s *Server) handleSingleCategoryPage() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		var pageNumber uint64
+		var (
+			pageNumber uint64
+			err        error
+		)
 
 		pNum := r.URL.Query().Get("page")
 		if pNum != "" {
@@ -264,63 +288,21 @@ func (s *Server) handleSingleCategoryPage() http.HandlerFunc {
 			pageNumber = 1
 		}
 
-		category, err := s.store.Categories().FindBySlug(chi.URLParam(r, "categorySlug"))
+		categorySlug := chi.URLParam(r, "categorySlug")
+
+		category, err := s.store.Categories().FindBySlug(r.Context(), categorySlug)
 		if err != nil {
+			if s.redirectOldURL(w, r, models.RedirectCategory, categorySlug) {
+				return
+			}
+
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
 			return
 		}
 
-		numOfPosts, err := s.store.Posts().Count(bson.D{
-			{Key: "deleted", Value: false},
-			{Key: "category_id", Value: category.ID},
-		})
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusSeeOther)
-		}
-
-		if pageNumber == 0 {
-			s.logger.Logf("[DEBUG] %v\n", pageNumber)
-		}
-
-		// Calculate maximum number of pages
-		maxPageNumber := numOfPosts / postPerPage
-
-		// Fix number maximum number of pages for odd value
-		if numOfPosts%postPerPage != 0 {
-			maxPageNumber++
-		}
-
-		// If pageNumber out of maximum
-		if pageNumber > uint64(maxPageNumber) {
-			pageNumber = uint64(maxPageNumber)
-		}
-
-		// Number of posts to skip
-		numOfSkip := (pageNumber - 1) * postPerPage
-
 		// Find posts with joining information from categories colleciton
-		posts, err := s.store.Posts().Aggregate(mongo.Pipeline{
-			{{"$lookup", bson.D{
-				{"from", "categories"},
-				{"localField", "category_id"},
-				{"foreignField", "_id"},
-				{"as", "category_slug"}}}},
-			{{"$match", bson.D{
-				{"deleted", false},
-				{"category_id", category.ID}}}},
-			{{"$project", bson.D{
-				{"title", 1},
-				{"snippet", 1},
-				{"postimg", 1},
-				{"time", 1},
-				{"slug", 1},
-				{"category_slug", "$category_slug.slug"}}}},
-			{{"$sort", bson.D{{"time", -1}}}},
-			{{"$skip", numOfSkip}},
-			{{"$limit", postPerPage}},
-			{{"$unwind", "$category_slug"}}})
+		posts, pageNumber, maxPageNumber, err := s.listPostsPage(r.Context(), store.ListQuery{CategoryID: category.ID}, pageNumber)
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
@@ -330,7 +312,7 @@ func (s *Server) handleSingleCategoryPage() http.HandlerFunc {
 		// Generate pagination slice
 		pagination := helpers.GeneratePagination(uint(pageNumber), uint(maxPageNumber))
 
-		categories, err := s.store.Categories().FindAll(bson.M{"deleted": false})
+		categories, err := s.store.Categories().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
@@ -339,7 +321,7 @@ func (s *Server) handleSingleCategoryPage() http.HandlerFunc {
 
 		buf := &bytes.Buffer{}
 
-		err = tmpl.ExecuteTemplate(buf, "category.gohtml", &categoryPage{
+		err = s.tmpl.ExecuteTemplate(buf, "category.gohtml", &categoryPage{
 			Page: &models.Page{
 				Title:    category.Title,
 				Subtitle: category.Subtitle,
@@ -362,66 +344,94 @@ func (s *Server) handleSingleCategoryPage() http.HandlerFunc {
 	}
 }
 
-func (s *Server) handleMaterialsPage() http.HandlerFunc {
-	type materialsPage struct {
-		Page    *models.Page
-		MatCats []*models.MaterialShow
+// handleSingleTagPage shows posts of tag page by page and all its materials
+func (s *Server) handleSingleTagPage() http.HandlerFunc {
+	type tagPage struct {
+		Page          *models.Page
+		Posts         []*models.Post
+		Materials     []*models.Material
+		Pagination    []helpers.PaginationLink
+		NumberOfPages int
+		CurrentPage   string
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		page, err := s.store.Pages().FindByURL(r.URL.Path)
+		var (
+			pageNumber uint64
+			err        error
+		)
+
+		pNum := r.URL.Query().Get("page")
+		if pNum != "" {
+			pageNumber, err = strconv.ParseUint(pNum, 10, 64)
+			if err != nil {
+				s.logger.Logf("[DEBUG] %v\n", err)
+				http.Redirect(w, r, "/posts", http.StatusSeeOther)
+				return
+			}
+		} else {
+			pageNumber = 1
+		}
+
+		tag, err := s.store.Tags().FindBySlug(r.Context(), chi.URLParam(r, "tagSlug"))
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		posts, pageNumber, maxPageNumber, err := s.listPostsPage(r.Context(), store.ListQuery{TagID: tag.ID}, pageNumber)
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		materials, err := s.store.Materials().List(r.Context(), store.ListQuery{TagID: tag.ID})
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
 			return
 		}
 
-		mats, err := s.store.MatCategories().Aggregate(mongo.Pipeline{
-			{{
-				Key: "$lookup", Value: bson.D{
-					{Key: "from", Value: "materials"},
-					{Key: "let", Value: bson.D{
-						{Key: "matcat_id", Value: "$_id"},
-					}},
-					{Key: "pipeline", Value: bson.A{
-						bson.D{
-							{Key: "$match", Value: bson.D{
-								{Key: "deleted", Value: false},
-							}},
-						},
-						bson.D{
-							{Key: "$sort", Value: bson.D{
-								{Key: "time", Value: -1},
-							}},
-						},
-						bson.D{
-							{Key: "$match", Value: bson.D{
-								{Key: "$expr", Value: bson.D{
-									{Key: "$eq", Value: bson.A{"$matcategory_id", "$$matcat_id"}},
-								}},
-							}},
-						}, bson.D{
-							{Key: "$limit", Value: 3},
-						},
-					}},
-					{Key: "as", Value: "materials"},
-				},
-			}},
-			{{
-				Key: "$match", Value: bson.D{
-					{Key: "deleted", Value: false},
-				},
-			}},
-			{{
-				Key: "$project", Value: bson.D{
-					{Key: "_id", Value: 1},
-					{Key: "title", Value: 1},
-					{Key: "slug", Value: 1},
-					{Key: "desc", Value: 1},
-					{Key: "materials", Value: 1},
-				},
+		buf := &bytes.Buffer{}
+
+		err = s.tmpl.ExecuteTemplate(buf, "tag.gohtml", &tagPage{
+			Page: &models.Page{
+				Title:    tag.Title,
+				Subtitle: "Записи и материалы по теме",
+				MetaDesc: "Записи и материалы по теме «" + tag.Title + "»",
+				URL:      tag.URL(),
 			},
-			}})
+			Posts:         posts,
+			Materials:     materials,
+			Pagination:    helpers.GeneratePagination(uint(pageNumber), uint(maxPageNumber)),
+			NumberOfPages: int(maxPageNumber),
+			CurrentPage:   strconv.Itoa(int(pageNumber)),
+		})
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			http.Redirect(w, r, "/404", http.StatusSeeOther)
+			return
+		}
+
+		io.Copy(w, buf)
+	}
+}
+
+func (s *Server) handleMaterialsPage() http.HandlerFunc {
+	type materialsPage struct {
+		Page    *models.Page
+		MatCats []*models.MaterialShow
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, ok := s.findPublicPage(w, r, http.StatusSeeOther)
+		if !ok {
+			return
+		}
+
+		mats, err := s.store.MatCategories().ListWithMaterials(r.Context(), store.ListQuery{}, 3)
 		if err != nil {
 			s.logger.Logf("[DEBUG] %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusSeeOther)
@@ -430,7 +440,7 @@ func (s *Server) handleMaterialsPage() http.HandlerFunc {
 
 		buf := &bytes.Buffer{}
 
-		err = tmpl.ExecuteTemplate(buf, "materials.gohtml", materialsPage{
+		err = s.tmpl.ExecuteTemplate(buf, "materials.gohtml", materialsPage{
 			Page:    page,
 			MatCats: mats,
 		})
@@ -450,19 +460,18 @@ func (s *Server) handleServicesPage() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		page, err := s.store.Pages().FindByURL(r.URL.Path)
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusNotFound)
+		page, ok := s.findPublicPage(w, r, http.StatusNotFound)
+		if !ok {
+			return
 		}
 
-		services, err := s.store.Services().FindAll(bson.M{"deleted": false})
+		services, err := s.store.Services().List(r.Context(), store.ListQuery{})
 		if err != nil {
 			s.logger.Logf("[DEBUG] services: %v\n", err)
 			http.Redirect(w, r, "/404", http.StatusNotFound)
 		}
 
-		tmpl.ExecuteTemplate(w, "services.gohtml", &servicepage{
+		s.tmpl.ExecuteTemplate(w, "services.gohtml", &servicepage{
 			Page:     page,
 			Services: services,
 		})
@@ -471,12 +480,11 @@ func (s *Server) handleServicesPage() http.HandlerFunc {
 
 func (s *Server) handleContactsPage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		contactspage, err := s.store.Pages().FindByURL(r.URL.Path)
-		if err != nil {
-			s.logger.Logf("[DEBUG] page: %v\n", err)
-			http.Redirect(w, r, "/404", http.StatusNotFound)
+		contactspage, ok := s.findPublicPage(w, r, http.StatusNotFound)
+		if !ok {
+			return
 		}
 
-		tmpl.ExecuteTemplate(w, "singlepage.gohtml", contactspage)
+		s.tmpl.ExecuteTemplate(w, "singlepage.gohtml", contactspage)
 	}
 }
@@ -0,0 +1,418 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/jsonpatch"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// patchable binds PATCH endpoint to repository of one content type
+// Repositories are resolved on each request, because store is configured after router
+type patchable struct {
+	notFound error
+	exists   error
+	revision string // Key of revisioned document type, empty if content has no revisions
+	// find returns current document and its version
+	find func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error)
+	// save decodes patched JSON of current document and writes its changed fields
+	// It returns saved document and its new version, which is unchanged when nothing was changed
+	save func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error)
+}
+
+// patchables returns patchable content types by their API routes
+func (s *Server) patchables() map[string]patchable {
+	return map[string]patchable{
+		"post": {
+			notFound: helpers.ErrNoPost,
+			exists:   helpers.ErrPostAlreadyExist,
+			revision: "post",
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				post, err := s.store.Posts().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return post, post.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				post := &models.Post{}
+				if err := json.Unmarshal(patched, post); err != nil {
+					return nil, 0, err
+				}
+
+				// Author is set once on create
+				post.ID, post.Version, post.Author = current.(*models.Post).ID, version, current.(*models.Post).Author
+
+				var err error
+				if post.Status, err = keepStatus(post.Status, current.(*models.Post).Status); err != nil {
+					return nil, 0, err
+				}
+
+				post.ApplySchedule(time.Now())
+				s.prepareContent(post)
+
+				fields, err := store.ChangedFields(current, post)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				if err = s.checkTags(ctx, post.TagIDs); err != nil {
+					return nil, 0, err
+				}
+
+				// Edited content of published post goes live only after review
+				post.Status, _ = reviewEdit(post.Status)
+				if fields, err = store.ChangedFields(current, post); err != nil {
+					return nil, 0, err
+				}
+
+				err = s.store.Posts().Patch(ctx, post, fields)
+
+				return post, post.Version, err
+			},
+		},
+		"category": {
+			notFound: helpers.ErrNoCategory,
+			exists:   helpers.ErrCategoryAlreadyExist,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				category, err := s.store.Categories().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return category, category.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				category := &models.Category{}
+				if err := json.Unmarshal(patched, category); err != nil {
+					return nil, 0, err
+				}
+
+				category.ID, category.Version = current.(*models.Category).ID, version
+
+				fields, err := store.ChangedFields(current, category)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				err = s.store.Categories().Patch(ctx, category, fields)
+
+				return category, category.Version, err
+			},
+		},
+		"material": {
+			notFound: helpers.ErrNoMaterial,
+			exists:   helpers.ErrMaterialAlreadyExist,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				material, err := s.store.Materials().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return material, material.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				material := &models.Material{}
+				if err := json.Unmarshal(patched, material); err != nil {
+					return nil, 0, err
+				}
+
+				material.ID, material.Version = current.(*models.Material).ID, version
+
+				fields, err := store.ChangedFields(current, material)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				if err = s.checkTags(ctx, material.TagIDs); err != nil {
+					return nil, 0, err
+				}
+
+				err = s.store.Materials().Patch(ctx, material, fields)
+
+				return material, material.Version, err
+			},
+		},
+		"matcategory": {
+			notFound: helpers.ErrNoMatCategory,
+			exists:   helpers.ErrMatCategoryAlreadyExist,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				matCategory, err := s.store.MatCategories().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return matCategory, matCategory.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				matCategory := &models.MatCategory{}
+				if err := json.Unmarshal(patched, matCategory); err != nil {
+					return nil, 0, err
+				}
+
+				matCategory.ID, matCategory.Version = current.(*models.MatCategory).ID, version
+
+				fields, err := store.ChangedFields(current, matCategory)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				err = s.store.MatCategories().Patch(ctx, matCategory, fields)
+
+				return matCategory, matCategory.Version, err
+			},
+		},
+		"service": {
+			notFound: helpers.ErrNoService,
+			exists:   helpers.ErrServiceAlreadyExist,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				service, err := s.store.Services().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return service, service.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				service := &models.Service{}
+				if err := json.Unmarshal(patched, service); err != nil {
+					return nil, 0, err
+				}
+
+				service.ID, service.Version = current.(*models.Service).ID, version
+
+				fields, err := store.ChangedFields(current, service)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				err = s.store.Services().Patch(ctx, service, fields)
+
+				return service, service.Version, err
+			},
+		},
+		"page": {
+			notFound: helpers.ErrNoPage,
+			exists:   helpers.ErrPageAlreadyExist,
+			revision: "page",
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				page, err := s.store.Pages().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return page, page.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				page := &models.Page{}
+				if err := json.Unmarshal(patched, page); err != nil {
+					return nil, 0, err
+				}
+
+				page.ID, page.Version = current.(*models.Page).ID, version
+
+				var err error
+				if page.Status, err = keepStatus(page.Status, current.(*models.Page).Status); err != nil {
+					return nil, 0, err
+				}
+
+				s.prepareContent(page)
+
+				fields, err := store.ChangedFields(current, page)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				// Edited content of published page goes live only after review
+				page.Status, _ = reviewEdit(page.Status)
+				if fields, err = store.ChangedFields(current, page); err != nil {
+					return nil, 0, err
+				}
+
+				err = s.store.Pages().Patch(ctx, page, fields)
+
+				return page, page.Version, err
+			},
+		},
+		"tag": {
+			notFound: helpers.ErrNoTag,
+			exists:   helpers.ErrTagAlreadyExist,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, int64, error) {
+				tag, err := s.store.Tags().FindByID(ctx, ID)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				return tag, tag.Version, nil
+			},
+			save: func(ctx context.Context, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+				tag := &models.Tag{}
+				if err := json.Unmarshal(patched, tag); err != nil {
+					return nil, 0, err
+				}
+
+				tag.ID, tag.Version = current.(*models.Tag).ID, version
+
+				fields, err := store.ChangedFields(current, tag)
+				if err != nil || len(fields) == 0 {
+					return current, version, err
+				}
+
+				err = s.store.Tags().Patch(ctx, tag, fields)
+
+				return tag, tag.Version, err
+			},
+		},
+	}
+}
+
+// savePatched saves patched document, revisioned one is saved together with its revision
+// Revision records transition when patch returned published document to review
+func (s *Server) savePatched(ctx context.Context, doc patchable, current interface{}, patched []byte, version int64) (interface{}, int64, error) {
+	if doc.revision == "" {
+		return doc.save(ctx, current, patched, version)
+	}
+
+	var (
+		updated        interface{}
+		updatedVersion int64
+		rd             = s.revisionedDocs()[doc.revision]
+	)
+
+	err := s.saveRevision(ctx, rd, usernameFromContext(ctx), "", func(ctx context.Context) (interface{}, *models.Transition, error) {
+		var err error
+		if updated, updatedVersion, err = doc.save(ctx, current, patched, version); err != nil || updatedVersion == version {
+			return nil, nil, err
+		}
+
+		var t *models.Transition
+		if from, to := rd.status(current), rd.status(updated); from != to {
+			t = &models.Transition{Action: models.ActionEdit, From: from, To: to}
+		}
+
+		return updated, t, nil
+	})
+
+	return updated, updatedVersion, err
+}
+
+// handlePatch applies JSON Merge Patch or JSON Patch to document and responds with updated document
+// Patch is applied to JSON representation of document, so it uses the same field names as GET
+// Like PUT it requires If-Match header with version of document
+func (s *Server) handlePatch(doc patchable) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID := r.URL.Query().Get("ID")
+
+		if ID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		objID, err := primitive.ObjectIDFromHex(ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrInvalidObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrInvalidObjectID)
+			return
+		}
+
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		current, currentVersion, err := doc.find(r.Context(), objID)
+
+		switch err {
+		case nil:
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", doc.notFound)
+			s.error(w, r, http.StatusNotFound, doc.notFound)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Patch made for stale version is rejected before it is applied
+		if currentVersion != version {
+			s.respondConflict(w, r, current, currentVersion)
+			return
+		}
+
+		data, err := json.Marshal(current)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		patched, err := jsonpatch.Apply(r.Header.Get("Content-Type"), data, patch)
+
+		switch {
+		case err == nil:
+		case errors.Is(err, jsonpatch.ErrUnsupportedType):
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusUnsupportedMediaType, err)
+			return
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		updated, updatedVersion, err := s.savePatched(r.Context(), doc, current, patched, version)
+
+		switch err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, currentVersion, err := doc.find(r.Context(), objID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, currentVersion)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", doc.notFound)
+			s.error(w, r, http.StatusNotFound, doc.notFound)
+			return
+		case doc.exists:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(updatedVersion))
+		s.respond(w, r, http.StatusOK, updated)
+	}
+}
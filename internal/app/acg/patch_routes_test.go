@@ -0,0 +1,119 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+)
+
+func TestHandlePatch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		patch       string
+		header      http.Header
+		wantCode    int
+		wantTitle   string
+	}{
+		{
+			name:        "Merge patch",
+			contentType: "application/merge-patch+json",
+			patch:       `{"title": "Бухгалтерия"}`,
+			wantCode:    http.StatusOK,
+			wantTitle:   "Бухгалтерия",
+		},
+		{
+			name:        "Plain JSON is merge patch",
+			contentType: "application/json; charset=utf-8",
+			patch:       `{"title": "Бухгалтерия"}`,
+			wantCode:    http.StatusOK,
+			wantTitle:   "Бухгалтерия",
+		},
+		{
+			name:        "JSON patch",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op": "test", "path": "/title", "value": "Налоги и отчетность"}, {"op": "replace", "path": "/title", "value": "Бухгалтерия"}]`,
+			wantCode:    http.StatusOK,
+			wantTitle:   "Бухгалтерия",
+		},
+		{
+			name:        "JSON patch as merge patch",
+			contentType: "application/merge-patch+json",
+			patch:       `[{"op": "replace", "path": "/title", "value": "Бухгалтерия"}]`,
+			wantCode:    http.StatusBadRequest,
+			wantTitle:   "Налоги и отчетность",
+		},
+		{
+			name:        "Failed test operation",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op": "test", "path": "/title", "value": "Бухгалтерия"}, {"op": "replace", "path": "/title", "value": "Бухгалтерия"}]`,
+			wantCode:    http.StatusConflict,
+			wantTitle:   "Налоги и отчетность",
+		},
+		{
+			name:        "Unsupported type",
+			contentType: "text/plain",
+			patch:       `{"title": "Бухгалтерия"}`,
+			wantCode:    http.StatusUnsupportedMediaType,
+			wantTitle:   "Налоги и отчетность",
+		},
+		{
+			name:        "Invalid type",
+			contentType: "application/",
+			patch:       `{"title": "Бухгалтерия"}`,
+			wantCode:    http.StatusUnsupportedMediaType,
+			wantTitle:   "Налоги и отчетность",
+		},
+		{
+			name:        "Without If-Match",
+			contentType: "application/merge-patch+json",
+			patch:       `{"title": "Бухгалтерия"}`,
+			header:      http.Header{},
+			wantCode:    http.StatusPreconditionRequired,
+			wantTitle:   "Налоги и отчетность",
+		},
+		{
+			name:        "Stale version",
+			contentType: "application/merge-patch+json",
+			patch:       `{"title": "Бухгалтерия"}`,
+			header:      ifMatchHeader(1),
+			wantCode:    http.StatusPreconditionFailed,
+			wantTitle:   "Налоги и отчетность",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testServer(t)
+			category := storetest.Category("Налоги и отчетность")
+			assert.NoError(t, s.store.Categories().Create(context.Background(), category))
+
+			header := ifMatchHeader(0)
+			if tc.header != nil {
+				header = tc.header
+			}
+			header.Set("Content-Type", tc.contentType)
+
+			w := serve(t, s, "first_editor", http.MethodPatch, "/api/category/?ID="+category.ID.Hex(), tc.patch, header)
+			assert.Equal(t, tc.wantCode, w.Code)
+
+			if tc.wantCode == http.StatusOK {
+				assert.Equal(t, etag(1), w.Header().Get("ETag"))
+
+				patched := &models.Category{}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(patched))
+				assert.Equal(t, tc.wantTitle, patched.Title)
+				assert.Equal(t, category.Slug, patched.Slug)
+			}
+
+			found, err := s.store.Categories().FindByID(context.Background(), category.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTitle, found.Title)
+		})
+	}
+}
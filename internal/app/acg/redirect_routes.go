@@ -0,0 +1,92 @@
+package acg
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
+// canonicalURL returns current URL of document which had slug of given type
+// Redirects point to documents instead of next slugs, so chain of renames is followed at once and can't loop
+func (s *Server) canonicalURL(ctx context.Context, docType, slug string) (string, error) {
+	redirect, err := s.store.Redirects().FindBySlug(ctx, docType, slug)
+	if err != nil {
+		return "", err
+	}
+
+	switch docType {
+	case models.RedirectPost:
+		post, err := s.store.Posts().FindByID(ctx, redirect.DocID)
+		if err != nil {
+			return "", err
+		}
+
+		// Old URL must not reveal draft or withdrawn post
+		if !post.IsPublished() {
+			return "", store.ErrNotFound
+		}
+
+		return s.postURL(ctx, post)
+	case models.RedirectCategory:
+		category, err := s.store.Categories().FindByID(ctx, redirect.DocID)
+		if err != nil {
+			return "", err
+		}
+
+		return category.URL(), nil
+	case models.RedirectMatCategory:
+		matcat, err := s.store.MatCategories().FindByID(ctx, redirect.DocID)
+		if err != nil {
+			return "", err
+		}
+
+		return "/materials/" + matcat.Slug, nil
+	}
+
+	page, err := s.store.Pages().FindByID(ctx, redirect.DocID)
+	if err != nil {
+		return "", err
+	}
+
+	if !page.IsPublished() {
+		return "", store.ErrNotFound
+	}
+
+	return page.URL, nil
+}
+
+// postURL returns URL of post in its current category
+func (s *Server) postURL(ctx context.Context, post *models.Post) (string, error) {
+	category, err := s.store.Categories().FindByID(ctx, post.CategoryID)
+	if err != nil {
+		return "", err
+	}
+
+	p := *post
+	p.CategorySlug = category.Slug
+
+	return p.GetURL(), nil
+}
+
+// redirectOldURL answers with 301 to current URL of document which had requested slug
+// It returns false when there is no such document, so caller answers with not found
+func (s *Server) redirectOldURL(w http.ResponseWriter, r *http.Request, docType, slug string) bool {
+	url, err := s.canonicalURL(r.Context(), docType, slug)
+	if err != nil || url == r.URL.Path {
+		return false
+	}
+
+	movedPermanently(w, r, url)
+	return true
+}
+
+// movedPermanently redirects to url keeping query of request, e.g. page of listing
+func movedPermanently(w http.ResponseWriter, r *http.Request, url string) {
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+
+	http.Redirect(w, r, url, http.StatusMovedPermanently)
+}
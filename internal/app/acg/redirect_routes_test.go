@@ -0,0 +1,92 @@
+package acg
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+)
+
+func TestRedirectOldURL(t *testing.T) {
+	ctx := context.Background()
+	s := testServer(t)
+
+	category := storetest.Category("Налоги и отчетность")
+	assert.NoError(t, s.store.Categories().Create(ctx, category))
+
+	post := storetest.Post("Первая запись", category.ID)
+	draft := storetest.Post("Черновик записи", category.ID)
+	draft.Status = models.StatusDraft
+	about := storetest.Page("/about")
+	contacts := storetest.Page("/kontakty")
+
+	assert.NoError(t, s.store.Posts().Create(ctx, post))
+	assert.NoError(t, s.store.Posts().Create(ctx, draft))
+	assert.NoError(t, s.store.Pages().Create(ctx, about))
+	assert.NoError(t, s.store.Pages().Create(ctx, contacts))
+
+	// Slugs and URLs are changed, old ones are recorded by store
+	post.Slug = "novaya_zapis"
+	draft.Slug = "noviy_chernovik"
+	about.URL = "/o-kompanii"
+	contacts.URL = "/contacts-old"
+	assert.NoError(t, s.store.Posts().Update(ctx, post))
+	assert.NoError(t, s.store.Posts().Update(ctx, draft))
+	assert.NoError(t, s.store.Pages().Update(ctx, about))
+	assert.NoError(t, s.store.Pages().Update(ctx, contacts))
+
+	testCases := []struct {
+		name         string
+		target       string
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:         "Renamed post",
+			target:       "/category/" + category.Slug + "/pervaya_zapis",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/category/" + category.Slug + "/novaya_zapis",
+		},
+		{
+			name:         "Query is kept",
+			target:       "/category/" + category.Slug + "/pervaya_zapis?utm_source=mail",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/category/" + category.Slug + "/novaya_zapis?utm_source=mail",
+		},
+		{
+			name:         "Renamed draft is not revealed",
+			target:       "/category/" + category.Slug + "/chernovik_zapisi",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/404",
+		},
+		{
+			name:         "Page moved from fixed route",
+			target:       "/about",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/o-kompanii",
+		},
+		{
+			name:         "Page moved from unrouted URL",
+			target:       "/kontakty",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "/contacts-old",
+		},
+		{
+			name:     "Unknown URL",
+			target:   "/unknown",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(t, s, "", http.MethodGet, tc.target, "", nil)
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			assert.Equal(t, tc.wantLocation, w.Header().Get("Location"))
+		})
+	}
+}
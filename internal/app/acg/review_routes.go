@@ -0,0 +1,277 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reviewable binds review endpoints to documents of one type
+// Repositories are resolved on each request, because store is configured after router
+type reviewable struct {
+	revision string // Key of revisioned document type, transitions are recorded in its revisions
+	notFound error
+	// find returns current document, its status and version
+	find func(ctx context.Context, ID primitive.ObjectID) (interface{}, models.Status, int64, error)
+	// transit writes status of transition to current document and returns written document and its new version
+	// Transition may be adjusted, e.g. approved post with publish_at in future is scheduled
+	transit func(ctx context.Context, current interface{}, t *models.Transition) (interface{}, int64, error)
+}
+
+// reviewables returns reviewable document types by their API routes
+func (s *Server) reviewables() map[string]reviewable {
+	return map[string]reviewable{
+		"post": {
+			revision: "post",
+			notFound: helpers.ErrNoPost,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, models.Status, int64, error) {
+				post, err := s.store.Posts().FindByID(ctx, ID)
+				if err != nil {
+					return nil, "", 0, err
+				}
+
+				return post, post.Status, post.Version, nil
+			},
+			transit: func(ctx context.Context, current interface{}, t *models.Transition) (interface{}, int64, error) {
+				post := *current.(*models.Post)
+				post.Status = t.To
+
+				if t.Action == models.ActionApprove {
+					post.Approve(time.Now())
+					t.To = post.Status
+				}
+
+				fields, err := store.ChangedFields(current, &post)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				err = s.store.Posts().Patch(ctx, &post, fields)
+
+				return &post, post.Version, err
+			},
+		},
+		"page": {
+			revision: "page",
+			notFound: helpers.ErrNoPage,
+			find: func(ctx context.Context, ID primitive.ObjectID) (interface{}, models.Status, int64, error) {
+				page, err := s.store.Pages().FindByID(ctx, ID)
+				if err != nil {
+					return nil, "", 0, err
+				}
+
+				return page, page.Status, page.Version, nil
+			},
+			transit: func(ctx context.Context, current interface{}, t *models.Transition) (interface{}, int64, error) {
+				page := *current.(*models.Page)
+				page.Status = t.To
+
+				err := s.store.Pages().Patch(ctx, &page, []string{"status"})
+
+				return &page, page.Version, err
+			},
+		},
+	}
+}
+
+// mountReview adds review endpoints of document type to r
+func (s *Server) mountReview(r chi.Router, doc reviewable) {
+	r.Post("/review", s.handleReview(doc))
+	r.Get("/reviews", s.handleReviewHistory(doc))
+}
+
+// keepStatus returns current status when editor sent the same or no status
+// Status is changed only by review actions and schedule, so they are recorded
+func keepStatus(status, current models.Status) (models.Status, error) {
+	if status != "" && status != current {
+		return "", helpers.ErrStatusReadOnly
+	}
+
+	return current, nil
+}
+
+// reviewEdit returns status of document after its content was edited and transition when status is changed
+// Edited published or scheduled document goes back to review, so changed content goes live only after approval
+func reviewEdit(status models.Status) (models.Status, *models.Transition) {
+	switch status {
+	case models.StatusPublished, models.StatusScheduled:
+		return models.StatusInReview, &models.Transition{Action: models.ActionEdit, From: status, To: models.StatusInReview}
+	}
+
+	return status, nil
+}
+
+// submitter returns editor who sent document to review last time, empty when it was never sent
+// Document goes to review by submit or by edit of published document, both are recorded in its revisions
+func (s *Server) submitter(ctx context.Context, ID primitive.ObjectID) (string, error) {
+	revs, err := s.store.Revisions().ListByDocument(ctx, ID, store.ListQuery{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, rev := range revs {
+		if rev.Transition != nil && rev.Transition.To == models.StatusInReview {
+			return rev.Author, nil
+		}
+	}
+
+	return "", nil
+}
+
+// handleReview changes status of document by review action
+// Version from If-Match is required, so reviewer approves exactly the version they have read
+func (s *Server) handleReview(doc reviewable) http.HandlerFunc {
+	type req struct {
+		Action  string `json:"action"`
+		Comment string `json:"comment"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID := r.URL.Query().Get("ID")
+
+		if ID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		objID, err := primitive.ObjectIDFromHex(ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrInvalidObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrInvalidObjectID)
+			return
+		}
+
+		version, code, err := ifMatch(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		req := &req{}
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		current, status, currentVersion, err := doc.find(r.Context(), objID)
+
+		switch err {
+		case nil:
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", doc.notFound)
+			s.error(w, r, http.StatusNotFound, doc.notFound)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if currentVersion != version {
+			s.respondConflict(w, r, current, currentVersion)
+			return
+		}
+
+		submitter, err := s.submitter(r.Context(), objID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		editor := models.Editor{Username: usernameFromContext(r.Context()), Reviewer: s.isReviewer(r.Context())}
+		t, err := models.Review(req.Action, status, editor, submitter, req.Comment)
+
+		switch err {
+		case nil:
+		case helpers.ErrReviewNotAllowed:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+			return
+		case helpers.ErrNotReviewer, helpers.ErrSelfReview:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusForbidden, err)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = s.saveRevision(r.Context(), s.revisionedDocs()[doc.revision], usernameFromContext(r.Context()), req.Comment, func(ctx context.Context) (interface{}, *models.Transition, error) {
+			written, v, err := doc.transit(ctx, current, t)
+			version = v
+			return written, t, err
+		})
+
+		switch err {
+		case nil:
+		case store.ErrVersionConflict:
+			current, _, currentVersion, err := doc.find(r.Context(), objID)
+			if err != nil {
+				s.logger.Logf("[ERROR] %v\n", err)
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			s.respondConflict(w, r, current, currentVersion)
+			return
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", doc.notFound)
+			s.error(w, r, http.StatusNotFound, doc.notFound)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(version))
+		s.respond(w, r, http.StatusOK, t)
+	}
+}
+
+// handleReviewHistory returns revisions of document which record changes of its status, newest first
+func (s *Server) handleReviewHistory(doc reviewable) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID := r.URL.Query().Get("ID")
+
+		if ID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		objID, err := primitive.ObjectIDFromHex(ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrInvalidObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrInvalidObjectID)
+			return
+		}
+
+		revs, err := s.store.Revisions().ListByDocument(r.Context(), objID, store.ListQuery{})
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		transitions := make([]*models.Revision, 0)
+		for _, rev := range revs {
+			if rev.Transition != nil {
+				transitions = append(transitions, rev)
+			}
+		}
+
+		s.respond(w, r, http.StatusOK, transitions)
+	}
+}
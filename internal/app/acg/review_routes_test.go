@@ -0,0 +1,150 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleReview(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reviewers  []string
+		submitter  string // Editor who submits draft before action, empty leaves it draft
+		editor     string
+		action     string
+		wantCode   int
+		wantErr    error
+		wantStatus models.Status
+	}{
+		{
+			name:       "Approve",
+			reviewers:  []string{"chief_editor"},
+			submitter:  "first_editor",
+			editor:     "chief_editor",
+			action:     models.ActionApprove,
+			wantCode:   http.StatusOK,
+			wantStatus: models.StatusPublished,
+		},
+		{
+			name:       "Approve draft",
+			reviewers:  []string{"chief_editor"},
+			editor:     "chief_editor",
+			action:     models.ActionApprove,
+			wantCode:   http.StatusConflict,
+			wantErr:    helpers.ErrReviewNotAllowed,
+			wantStatus: models.StatusDraft,
+		},
+		{
+			name:       "Submit twice",
+			reviewers:  []string{"chief_editor"},
+			submitter:  "first_editor",
+			editor:     "first_editor",
+			action:     models.ActionSubmit,
+			wantCode:   http.StatusConflict,
+			wantErr:    helpers.ErrReviewNotAllowed,
+			wantStatus: models.StatusInReview,
+		},
+		{
+			name:       "Approve by not reviewer",
+			reviewers:  []string{"chief_editor"},
+			submitter:  "first_editor",
+			editor:     "second_editor",
+			action:     models.ActionApprove,
+			wantCode:   http.StatusForbidden,
+			wantErr:    helpers.ErrNotReviewer,
+			wantStatus: models.StatusInReview,
+		},
+		{
+			name:       "Approve own submission",
+			reviewers:  []string{"chief_editor", "second_chief"},
+			submitter:  "chief_editor",
+			editor:     "chief_editor",
+			action:     models.ActionApprove,
+			wantCode:   http.StatusForbidden,
+			wantErr:    helpers.ErrSelfReview,
+			wantStatus: models.StatusInReview,
+		},
+		{
+			name:       "Approve without reviewers",
+			submitter:  "first_editor",
+			editor:     "chief_editor",
+			action:     models.ActionApprove,
+			wantCode:   http.StatusForbidden,
+			wantErr:    helpers.ErrNotReviewer,
+			wantStatus: models.StatusInReview,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := testServer(t, tc.reviewers...)
+
+			post := storetest.Post("Первая запись", primitive.NewObjectID())
+			post.Status = models.StatusDraft
+			assert.NoError(t, s.store.Posts().Create(ctx, post))
+
+			target := "/api/post/review?ID=" + post.ID.Hex()
+			var version int64
+
+			if tc.submitter != "" {
+				w := serve(t, s, tc.submitter, http.MethodPost, target, `{"action": "submit"}`, ifMatchHeader(version))
+				if !assert.Equal(t, http.StatusOK, w.Code) {
+					return
+				}
+				version++
+			}
+
+			body, err := json.Marshal(map[string]string{"action": tc.action})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := serve(t, s, tc.editor, http.MethodPost, target, string(body), ifMatchHeader(version))
+			assert.Equal(t, tc.wantCode, w.Code)
+
+			if tc.wantErr != nil {
+				var resp struct {
+					Error string `json:"error"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, tc.wantErr.Error(), resp.Error)
+			}
+
+			found, err := s.store.Posts().FindByID(ctx, post.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, found.Status)
+		})
+	}
+}
+
+func TestHandleReview_IfMatch(t *testing.T) {
+	ctx := context.Background()
+	s := testServer(t, "chief_editor")
+
+	post := storetest.Post("Первая запись", primitive.NewObjectID())
+	post.Status = models.StatusDraft
+	assert.NoError(t, s.store.Posts().Create(ctx, post))
+
+	target := "/api/post/review?ID=" + post.ID.Hex()
+
+	w := serve(t, s, "first_editor", http.MethodPost, target, `{"action": "submit"}`, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	w = serve(t, s, "first_editor", http.MethodPost, target, `{"action": "submit"}`, ifMatchHeader(0))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, etag(1), w.Header().Get("ETag"))
+
+	// Reviewer approves exactly the version they have read
+	w = serve(t, s, "chief_editor", http.MethodPost, target, `{"action": "approve"}`, ifMatchHeader(0))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, etag(1), w.Header().Get("ETag"))
+}
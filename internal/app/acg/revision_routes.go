@@ -0,0 +1,308 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// revisioned binds revision endpoints to documents of one type
+// Repositories are resolved on each request, because store is configured after router
+type revisioned struct {
+	docType  string
+	name     string
+	notFound error
+	// revision returns new revision with snapshot of written document
+	revision func(written interface{}, author, comment string) *models.Revision
+	// status returns status of document
+	status func(doc interface{}) models.Status
+	// rollback replaces live document with snapshot of revision and returns written document
+	// and transition when rolled back content returns published document to review, ErrNotFound means document is not live
+	rollback func(ctx context.Context, rev *models.Revision) (interface{}, *models.Transition, error)
+}
+
+// revisionedDocs returns revisioned document types by their API routes
+func (s *Server) revisionedDocs() map[string]revisioned {
+	return map[string]revisioned{
+		"post": {
+			docType:  models.RevisionPost,
+			name:     "Post",
+			notFound: helpers.ErrNoPost,
+			revision: func(written interface{}, author, comment string) *models.Revision {
+				return models.NewPostRevision(written.(*models.Post), author, comment)
+			},
+			status: func(doc interface{}) models.Status {
+				return doc.(*models.Post).Status
+			},
+			rollback: func(ctx context.Context, rev *models.Revision) (interface{}, *models.Transition, error) {
+				current, err := s.store.Posts().FindByID(ctx, rev.DocID)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				// Status is changed only by review, so snapshot brings back content only
+				post := *rev.Post
+				post.Version = current.Version
+				post.Status, post.PublishAt, post.UnpublishAt, post.Time = current.Status, current.PublishAt, current.UnpublishAt, current.Time
+				post.Author = current.Author
+
+				// Category and tags of snapshot may be trashed since, post must not point to them like on create and update
+				if _, err = s.store.Categories().FindByID(ctx, post.CategoryID); err != nil {
+					if err == store.ErrNotFound {
+						err = helpers.ErrNoCategory
+					}
+					return nil, nil, err
+				}
+
+				if err = s.checkTags(ctx, post.TagIDs); err != nil {
+					return nil, nil, err
+				}
+
+				s.prepareContent(&post)
+
+				var t *models.Transition
+				post.Status, t = reviewEdit(post.Status)
+
+				return &post, t, s.store.Posts().Update(ctx, &post)
+			},
+		},
+		"page": {
+			docType:  models.RevisionPage,
+			name:     "Page",
+			notFound: helpers.ErrNoPage,
+			revision: func(written interface{}, author, comment string) *models.Revision {
+				return models.NewPageRevision(written.(*models.Page), author, comment)
+			},
+			status: func(doc interface{}) models.Status {
+				return doc.(*models.Page).Status
+			},
+			rollback: func(ctx context.Context, rev *models.Revision) (interface{}, *models.Transition, error) {
+				current, err := s.store.Pages().FindByID(ctx, rev.DocID)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				page := *rev.Page
+				page.Version = current.Version
+				page.Status = current.Status
+				s.prepareContent(&page)
+
+				var t *models.Transition
+				page.Status, t = reviewEdit(page.Status)
+
+				return &page, t, s.store.Pages().Update(ctx, &page)
+			},
+		},
+	}
+}
+
+// mountRevisions adds revision endpoints of document type to r
+func (s *Server) mountRevisions(r chi.Router, doc revisioned) {
+	r.Get("/revisions", s.handleRevisionList(doc))
+	r.Get("/revisions/diff", s.handleRevisionDiff(doc))
+	r.Get("/revision", s.handleRevisionGet(doc))
+	r.Post("/revision/restore", s.handleRevisionRestore(doc))
+}
+
+// saveRevision runs write of document and creates its revision in one transaction, so every saved change has revision
+// Revision is made of document returned by write, which is exactly the state written by this editor
+// Write returns nil document when it has changed nothing, then no revision is created,
+// and transition when it has changed status of document
+func (s *Server) saveRevision(ctx context.Context, doc revisioned, author, comment string, write func(ctx context.Context) (interface{}, *models.Transition, error)) error {
+	return s.store.WithTransaction(ctx, func(ctx context.Context) error {
+		written, t, err := write(ctx)
+		if err != nil || written == nil {
+			return err
+		}
+
+		rev := doc.revision(written, author, comment)
+		rev.Transition = t
+
+		return s.store.Revisions().Create(ctx, rev)
+	})
+}
+
+// findRevision returns revision by hex ID only if it belongs to document type
+func (s *Server) findRevision(ctx context.Context, doc revisioned, ID string) (*models.Revision, int, error) {
+	objID, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return nil, http.StatusBadRequest, helpers.ErrInvalidObjectID
+	}
+
+	rev, err := s.store.Revisions().FindByID(ctx, objID)
+
+	switch {
+	case err == store.ErrNotFound, err == nil && rev.DocType != doc.docType:
+		return nil, http.StatusNotFound, helpers.ErrNoRevision
+	case err != nil:
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return rev, http.StatusOK, nil
+}
+
+// handleRevisionList returns revisions of document without snapshots, newest first
+func (s *Server) handleRevisionList(doc revisioned) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID := r.URL.Query().Get("ID")
+
+		if ID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		objID, err := primitive.ObjectIDFromHex(ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrInvalidObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrInvalidObjectID)
+			return
+		}
+
+		q, err := listQuery(r)
+		if err != nil {
+			s.logger.Logf("[DEBUG] during parse list query: %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		revs, err := s.store.Revisions().ListByDocument(r.Context(), objID, q)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, revs)
+	}
+}
+
+// handleRevisionGet returns revision with snapshot of document
+func (s *Server) handleRevisionGet(doc revisioned) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ID := r.URL.Query().Get("ID")
+
+		if ID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		rev, code, err := s.findRevision(r.Context(), doc, ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, rev)
+	}
+}
+
+// handleRevisionDiff returns block-level diff between two revisions of the same document
+func (s *Server) handleRevisionDiff(doc revisioned) http.HandlerFunc {
+	type resp struct {
+		From    *models.Revision     `json:"from"`
+		To      *models.Revision     `json:"to"`
+		Changes []models.BlockChange `json:"changes"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		fromID, toID := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+
+		if fromID == "" || toID == "" {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNoRequestParams)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrNoRequestParams)
+			return
+		}
+
+		from, code, err := s.findRevision(r.Context(), doc, fromID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		to, code, err := s.findRevision(r.Context(), doc, toID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		if from.DocID != to.DocID {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrRevisionsDiffer)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrRevisionsDiffer)
+			return
+		}
+
+		res := resp{
+			From:    from,
+			To:      to,
+			Changes: models.DiffBlocks(from.Blocks(), to.Blocks()),
+		}
+
+		// Snapshots are replaced by changes
+		from.Post, from.Page, to.Post, to.Page = nil, nil, nil, nil
+
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
+
+// handleRevisionRestore replaces document with snapshot of revision
+// Restore is saved as new revision, so it can be rolled back too
+func (s *Server) handleRevisionRestore(doc revisioned) http.HandlerFunc {
+	type req struct {
+		ID string `json:"revisionID"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &req{}
+		var err error
+
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		rev, code, err := s.findRevision(r.Context(), doc, req.ID)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, code, err)
+			return
+		}
+
+		err = s.saveRevision(r.Context(), doc, usernameFromContext(r.Context()), "Restored from revision "+rev.ID.Hex(), func(ctx context.Context) (interface{}, *models.Transition, error) {
+			return doc.rollback(ctx, rev)
+		})
+
+		switch err {
+		case nil:
+		case store.ErrNotFound:
+			// Deleted documents must be restored from trash first
+			s.logger.Logf("[ERROR] %v\n", doc.notFound)
+			s.error(w, r, http.StatusNotFound, doc.notFound)
+			return
+		case helpers.ErrNoCategory, helpers.ErrNoTag:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusNotFound, err)
+			return
+		case store.ErrVersionConflict, helpers.ErrPostAlreadyExist, helpers.ErrPageAlreadyExist:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+			return
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, fmt.Sprintf("%s (%s) successfully restored from revision %s", doc.name, rev.DocID.Hex(), rev.ID.Hex()))
+	}
+}
@@ -0,0 +1,102 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleRevisionRestore(t *testing.T) {
+	testCases := []struct {
+		name string
+		// change makes snapshot of revision invalid after it is saved
+		change    func(t *testing.T, s *Server, old *models.Category, tag *models.Tag)
+		wantCode  int
+		wantErr   error
+		wantTitle string
+	}{
+		{
+			name:      "Restore",
+			change:    func(t *testing.T, s *Server, old *models.Category, tag *models.Tag) {},
+			wantCode:  http.StatusOK,
+			wantTitle: "Первая запись",
+		},
+		{
+			name: "Trashed category",
+			change: func(t *testing.T, s *Server, old *models.Category, tag *models.Tag) {
+				assert.NoError(t, s.store.Categories().Delete(context.Background(), old.ID, "first_editor"))
+			},
+			wantCode:  http.StatusNotFound,
+			wantErr:   helpers.ErrNoCategory,
+			wantTitle: "Запись в новой категории",
+		},
+		{
+			name: "Trashed tag",
+			change: func(t *testing.T, s *Server, old *models.Category, tag *models.Tag) {
+				assert.NoError(t, s.store.Tags().Delete(context.Background(), tag.ID, "first_editor"))
+			},
+			wantCode:  http.StatusNotFound,
+			wantErr:   helpers.ErrNoTag,
+			wantTitle: "Запись в новой категории",
+		},
+		{
+			name: "Slug taken",
+			change: func(t *testing.T, s *Server, old *models.Category, tag *models.Tag) {
+				assert.NoError(t, s.store.Posts().Create(context.Background(), storetest.Post("Первая запись", old.ID)))
+			},
+			wantCode:  http.StatusConflict,
+			wantErr:   helpers.ErrPostAlreadyExist,
+			wantTitle: "Запись в новой категории",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := testServer(t)
+
+			old := storetest.Category("Налоги и отчетность")
+			current := storetest.Category("Бухгалтерия")
+			tag := storetest.Tag("Отчетность")
+			assert.NoError(t, s.store.Categories().Create(ctx, old))
+			assert.NoError(t, s.store.Categories().Create(ctx, current))
+			assert.NoError(t, s.store.Tags().Create(ctx, tag))
+
+			post := storetest.Post("Первая запись", old.ID)
+			post.TagIDs = []primitive.ObjectID{tag.ID}
+			assert.NoError(t, s.store.Posts().Create(ctx, post))
+
+			rev := models.NewPostRevision(post, "first_editor", "")
+			assert.NoError(t, s.store.Revisions().Create(ctx, rev))
+
+			// Post is moved to another category under new slug, so old slug is free
+			moved := storetest.Post("Запись в новой категории", current.ID)
+			moved.ID = post.ID
+			assert.NoError(t, s.store.Posts().Update(ctx, moved))
+
+			tc.change(t, s, old, tag)
+
+			w := serve(t, s, "first_editor", http.MethodPost, "/api/post/revision/restore", `{"revisionID": "`+rev.ID.Hex()+`"}`, nil)
+			assert.Equal(t, tc.wantCode, w.Code)
+
+			if tc.wantErr != nil {
+				var resp struct {
+					Error string `json:"error"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, tc.wantErr.Error(), resp.Error)
+			}
+
+			found, err := s.store.Posts().FindByID(ctx, post.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTitle, found.Title)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package acg
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publishCheckInterval is period between checks of posts due to be published or unpublished
+const publishCheckInterval = time.Minute
+
+// calendarDays is length of calendar when its end is not specified
+const calendarDays = 30
+
+// schedulerAuthor is author of revisions saved after changes made by schedule
+const schedulerAuthor = "scheduler"
+
+// runPublisher applies schedule of posts right away and then every publishCheckInterval until ctx is done
+func (s *Server) runPublisher(ctx context.Context) {
+	ticker := time.NewTicker(publishCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDuePosts(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDuePosts publishes scheduled posts and withdraws published ones whose time has come
+// Post changed by editor meanwhile is skipped, the next check picks it up again
+func (s *Server) publishDuePosts(ctx context.Context) {
+	now := time.Now()
+
+	// Time of scheduled post is its publish_at
+	due := []store.ListQuery{
+		{Status: models.StatusScheduled, To: now},
+		{Status: models.StatusPublished, UnpublishBefore: now},
+	}
+
+	for _, q := range due {
+		posts, err := s.store.Posts().List(ctx, q)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Logf("[ERROR] During search of %s posts due by schedule: %v\n", q.Status, err)
+			}
+			continue
+		}
+
+		for _, post := range posts {
+			s.applySchedule(ctx, post, now)
+		}
+	}
+}
+
+// applySchedule writes status of post changed by its schedule and saves revision of it
+func (s *Server) applySchedule(ctx context.Context, post *models.Post, now time.Time) {
+	updated := *post
+	updated.ApplySchedule(now)
+
+	fields, err := store.ChangedFields(post, &updated)
+	if err != nil || len(fields) == 0 {
+		return
+	}
+
+	t := &models.Transition{Action: models.ActionSchedule, From: post.Status, To: updated.Status}
+	err = s.saveRevision(ctx, s.revisionedDocs()["post"], schedulerAuthor, string(updated.Status)+" by schedule", func(ctx context.Context) (interface{}, *models.Transition, error) {
+		return &updated, t, s.store.Posts().Patch(ctx, &updated, fields)
+	})
+
+	switch err {
+	case nil:
+	case store.ErrVersionConflict, store.ErrNotFound:
+		s.logger.Logf("[WARN] Post %s was changed during publication by schedule\n", post.ID.Hex())
+		return
+	default:
+		s.logger.Logf("[ERROR] During publication of post %s by schedule: %v\n", post.ID.Hex(), err)
+		return
+	}
+
+	s.logger.Logf("[INFO] Post %s is %s by schedule\n", post.ID.Hex(), updated.Status)
+}
+
+// handlePostCalendar returns scheduled posts grouped by days of publication
+// Calendar starts today and lasts calendarDays unless from and to are specified
+func (s *Server) handlePostCalendar() http.HandlerFunc {
+	type entry struct {
+		ID          primitive.ObjectID `json:"_id"`
+		Title       string             `json:"title"`
+		Slug        string             `json:"slug"`
+		PublishAt   time.Time          `json:"publish_at"`
+		UnpublishAt time.Time          `json:"unpublish_at,omitempty"`
+	}
+	type day struct {
+		Date  string   `json:"date"`
+		Posts []*entry `json:"posts"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := listQuery(r)
+		if err != nil {
+			s.logger.Logf("[DEBUG] during parse list query: %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if q.From.IsZero() {
+			now := time.Now()
+			q.From = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		}
+
+		if q.To.IsZero() {
+			q.To = q.From.AddDate(0, 0, calendarDays)
+		}
+
+		q.Status, q.Sort = models.StatusScheduled, store.SortOldest
+
+		posts, err := s.store.Posts().List(r.Context(), q)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		days := make([]*day, 0)
+		for _, p := range posts {
+			date := p.PublishAt.Local().Format(dateLayout)
+			if len(days) == 0 || days[len(days)-1].Date != date {
+				days = append(days, &day{Date: date})
+			}
+
+			last := days[len(days)-1]
+			last.Posts = append(last.Posts, &entry{
+				ID:          p.ID,
+				Title:       p.Title,
+				Slug:        p.Slug,
+				PublishAt:   p.PublishAt,
+				UnpublishAt: p.UnpublishAt,
+			})
+		}
+
+		s.respond(w, r, http.StatusOK, days)
+	}
+}
@@ -0,0 +1,190 @@
+package acg
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/search"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
+// searchRebuildInterval is period between full rebuilds of search index
+// Rebuild picks up changes made by other instances or directly in database
+const searchRebuildInterval = 15 * time.Minute
+
+const resultsPerPage = 10
+
+// configureSearch wraps store with search indexer and fills index with current content
+// Failed initial build is not fatal, index is rebuilt again by background job
+func (s *Server) configureSearch() {
+	s.search = search.NewStore(s.store, search.NewIndex(), s.logger)
+	s.store = s.search
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.DBMigrateTimeout)*time.Second)
+	defer cancel()
+
+	if err := s.search.Rebuild(ctx); err != nil {
+		s.logger.Logf("[ERROR] During build of search index: %v\n", err)
+	}
+}
+
+// runSearchRebuilder rebuilds search index periodically and when writes request it until ctx is done
+func (s *Server) runSearchRebuilder(ctx context.Context) {
+	ticker := time.NewTicker(searchRebuildInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.search.Pending():
+		}
+
+		if err := s.search.Rebuild(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Logf("[ERROR] During rebuild of search index: %v\n", err)
+		}
+	}
+}
+
+// searchQuery parses q, type, deleted, limit and skip parameters of admin search
+func searchQuery(r *http.Request) (search.Query, error) {
+	lq, err := listQuery(r)
+	if err != nil {
+		return search.Query{}, err
+	}
+
+	q := search.Query{
+		Text:   lq.Text,
+		Hidden: true,
+		Limit:  int(lq.Limit),
+		Offset: int(lq.Offset),
+	}
+
+	if strings.TrimSpace(q.Text) == "" {
+		return q, helpers.ErrNoSearchText
+	}
+
+	if types := r.URL.Query().Get("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			switch t {
+			case search.TypePost, search.TypePage, search.TypeMaterial, search.TypeService:
+				q.Types = append(q.Types, t)
+			default:
+				return q, helpers.ErrUnknownSearchType
+			}
+		}
+	}
+
+	switch r.URL.Query().Get("deleted") {
+	case "":
+		q.Deleted = store.NotDeleted
+	case "any":
+		q.Deleted = store.AnyDeleted
+	case "only":
+		q.Deleted = store.OnlyDeleted
+	default:
+		return q, helpers.ErrUnknownDeletedFilter
+	}
+
+	return q, nil
+}
+
+// handleSearch returns ranked documents of all types matching q
+func (s *Server) handleSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := searchQuery(r)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, s.search.Search(q))
+	}
+}
+
+// handleSearchPage renders public search results with highlighted matches
+func (s *Server) handleSearchPage() http.HandlerFunc {
+	type searchPage struct {
+		Page          *models.Page
+		Query         string
+		Total         int
+		Hits          []*search.Hit
+		Pagination    []helpers.PaginationLink
+		NumberOfPages int
+		CurrentPage   string
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			pageNumber uint64 = 1
+			err        error
+		)
+
+		text := strings.TrimSpace(r.URL.Query().Get("q"))
+
+		if pNum := r.URL.Query().Get("page"); pNum != "" {
+			pageNumber, err = strconv.ParseUint(pNum, 10, 64)
+			if err != nil || pageNumber == 0 {
+				s.logger.Logf("[DEBUG] %v\n", err)
+				http.Redirect(w, r, "/search?q="+url.QueryEscape(text), http.StatusSeeOther)
+				return
+			}
+		}
+
+		res := s.search.Search(search.Query{
+			Text:   text,
+			Limit:  resultsPerPage,
+			Offset: int(pageNumber-1) * resultsPerPage,
+		})
+
+		maxPageNumber := (res.Total + resultsPerPage - 1) / resultsPerPage
+
+		// Page out of range is redirected to the last one, offset of huge page may overflow
+		if maxPageNumber > 0 && pageNumber > uint64(maxPageNumber) {
+			http.Redirect(w, r, "/search?q="+url.QueryEscape(text)+"&page="+strconv.Itoa(maxPageNumber), http.StatusSeeOther)
+			return
+		}
+
+		page := &models.Page{
+			Title:    "Поиск",
+			MetaDesc: "Поиск по статьям, материалам и услугам",
+			URL:      "/search",
+		}
+
+		switch {
+		case text == "":
+			page.Subtitle = "Введите запрос, чтобы найти статьи, материалы и услуги"
+		case res.Total == 0:
+			page.Subtitle = "По запросу «" + text + "» ничего не найдено"
+		default:
+			page.Subtitle = "Найдено результатов: " + strconv.Itoa(res.Total)
+		}
+
+		var pagination []helpers.PaginationLink
+		if maxPageNumber > 1 {
+			pagination = helpers.GeneratePagination(uint(pageNumber), uint(maxPageNumber))
+		}
+
+		err = s.tmpl.ExecuteTemplate(w, "search.gohtml", &searchPage{
+			Page:          page,
+			Query:         text,
+			Total:         res.Total,
+			Hits:          res.Hits,
+			Pagination:    pagination,
+			NumberOfPages: maxPageNumber,
+			CurrentPage:   strconv.FormatUint(pageNumber, 10),
+		})
+		if err != nil {
+			s.logger.Logf("[DEBUG] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package acg
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleSearch_Paginate(t *testing.T) {
+	s := testServer(t)
+	assert.NoError(t, s.store.Posts().Create(context.Background(), storetest.Post("Первая запись", primitive.NewObjectID())))
+
+	testCases := []struct {
+		name         string
+		username     string
+		target       string
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:     "Negative skip",
+			username: "first_editor",
+			target:   "/api/search?q=запись&skip=-1",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Negative limit",
+			username: "first_editor",
+			target:   "/api/search?q=запись&limit=-1",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "First page",
+			username: "first_editor",
+			target:   "/api/search?q=запись&skip=0&limit=10",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:         "Page out of range",
+			target:       "/search?q=запись&page=4611686018427387905",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/search?q=%D0%B7%D0%B0%D0%BF%D0%B8%D1%81%D1%8C&page=1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(t, s, tc.username, http.MethodGet, tc.target, "", nil)
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			assert.Equal(t, tc.wantLocation, w.Header().Get("Location"))
+		})
+	}
+}
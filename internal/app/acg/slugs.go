@@ -0,0 +1,62 @@
+package acg
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
+// Lookups of slugs for slug.Generator.Unique, only live documents take slugs
+
+// found converts error of lookup into answer whether document exists
+func found(err error) (bool, error) {
+	switch err {
+	case nil:
+		return true, nil
+	case store.ErrNotFound:
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (s *Server) postSlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Posts().FindBySlug(ctx, slug)
+	return found(err)
+}
+
+func (s *Server) categorySlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Categories().FindBySlug(ctx, slug)
+	return found(err)
+}
+
+func (s *Server) serviceSlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Services().FindBySlug(ctx, slug)
+	return found(err)
+}
+
+func (s *Server) matCategorySlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.MatCategories().FindBySlug(ctx, slug)
+	return found(err)
+}
+
+func (s *Server) materialSlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Materials().FindBySlug(ctx, slug)
+	return found(err)
+}
+
+func (s *Server) tagSlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Tags().FindBySlug(ctx, slug)
+	return found(err)
+}
+
+func (s *Server) authorSlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Users().FindByAuthorSlug(ctx, slug)
+	return found(err)
+}
+
+// pageSlugTaken checks URL of page made from slug
+func (s *Server) pageSlugTaken(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.Pages().FindByURL(ctx, "/"+slug)
+	return found(err)
+}
@@ -0,0 +1,224 @@
+package acg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// trashPurgeInterval is period between automatic purges of expired trash
+const trashPurgeInterval = time.Hour
+
+// trashBin binds trash endpoints to repository of one content type
+// Repositories are resolved on each request, because store is configured after router
+type trashBin struct {
+	name string
+	repo func() store.ITrashRepository
+	list func(context.Context, store.ListQuery) (interface{}, error)
+}
+
+// trashBins returns trash bins of all soft deleted content types by their API routes
+func (s *Server) trashBins() map[string]trashBin {
+	return map[string]trashBin{
+		"post": {
+			name: "Post",
+			repo: func() store.ITrashRepository { return s.store.Posts() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.Posts().List(ctx, q)
+			},
+		},
+		"category": {
+			name: "Category",
+			repo: func() store.ITrashRepository { return s.store.Categories() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.Categories().List(ctx, q)
+			},
+		},
+		"material": {
+			name: "Material",
+			repo: func() store.ITrashRepository { return s.store.Materials() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.Materials().List(ctx, q)
+			},
+		},
+		"matcategory": {
+			name: "Material category",
+			repo: func() store.ITrashRepository { return s.store.MatCategories() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.MatCategories().List(ctx, q)
+			},
+		},
+		"service": {
+			name: "Service",
+			repo: func() store.ITrashRepository { return s.store.Services() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.Services().List(ctx, q)
+			},
+		},
+		"page": {
+			name: "Page",
+			repo: func() store.ITrashRepository { return s.store.Pages() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.Pages().List(ctx, q)
+			},
+		},
+		"tag": {
+			name: "Tag",
+			repo: func() store.ITrashRepository { return s.store.Tags() },
+			list: func(ctx context.Context, q store.ListQuery) (interface{}, error) {
+				return s.store.Tags().List(ctx, q)
+			},
+		},
+	}
+}
+
+// mountTrash adds trash, restore and purge endpoints of content type to r
+func (s *Server) mountTrash(r chi.Router, bin trashBin) {
+	r.Get("/trash", s.handleTrashList(bin))
+	r.Post("/restore", s.handleTrashRestore(bin))
+	r.Delete("/purge", s.handleTrashPurge(bin))
+}
+
+// handleTrashList returns deleted items, supports same query params as listings
+func (s *Server) handleTrashList(bin trashBin) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := listQuery(r)
+		if err != nil {
+			s.logger.Logf("[DEBUG] during parse list query: %v\n", err)
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		q.Deleted = store.OnlyDeleted
+
+		items, err := bin.list(r.Context(), q)
+		if err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, items)
+	}
+}
+
+// handleTrashRestore brings item back from trash
+func (s *Server) handleTrashRestore(bin trashBin) http.HandlerFunc {
+	type req struct {
+		ID primitive.ObjectID `json:"restoredID"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &req{}
+		var err error
+
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if req.ID.IsZero() {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrEmptyObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrEmptyObjectID)
+			return
+		}
+
+		err = bin.repo().Restore(r.Context(), req.ID)
+
+		switch err {
+		case nil:
+			s.respond(w, r, http.StatusOK, fmt.Sprintf("%s (%s) successfully restored", bin.name, req.ID.Hex()))
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNotInTrash)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNotInTrash)
+		case helpers.ErrPostAlreadyExist, helpers.ErrCategoryAlreadyExist, helpers.ErrMaterialAlreadyExist,
+			helpers.ErrMatCategoryAlreadyExist, helpers.ErrServiceAlreadyExist, helpers.ErrPageAlreadyExist, helpers.ErrTagAlreadyExist:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusConflict, err)
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+		}
+	}
+}
+
+// handleTrashPurge permanently removes item from trash
+func (s *Server) handleTrashPurge(bin trashBin) http.HandlerFunc {
+	type req struct {
+		ID primitive.ObjectID `json:"purgedID"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &req{}
+		var err error
+
+		if err = json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if req.ID.IsZero() {
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrEmptyObjectID)
+			s.error(w, r, http.StatusBadRequest, helpers.ErrEmptyObjectID)
+			return
+		}
+
+		err = bin.repo().Purge(r.Context(), req.ID)
+
+		switch err {
+		case nil:
+			s.respond(w, r, http.StatusOK, fmt.Sprintf("%s (%s) permanently deleted", bin.name, req.ID.Hex()))
+		case store.ErrNotFound:
+			s.logger.Logf("[ERROR] %v\n", helpers.ErrNotInTrash)
+			s.error(w, r, http.StatusNotFound, helpers.ErrNotInTrash)
+		default:
+			s.logger.Logf("[ERROR] %v\n", err)
+			s.error(w, r, http.StatusInternalServerError, err)
+		}
+	}
+}
+
+// purgeExpiredTrash permanently removes items deleted more than TrashRetentionDays ago
+func (s *Server) purgeExpiredTrash(ctx context.Context) {
+	before := time.Now().AddDate(0, 0, -s.config.TrashRetentionDays)
+
+	for _, bin := range s.trashBins() {
+		n, err := bin.repo().PurgeDeletedBefore(ctx, before)
+		if err != nil {
+			s.logger.Logf("[ERROR] During purge of expired %s trash: %v\n", bin.name, err)
+			continue
+		}
+
+		if n > 0 {
+			s.logger.Logf("[INFO] Purged %d expired items of %s trash\n", n, bin.name)
+		}
+	}
+}
+
+// runTrashPurger purges expired trash right away and then every trashPurgeInterval until ctx is done
+// Zero TrashRetentionDays keeps deleted items forever
+func (s *Server) runTrashPurger(ctx context.Context) {
+	if s.config.TrashRetentionDays <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.purgeExpiredTrash(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
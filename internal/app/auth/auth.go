@@ -61,6 +61,33 @@ func CheckToken(tokenString, secret string) (bool, error) {
 	return false, nil
 }
 
+// Username verify tokenString with given secret and return username from its claims
+func Username(tokenString, secret string) (string, error) {
+	tok, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected token signing: %v", t.Header["alg"])
+		}
+
+		return []byte(secret), nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	claims, ok := tok.Claims.(jwt.MapClaims)
+	if !ok || !tok.Valid {
+		return "", helpers.ErrUnauthorized
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return "", fmt.Errorf("Can't extract one or more claims fields")
+	}
+
+	return username, nil
+}
+
 // UpdateToken generates and  returns new token, expTime and error
 func UpdateToken(oldToken, secret string) (string, time.Time, error) {
 	oldTokParsed, err := jwt.Parse(oldToken, func(t *jwt.Token) (interface{}, error) {
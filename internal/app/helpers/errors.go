@@ -7,12 +7,18 @@ var (
 	ErrNoRequestParams = errors.New("You need to specify required query params")
 	ErrUnauthorized    = errors.New("You are not authorized yet")
 
-	ErrNoCategory    = errors.New("Category does not exist yet")
-	ErrNoMatCategory = errors.New("Material category does not exist yet")
-	ErrNoPost        = errors.New("Post does not exist yet")
-	ErrNoPage        = errors.New("Page does not exist yet")
-	ErrNoMaterial    = errors.New("Material does not exist yet")
-	ErrNoService     = errors.New("Service does not exist yet")
+	ErrNoCategory      = errors.New("Category does not exist yet")
+	ErrNoMatCategory   = errors.New("Material category does not exist yet")
+	ErrNoPost          = errors.New("Post does not exist yet")
+	ErrNoPage          = errors.New("Page does not exist yet")
+	ErrNoMaterial      = errors.New("Material does not exist yet")
+	ErrNoService       = errors.New("Service does not exist yet")
+	ErrNoTag           = errors.New("Tag does not exist yet")
+	ErrNoAuthor        = errors.New("Author does not exist yet")
+	ErrNoUser          = errors.New("User does not exist yet")
+	ErrNotInTrash      = errors.New("Item is not in trash")
+	ErrNoRevision      = errors.New("Revision does not exist")
+	ErrRevisionsDiffer = errors.New("Revisions belong to different documents")
 
 	ErrPostAlreadyExist        = errors.New("Post already exist")
 	ErrPageAlreadyExist        = errors.New("Page already exist")
@@ -22,6 +28,33 @@ var (
 	ErrServiceAlreadyExist     = errors.New("Service already exist")
 	ErrCategoryAlreadyExist    = errors.New("Category already exist")
 	ErrMatCategoryAlreadyExist = errors.New("Material category already exist")
+	ErrTagAlreadyExist         = errors.New("Tag already exist")
+	ErrAuthorAlreadyExist      = errors.New("Author already exist")
+
+	ErrCategoryNotEmpty    = errors.New("Category still has posts")
+	ErrMatCategoryNotEmpty = errors.New("Material category still has materials")
+	ErrUnknownDeletePolicy = errors.New("Delete policy must be one of restrict, reassign or cascade")
+	ErrNoReassignTarget    = errors.New("You need to specify existing category to reassign children to")
+	ErrUnknownStatus       = errors.New("Status must be one of draft, in_review, scheduled, published or unpublished")
+	ErrUnknownBlockType    = errors.New("Block type must be one of paragraph, header, image, list, checklist, quote, table, delimiter or warning")
+	ErrUnsafeURL           = errors.New("URL must be relative or use http(s) scheme")
+
+	ErrUnknownReviewAction = errors.New("Review action must be one of submit, approve, reject, unpublish or reopen")
+	ErrReviewNotAllowed    = errors.New("Review action is not allowed in current status")
+	ErrNotReviewer         = errors.New("Only reviewers may take this action")
+	ErrSelfReview          = errors.New("Document must be approved by reviewer other than editor who submitted it")
+	ErrNoReviewComment     = errors.New("You need to specify comment for this action")
+	ErrStatusReadOnly      = errors.New("Status is changed only by review actions")
+
+	ErrNoIfMatch      = errors.New("You need to specify If-Match header with version of document")
+	ErrInvalidIfMatch = errors.New("If-Match header must contain version of document from ETag")
+
+	ErrNoCache = errors.New("Store cache is disabled")
+
+	ErrNoSearchText         = errors.New("You need to specify text to search in q parameter")
+	ErrUnknownSearchType    = errors.New("Search type must be one of post, page, material or service")
+	ErrUnknownDeletedFilter = errors.New("Deleted filter must be one of any or only")
+	ErrNegativeListParam    = errors.New("Limit and skip must not be negative")
 
 	ErrInvalidObjectID = errors.New("ObjectID must be valid")
 	ErrEmptyObjectID   = errors.New("You need to specify correct ObjectID")
@@ -0,0 +1,409 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7386) and JSON Patch (RFC 6902) documents
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of supported patch formats
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupportedType is returned by Apply for unknown media type of patch
+	ErrUnsupportedType = errors.New("Patch must be " + MergePatchType + " or " + JSONPatchType)
+	// ErrTestFailed is returned when "test" operation of JSON Patch doesn't match document
+	ErrTestFailed = errors.New("Patch test operation failed")
+)
+
+// Apply applies patch of given media type to JSON document
+// Plain application/json is treated as merge patch
+func Apply(contentType string, doc, patch []byte) ([]byte, error) {
+	mediaType := MergePatchType
+	if contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, ErrUnsupportedType
+		}
+	}
+
+	switch mediaType {
+	case MergePatchType, "application/json":
+		return MergePatch(doc, patch)
+	case JSONPatchType:
+		return Patch(doc, patch)
+	}
+
+	return nil, ErrUnsupportedType
+}
+
+// MergePatch applies JSON Merge Patch to document
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+
+	if err := unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	if err := unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValue(target, p))
+}
+
+// mergeValue implements MergePatch function of RFC 7386
+func mergeValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = mergeValue(t[k], v)
+	}
+
+	return t
+}
+
+// operation is single operation of JSON Patch
+type operation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// Patch applies JSON Patch to document
+// Operations are applied in order and whole patch fails if any of them fails
+func Patch(doc, patch []byte) ([]byte, error) {
+	var (
+		root interface{}
+		ops  []operation
+	)
+
+	if err := unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		if root, err = apply(root, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+// apply returns document with applied operation
+func apply(root interface{}, op operation) (interface{}, error) {
+	if op.Path == nil {
+		return nil, errors.New("missing path")
+	}
+
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+
+		var value interface{}
+		if err = unmarshal(*op.Value, &value); err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case "add":
+			return add(root, path, value)
+		case "replace":
+			if root, _, err = remove(root, path); err != nil {
+				return nil, err
+			}
+			return add(root, path, value)
+		}
+
+		cur, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(cur, value) {
+			return nil, ErrTestFailed
+		}
+
+		return root, nil
+	case "remove":
+		root, _, err = remove(root, path)
+		return root, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, errors.New("missing from")
+		}
+
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("location can't be moved into its child")
+			}
+
+			if root, value, err = remove(root, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = get(root, from); err != nil {
+				return nil, err
+			}
+
+			value = deepCopy(value)
+		}
+
+		return add(root, path, value)
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// parsePointer splits JSON Pointer (RFC 6901) into unescaped reference tokens
+func parsePointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if s[0] != '/' {
+		return nil, fmt.Errorf("invalid pointer %q", s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// index parses array index token, "-" means position after the last element and is allowed only for add
+func index(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	max := length - 1
+	if appending {
+		max = length
+	}
+
+	if i > max {
+		return 0, fmt.Errorf("array index %d is out of range", i)
+	}
+
+	return i, nil
+}
+
+// get returns value referenced by path
+func get(root interface{}, path []string) (interface{}, error) {
+	cur := root
+
+	for _, token := range path {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			cur = v
+		case []interface{}:
+			i, err := index(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("can't reference %q in scalar value", token)
+		}
+	}
+
+	return cur, nil
+}
+
+// add returns root with value added at path
+func add(root interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return root, nil
+	case []interface{}:
+		i, err := index(last, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+
+		return replaceAt(root, path[:len(path)-1], node)
+	}
+
+	return nil, fmt.Errorf("can't add %q to scalar value", last)
+}
+
+// remove returns root without value at path and the removed value
+func remove(root interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, root, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q does not exist", last)
+		}
+
+		delete(node, last)
+		return root, v, nil
+	case []interface{}:
+		i, err := index(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+
+		root, err = replaceAt(root, path[:len(path)-1], node)
+		return root, v, err
+	}
+
+	return nil, nil, fmt.Errorf("can't remove %q from scalar value", last)
+}
+
+// replaceAt puts value at path, used after array was reallocated
+func replaceAt(root interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		i, err := index(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = value
+	}
+
+	return root, nil
+}
+
+// deepCopy copies decoded JSON value, so copied value can be changed independently
+func deepCopy(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(val))
+		for i, item := range val {
+			a[i] = deepCopy(item)
+		}
+		return a
+	}
+
+	return v
+}
+
+// unmarshal decodes JSON keeping numbers as is, so big integers are not rounded
+func unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
@@ -0,0 +1,141 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const doc = `{"title":"Запись","tags":["a","b"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7}`
+
+func TestMergePatch(t *testing.T) {
+	testCases := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "Replace field",
+			patch: `{"title":"Новая запись"}`,
+			want:  `{"title":"Новая запись","tags":["a","b"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7}`,
+		},
+		{
+			name:  "Remove field by null",
+			patch: `{"img":null}`,
+			want:  `{"title":"Запись","tags":["a","b"],"version":7}`,
+		},
+		{
+			name:  "Merge nested object",
+			patch: `{"img":{"alt":null,"url":"/b.jpg"}}`,
+			want:  `{"title":"Запись","tags":["a","b"],"img":{"url":"/b.jpg"},"version":7}`,
+		},
+		{
+			name:  "Arrays are replaced",
+			patch: `{"tags":["c"]}`,
+			want:  `{"title":"Запись","tags":["c"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7}`,
+		},
+		{
+			name:  "Empty patch",
+			patch: `{}`,
+			want:  doc,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(doc), []byte(tc.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Replace field",
+			patch: `[{"op":"replace","path":"/title","value":"Новая запись"}]`,
+			want:  `{"title":"Новая запись","tags":["a","b"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7}`,
+		},
+		{
+			name:  "Add to array",
+			patch: `[{"op":"add","path":"/tags/1","value":"x"},{"op":"add","path":"/tags/-","value":"z"}]`,
+			want:  `{"title":"Запись","tags":["a","x","b","z"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7}`,
+		},
+		{
+			name:  "Remove from array and object",
+			patch: `[{"op":"remove","path":"/tags/0"},{"op":"remove","path":"/img/alt"}]`,
+			want:  `{"title":"Запись","tags":["b"],"img":{"url":"/a.jpg"},"version":7}`,
+		},
+		{
+			name:  "Move and copy",
+			patch: `[{"op":"copy","from":"/img/url","path":"/cover"},{"op":"move","from":"/title","path":"/img/alt"}]`,
+			want:  `{"tags":["a","b"],"img":{"url":"/a.jpg","alt":"Запись"},"cover":"/a.jpg","version":7}`,
+		},
+		{
+			name:  "Escaped pointer",
+			patch: `[{"op":"add","path":"/a~1b~0c","value":1}]`,
+			want:  `{"title":"Запись","tags":["a","b"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7,"a/b~c":1}`,
+		},
+		{
+			name:  "Passed test",
+			patch: `[{"op":"test","path":"/version","value":7},{"op":"replace","path":"/title","value":"Новая запись"}]`,
+			want:  `{"title":"Новая запись","tags":["a","b"],"img":{"url":"/a.jpg","alt":"Фото"},"version":7}`,
+		},
+		{
+			name:    "Failed test",
+			patch:   `[{"op":"test","path":"/version","value":6}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Replace missing field",
+			patch:   `[{"op":"replace","path":"/missing","value":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Index out of range",
+			patch:   `[{"op":"add","path":"/tags/3","value":"x"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Move into child",
+			patch:   `[{"op":"move","from":"/img","path":"/img/inner"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown operation",
+			patch:   `[{"op":"merge","path":"/title","value":"x"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Patch([]byte(doc), []byte(tc.patch))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	_, err := Apply("application/xml", []byte(doc), []byte(`{}`))
+	assert.Equal(t, ErrUnsupportedType, err)
+
+	_, err = Apply(JSONPatchType, []byte(doc), []byte(`[{"op":"test","path":"/title","value":"Другая"}]`))
+	assert.ErrorIs(t, err, ErrTestFailed)
+
+	got, err := Apply(MergePatchType+"; charset=utf-8", []byte(doc), []byte(`{"version":null}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Запись","tags":["a","b"],"img":{"url":"/a.jpg","alt":"Фото"}}`, string(got))
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// Author is public profile of user who writes posts
+// Slug is generated from name when profile is saved first time and kept afterwards, so links to author don't break
+type Author struct {
+	Slug     string `bson:"slug,omitempty" json:"slug,omitempty"`
+	Name     string `bson:"name,omitempty" json:"name,omitempty"`
+	Position string `bson:"position,omitempty" json:"position,omitempty"`
+	Photo    string `bson:"photo,omitempty" json:"photo,omitempty"`
+	Bio      string `bson:"bio,omitempty" json:"bio,omitempty"`
+}
+
+// URL returns format url with format: "/author/author_slug"
+func (a Author) URL() string {
+	return "/author/" + a.Slug
+}
+
+// Validate check struct fields for correctness
+// Position holds credentials shown under name, e.g. Аттестованный аудитор
+func (a Author) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Slug, validation.Required),
+		validation.Field(&a.Name, validation.Required, validation.RuneLength(2, 100)),
+		validation.Field(&a.Position, validation.RuneLength(0, 255)),
+		validation.Field(&a.Photo, is.RequestURI),
+		validation.Field(&a.Bio, validation.RuneLength(0, 2000)),
+	)
+}
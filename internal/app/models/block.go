@@ -1,17 +1,104 @@
 package models
 
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+	"github.com/the-NZA/acg-nikolaev/internal/app/typograph"
+)
+
 // Block is a struct that represents blocks saved in json format
 // Specific block types: text, image, etc
 type Block struct {
-	Type string     `bson:"type" json:"type"`
-	Data *BlockData `bson:"data" json:"data"`
+	Type  string      `bson:"type" json:"type"`
+	Data  *BlockData  `bson:"data" json:"data"`
+	Tunes *BlockTunes `bson:"tunes,omitempty" json:"tunes,omitempty"`
+}
+
+// BlockTunes are settings of block saved by Editor.js block tunes
+type BlockTunes struct {
+	NoTypograph bool `bson:"noTypograph,omitempty" json:"noTypograph,omitempty"` // Text of block goes out as typed
 }
 
 // BlockData represents structure of blocks data field
+// Fields are shared by Editor.js tools, each block type uses only some of them
 type BlockData struct {
-	Text  string    `bson:"text,omitempty" json:"text,omitempty"`
-	Level int8      `bson:"level,omitempty" json:"level,omitempty"`
-	File  *FileInfo `bson:"file,omitempty" json:"file,omitempty"`
+	Text           string     `bson:"text,omitempty" json:"text,omitempty"`
+	Level          int8       `bson:"level,omitempty" json:"level,omitempty"`
+	Anchor         string     `bson:"anchor,omitempty" json:"anchor,omitempty"` // Header anchor unique in content
+	File           *FileInfo  `bson:"file,omitempty" json:"file,omitempty"`
+	Caption        string     `bson:"caption,omitempty" json:"caption,omitempty"`               // Image caption or quote author
+	WithBorder     bool       `bson:"withBorder,omitempty" json:"withBorder,omitempty"`         // Image flag
+	WithBackground bool       `bson:"withBackground,omitempty" json:"withBackground,omitempty"` // Image flag
+	Stretched      bool       `bson:"stretched,omitempty" json:"stretched,omitempty"`           // Image flag
+	Alignment      string     `bson:"alignment,omitempty" json:"alignment,omitempty"`           // Quote alignment: left or center
+	Style          string     `bson:"style,omitempty" json:"style,omitempty"`                   // List style: ordered or unordered
+	Items          []ListItem `bson:"items,omitempty" json:"items,omitempty"`                   // Items of list or checklist
+	WithHeadings   bool       `bson:"withHeadings,omitempty" json:"withHeadings,omitempty"`     // First row of table is heading
+	Content        [][]string `bson:"content,omitempty" json:"content,omitempty"`               // Rows of table cells
+	Title          string     `bson:"title,omitempty" json:"title,omitempty"`                   // Warning title
+	Message        string     `bson:"message,omitempty" json:"message,omitempty"`               // Warning message
+}
+
+// blockRules validate data of allowed block types by their required fields, block of type missing here is rejected
+// New block type needs its rules here and its renderer in render package
+var blockRules = map[string]func(d *BlockData) error{
+	"paragraph": func(d *BlockData) error {
+		return validation.ValidateStruct(d, validation.Field(&d.Text, validation.Required))
+	},
+	"header": func(d *BlockData) error {
+		return validation.ValidateStruct(d,
+			validation.Field(&d.Text, validation.Required),
+			validation.Field(&d.Level, validation.Required, validation.Min(1), validation.Max(6)),
+			validation.Field(&d.Anchor, validation.Match(anchor)),
+		)
+	},
+	"image": func(d *BlockData) error {
+		return validation.ValidateStruct(d, validation.Field(&d.File, validation.NotNil))
+	},
+	"list": func(d *BlockData) error {
+		return validation.ValidateStruct(d,
+			validation.Field(&d.Style, validation.In("ordered", "unordered")),
+			validation.Field(&d.Items, validation.Required),
+		)
+	},
+	"checklist": func(d *BlockData) error {
+		return validation.ValidateStruct(d, validation.Field(&d.Items, validation.Required))
+	},
+	"quote": func(d *BlockData) error {
+		return validation.ValidateStruct(d,
+			validation.Field(&d.Text, validation.Required),
+			validation.Field(&d.Alignment, validation.In("left", "center")),
+		)
+	},
+	"table": func(d *BlockData) error {
+		return validation.ValidateStruct(d, validation.Field(&d.Content, validation.Required))
+	},
+	"delimiter": func(d *BlockData) error {
+		return nil
+	},
+	"warning": func(d *BlockData) error {
+		return validation.ValidateStruct(d, validation.Field(&d.Message, validation.Required))
+	},
+}
+
+// Validate checks block by rules of its type
+// Errors of PageData are keyed by index of block, so editor can point at broken one
+func (b Block) Validate() error {
+	rules, ok := blockRules[b.Type]
+
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Type, validation.Required, validation.When(!ok, validation.By(func(interface{}) error {
+			return helpers.ErrUnknownBlockType
+		}))),
+		validation.Field(&b.Data, validation.NotNil, validation.When(ok && b.Data != nil, validation.By(func(interface{}) error {
+			return rules(b.Data)
+		}))),
+	)
 }
 
 // FileInfo represents basic file structure for editors "file" field
@@ -20,3 +107,172 @@ type FileInfo struct {
 	Width  int32  `bson:"width,omitempty" json:"width,omitempty"`
 	Height int32  `bson:"height,omitempty" json:"height,omitempty"`
 }
+
+// Validate checks that file has safe URL, only relative and http(s) URLs are allowed
+func (f FileInfo) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.URL, validation.Required, validation.By(checkFileURL)),
+		validation.Field(&f.Width, validation.Min(0)),
+		validation.Field(&f.Height, validation.Min(0)),
+	)
+}
+
+func checkFileURL(value interface{}) error {
+	u, err := url.Parse(strings.TrimSpace(value.(string)))
+	if err != nil {
+		return helpers.ErrUnsafeURL
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return nil
+	}
+
+	return helpers.ErrUnsafeURL
+}
+
+// ListItem is item of list or checklist, nested list keeps its items in Items
+type ListItem struct {
+	Text    string     `bson:"text,omitempty" json:"text,omitempty"`
+	Checked bool       `bson:"checked,omitempty" json:"checked,omitempty"`
+	Items   []ListItem `bson:"items,omitempty" json:"items,omitempty"`
+}
+
+// Validate checks that item and its nested items have text
+func (i ListItem) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Text, validation.Required),
+		validation.Field(&i.Items),
+	)
+}
+
+// UnmarshalJSON accepts item in every format of Editor.js tools:
+// string of list, {text, checked} of checklist and {content, items} of nested list
+func (i *ListItem) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), `"`) {
+		*i = ListItem{}
+		return json.Unmarshal(data, &i.Text)
+	}
+
+	var item struct {
+		Text    string     `json:"text"`
+		Content string     `json:"content"`
+		Checked bool       `json:"checked"`
+		Items   []ListItem `json:"items"`
+	}
+
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+
+	*i = ListItem{Text: item.Text, Checked: item.Checked, Items: item.Items}
+	if i.Text == "" {
+		i.Text = item.Content
+	}
+
+	return nil
+}
+
+// SanitizeBlocks returns blocks with inline markup cleaned by policy, given blocks are not changed
+func SanitizeBlocks(blocks []Block, policy *sanitize.Policy) []Block {
+	if blocks == nil {
+		return nil
+	}
+
+	result := make([]Block, len(blocks))
+	for i, block := range blocks {
+		result[i] = block.Sanitized(policy)
+	}
+
+	return result
+}
+
+// Sanitized returns block with inline markup of every text field cleaned by policy
+// Block without data is returned as is, data of block is copied, not changed
+func (b Block) Sanitized(policy *sanitize.Policy) Block {
+	if b.Data == nil {
+		return b
+	}
+
+	data := *b.Data
+	data.Text = policy.HTML(data.Text)
+	data.Caption = policy.HTML(data.Caption)
+	data.Title = policy.HTML(data.Title)
+	data.Message = policy.HTML(data.Message)
+	data.Items = sanitizeItems(data.Items, policy)
+
+	if data.Content != nil {
+		data.Content = make([][]string, len(b.Data.Content))
+		for i, row := range b.Data.Content {
+			data.Content[i] = make([]string, len(row))
+			for j, cell := range row {
+				data.Content[i][j] = policy.HTML(cell)
+			}
+		}
+	}
+
+	b.Data = &data
+	return b
+}
+
+func sanitizeItems(items []ListItem, policy *sanitize.Policy) []ListItem {
+	if items == nil {
+		return nil
+	}
+
+	result := make([]ListItem, len(items))
+	for i, item := range items {
+		result[i] = ListItem{Text: policy.HTML(item.Text), Checked: item.Checked, Items: sanitizeItems(item.Items, policy)}
+	}
+
+	return result
+}
+
+// TypographBlocks returns blocks with typography applied by Typographed, given blocks are not changed
+func TypographBlocks(blocks []Block) []Block {
+	if blocks == nil {
+		return nil
+	}
+
+	result := make([]Block, len(blocks))
+	for i, block := range blocks {
+		result[i] = block.Typographed()
+	}
+
+	return result
+}
+
+// Typographed returns block with typography applied to text of paragraph, header, list and checklist
+// Blocks of other types and blocks with noTypograph tune are returned as is, data of block is copied, not changed
+func (b Block) Typographed() Block {
+	if b.Data == nil || b.Tunes != nil && b.Tunes.NoTypograph {
+		return b
+	}
+
+	data := *b.Data
+
+	switch b.Type {
+	case "paragraph", "header":
+		data.Text = typograph.HTML(data.Text)
+	case "list", "checklist":
+		data.Items = typographItems(data.Items)
+	default:
+		return b
+	}
+
+	b.Data = &data
+	return b
+}
+
+func typographItems(items []ListItem) []ListItem {
+	if items == nil {
+		return nil
+	}
+
+	result := make([]ListItem, len(items))
+	for i, item := range items {
+		result[i] = ListItem{Text: typograph.HTML(item.Text), Checked: item.Checked, Items: typographItems(item.Items)}
+	}
+
+	return result
+}
@@ -0,0 +1,220 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+)
+
+func TestListItem_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+		want []ListItem
+	}{
+		{
+			name: "Items of list",
+			data: `["Паспорт", "ИНН"]`,
+			want: []ListItem{{Text: "Паспорт"}, {Text: "ИНН"}},
+		},
+		{
+			name: "Items of checklist",
+			data: `[{"text": "Договор подписан", "checked": true}]`,
+			want: []ListItem{{Text: "Договор подписан", Checked: true}},
+		},
+		{
+			name: "Items of nested list",
+			data: `[{"content": "Документы", "items": [{"content": "Выписки", "items": []}]}]`,
+			want: []ListItem{{Text: "Документы", Items: []ListItem{{Text: "Выписки", Items: []ListItem{}}}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var items []ListItem
+
+			assert.NoError(t, json.Unmarshal([]byte(tc.data), &items))
+			assert.Equal(t, tc.want, items)
+		})
+	}
+}
+
+func TestSanitizeBlocks(t *testing.T) {
+	given := []Block{
+		{Type: "paragraph", Data: &BlockData{Text: `<b onclick="alert(1)">Текст</b><script>alert(1)</script><a href="https://acg-nikolaev.ru/posts">Записи</a>`}},
+		{Type: "image", Data: &BlockData{File: &FileInfo{URL: "/uploads/report.jpg"}, Caption: `<img src=x onerror="alert(1)">Отчет`}},
+		{Type: "list", Data: &BlockData{Items: []ListItem{{Text: "<i>Паспорт", Items: []ListItem{{Text: `<a href="javascript:alert(1)">ИНН</a>`}}}}}},
+		{Type: "table", Data: &BlockData{Content: [][]string{{"<u>Налог</u>", "<div>НДС</div>"}}}},
+		{Type: "warning", Data: &BlockData{Title: "<h1>Внимание</h1>", Message: "<em>Срок</em>"}},
+		{Type: "delimiter"},
+	}
+
+	blocks := SanitizeBlocks(given, sanitize.New("acg-nikolaev.ru"))
+
+	assert.Equal(t, `<b>Текст</b><a href="https://acg-nikolaev.ru/posts">Записи</a>`, blocks[0].Data.Text)
+	assert.Equal(t, "Отчет", blocks[1].Data.Caption)
+	assert.Equal(t, "/uploads/report.jpg", blocks[1].Data.File.URL)
+	assert.Equal(t, []ListItem{{Text: "<i>Паспорт</i>", Items: []ListItem{{Text: "<a>ИНН</a>"}}}}, blocks[2].Data.Items)
+	assert.Equal(t, [][]string{{"<u>Налог</u>", "НДС"}}, blocks[3].Data.Content)
+	assert.Equal(t, "Внимание", blocks[4].Data.Title)
+	assert.Equal(t, "<em>Срок</em>", blocks[4].Data.Message)
+	assert.Nil(t, blocks[5].Data)
+
+	// Given blocks must not be changed
+	assert.Equal(t, "<i>Паспорт", given[2].Data.Items[0].Text)
+	assert.Equal(t, "<div>НДС</div>", given[3].Data.Content[0][1])
+}
+
+func TestBlock_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		block   Block
+		wantErr bool
+	}{
+		{
+			name:  "Paragraph",
+			block: Block{Type: "paragraph", Data: &BlockData{Text: "Текст"}},
+		},
+		{
+			name:    "Paragraph without text",
+			block:   Block{Type: "paragraph", Data: &BlockData{}},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown type",
+			block:   Block{Type: "video", Data: &BlockData{Text: "Видео"}},
+			wantErr: true,
+		},
+		{
+			name:    "Without data",
+			block:   Block{Type: "delimiter"},
+			wantErr: true,
+		},
+		{
+			name:  "Delimiter",
+			block: Block{Type: "delimiter", Data: &BlockData{}},
+		},
+		{
+			name:  "Header",
+			block: Block{Type: "header", Data: &BlockData{Text: "Заголовок", Level: 3}},
+		},
+		{
+			name:    "Header of level 9",
+			block:   Block{Type: "header", Data: &BlockData{Text: "Заголовок", Level: 9}},
+			wantErr: true,
+		},
+		{
+			name:  "Image",
+			block: Block{Type: "image", Data: &BlockData{File: &FileInfo{URL: "/uploads/report.jpg", Width: 800, Height: 450}}},
+		},
+		{
+			name:    "Image without file",
+			block:   Block{Type: "image", Data: &BlockData{Caption: "Отчет"}},
+			wantErr: true,
+		},
+		{
+			name:    "Image with unsafe URL",
+			block:   Block{Type: "image", Data: &BlockData{File: &FileInfo{URL: "javascript:alert(1)"}}},
+			wantErr: true,
+		},
+		{
+			name:  "List",
+			block: Block{Type: "list", Data: &BlockData{Style: "ordered", Items: []ListItem{{Text: "Паспорт"}}}},
+		},
+		{
+			name:    "List of unknown style",
+			block:   Block{Type: "list", Data: &BlockData{Style: "dotted", Items: []ListItem{{Text: "Паспорт"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "List with empty nested item",
+			block:   Block{Type: "list", Data: &BlockData{Items: []ListItem{{Text: "Документы", Items: []ListItem{{}}}}}},
+			wantErr: true,
+		},
+		{
+			name:    "Checklist without items",
+			block:   Block{Type: "checklist", Data: &BlockData{}},
+			wantErr: true,
+		},
+		{
+			name:    "Quote of unknown alignment",
+			block:   Block{Type: "quote", Data: &BlockData{Text: "Цитата", Alignment: "right"}},
+			wantErr: true,
+		},
+		{
+			name:  "Table",
+			block: Block{Type: "table", Data: &BlockData{Content: [][]string{{"Налог", "Ставка"}}}},
+		},
+		{
+			name:    "Warning without message",
+			block:   Block{Type: "warning", Data: &BlockData{Title: "Внимание"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.wantErr {
+				assert.Error(t, tc.block.Validate())
+			} else {
+				assert.NoError(t, tc.block.Validate())
+			}
+		})
+	}
+}
+
+func TestPage_Validate_BlockIndex(t *testing.T) {
+	page := Page{PageData: []Block{
+		{Type: "paragraph", Data: &BlockData{Text: "Текст"}},
+		{Type: "header", Data: &BlockData{Text: "Заголовок", Level: 9}},
+	}}
+
+	errs, ok := page.Validate().(validation.Errors)
+	assert.True(t, ok)
+
+	blocks, ok := errs["pagedata"].(validation.Errors)
+	assert.True(t, ok)
+	assert.Contains(t, blocks, "1")
+	assert.NotContains(t, blocks, "0")
+}
+
+func TestBlock_Typographed(t *testing.T) {
+	testCases := []struct {
+		name  string
+		block Block
+		want  *BlockData
+	}{
+		{
+			name:  "Paragraph",
+			block: Block{Type: "paragraph", Data: &BlockData{Text: `<b>Налог</b> - "платеж"`}},
+			want:  &BlockData{Text: "<b>Налог</b>\u00a0— «платеж»"},
+		},
+		{
+			name:  "Nested list",
+			block: Block{Type: "list", Data: &BlockData{Items: []ListItem{{Text: "Отчет в ФНС", Items: []ListItem{{Text: "Срок - 25 число"}}}}}},
+			want:  &BlockData{Items: []ListItem{{Text: "Отчет в\u00a0ФНС", Items: []ListItem{{Text: "Срок\u00a0— 25 число"}}}}},
+		},
+		{
+			name:  "Block with noTypograph tune",
+			block: Block{Type: "paragraph", Data: &BlockData{Text: `Налог - "платеж"`}, Tunes: &BlockTunes{NoTypograph: true}},
+			want:  &BlockData{Text: `Налог - "платеж"`},
+		},
+		{
+			name:  "Other type",
+			block: Block{Type: "quote", Data: &BlockData{Text: `Налог - "платеж"`}},
+			want:  &BlockData{Text: `Налог - "платеж"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := *tc.block.Data
+
+			assert.Equal(t, tc.want, tc.block.Typographed().Data)
+			assert.Equal(t, before, *tc.block.Data, "data of block must not be changed")
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,12 +10,15 @@ import (
 
 // Category represents structure for each post category
 type Category struct {
-	ID       primitive.ObjectID `bson:"_id" json:"_id"`
-	Title    string             `bson:"title,omitempty" json:"title,omitempty"`
-	Subtitle string             `bson:"subtitle,omitempty" json:"subtitle,omitempty"`
-	Slug     string             `bson:"slug,omitempty" json:"slug,omitempty"`
-	MetaDesc string             `bson:"metadesc,omitempty" json:"metadesc,omitempty"`
-	Deleted  bool               `bson:"deleted" json:"-"`
+	ID        primitive.ObjectID `bson:"_id" json:"_id"`
+	Title     string             `bson:"title,omitempty" json:"title,omitempty"`
+	Subtitle  string             `bson:"subtitle,omitempty" json:"subtitle,omitempty"`
+	Slug      string             `bson:"slug,omitempty" json:"slug,omitempty"`
+	MetaDesc  string             `bson:"metadesc,omitempty" json:"metadesc,omitempty"`
+	Deleted   bool               `bson:"deleted" json:"-"`
+	DeletedAt time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version   int64              `bson:"version" json:"version"`
 }
 
 // URL returns format url with format: "/category/category_slug"
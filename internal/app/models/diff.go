@@ -0,0 +1,89 @@
+package models
+
+import "reflect"
+
+// Operations of block diff
+const (
+	DiffEqual   = "equal"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// BlockChange describes one step of transformation of old blocks into new ones
+// Index is -1 when block is absent on that side
+type BlockChange struct {
+	Op       string `json:"op"`
+	OldIndex int    `json:"old_index"`
+	NewIndex int    `json:"new_index"`
+	Old      *Block `json:"old,omitempty"`
+	New      *Block `json:"new,omitempty"`
+}
+
+// DiffBlocks returns block-level diff between from and to content
+// Blocks are matched by longest common subsequence, removed block followed by added block
+// of the same type is reported as changed
+func DiffBlocks(from, to []Block) []BlockChange {
+	// lcs[i][j] is length of common subsequence of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			switch {
+			case reflect.DeepEqual(from[i], to[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	changes := make([]BlockChange, 0, len(from)+len(to))
+	i, j := 0, 0
+
+	// removed and added collect blocks between two equal ones
+	var removed, added []int
+
+	flush := func() {
+		n := 0
+		for n < len(removed) && n < len(added) && from[removed[n]].Type == to[added[n]].Type {
+			changes = append(changes, BlockChange{Op: DiffChanged, OldIndex: removed[n], NewIndex: added[n], Old: &from[removed[n]], New: &to[added[n]]})
+			n++
+		}
+
+		for _, r := range removed[n:] {
+			changes = append(changes, BlockChange{Op: DiffRemoved, OldIndex: r, NewIndex: -1, Old: &from[r]})
+		}
+
+		for _, a := range added[n:] {
+			changes = append(changes, BlockChange{Op: DiffAdded, OldIndex: -1, NewIndex: a, New: &to[a]})
+		}
+
+		removed, added = removed[:0], added[:0]
+	}
+
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && reflect.DeepEqual(from[i], to[j]):
+			flush()
+			changes = append(changes, BlockChange{Op: DiffEqual, OldIndex: i, NewIndex: j, New: &to[j]})
+			i++
+			j++
+		case j == len(to) || (i < len(from) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+
+	flush()
+
+	return changes
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func paragraph(text string) Block {
+	return Block{Type: "paragraph", Data: &BlockData{Text: text}}
+}
+
+func header(text string) Block {
+	return Block{Type: "header", Data: &BlockData{Text: text, Level: 2}}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    []Block
+		to      []Block
+		wantOps []string
+	}{
+		{
+			name:    "Same content",
+			from:    []Block{header("Заголовок"), paragraph("Текст")},
+			to:      []Block{header("Заголовок"), paragraph("Текст")},
+			wantOps: []string{DiffEqual, DiffEqual},
+		},
+		{
+			name:    "Added block",
+			from:    []Block{paragraph("Первый")},
+			to:      []Block{paragraph("Первый"), paragraph("Второй")},
+			wantOps: []string{DiffEqual, DiffAdded},
+		},
+		{
+			name:    "Removed block",
+			from:    []Block{header("Заголовок"), paragraph("Первый")},
+			to:      []Block{paragraph("Первый")},
+			wantOps: []string{DiffRemoved, DiffEqual},
+		},
+		{
+			name:    "Changed block",
+			from:    []Block{header("Заголовок"), paragraph("Старый"), paragraph("Конец")},
+			to:      []Block{header("Заголовок"), paragraph("Новый"), paragraph("Конец")},
+			wantOps: []string{DiffEqual, DiffChanged, DiffEqual},
+		},
+		{
+			name:    "Replaced by block of other type",
+			from:    []Block{paragraph("Текст")},
+			to:      []Block{header("Текст")},
+			wantOps: []string{DiffRemoved, DiffAdded},
+		},
+		{
+			name:    "Empty revisions",
+			from:    nil,
+			to:      nil,
+			wantOps: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changes := DiffBlocks(tc.from, tc.to)
+
+			ops := make([]string, 0, len(changes))
+			for _, c := range changes {
+				ops = append(ops, c.Op)
+			}
+
+			assert.Equal(t, tc.wantOps, ops)
+		})
+	}
+}
+
+func TestDiffBlocks_Indexes(t *testing.T) {
+	changes := DiffBlocks(
+		[]Block{header("Заголовок"), paragraph("Старый")},
+		[]Block{paragraph("Вступление"), header("Заголовок"), paragraph("Новый")},
+	)
+
+	if assert.Len(t, changes, 3) {
+		assert.Equal(t, BlockChange{Op: DiffAdded, OldIndex: -1, NewIndex: 0, New: &Block{Type: "paragraph", Data: &BlockData{Text: "Вступление"}}}, changes[0])
+		assert.Equal(t, 0, changes[1].OldIndex)
+		assert.Equal(t, 1, changes[1].NewIndex)
+		assert.Equal(t, DiffChanged, changes[2].Op)
+		assert.Equal(t, "Старый", changes[2].Old.Data.Text)
+		assert.Equal(t, "Новый", changes[2].New.Data.Text)
+	}
+}
@@ -10,14 +10,18 @@ import (
 
 // Material represent structure of each material
 type Material struct {
-	ID            primitive.ObjectID `bson:"_id" json:"_id"`
-	Title         string             `bson:"title,omitempty" json:"title,omitempty"`
-	MatCategoryID primitive.ObjectID `bson:"matcategory_id,omitempty" json:"matcategory_id,omitempty"`
-	Slug          string             `bson:"slug,omitempty" json:"slug,omitempty"`
-	Desc          string             `bson:"desc,omitempty" json:"desc,omitempty"`
-	Time          time.Time          `bson:"time,omitempty" json:"time,omitempty"`
-	FileLink      string             `bson:"filelink,omitempty" json:"filelink,omitempty"`
-	Deleted       bool               `bson:"deleted" json:"-"`
+	ID            primitive.ObjectID   `bson:"_id" json:"_id"`
+	Title         string               `bson:"title,omitempty" json:"title,omitempty"`
+	MatCategoryID primitive.ObjectID   `bson:"matcategory_id,omitempty" json:"matcategory_id,omitempty"`
+	Slug          string               `bson:"slug,omitempty" json:"slug,omitempty"`
+	Desc          string               `bson:"desc,omitempty" json:"desc,omitempty"`
+	Time          time.Time            `bson:"time,omitempty" json:"time,omitempty"`
+	FileLink      string               `bson:"filelink,omitempty" json:"filelink,omitempty"`
+	TagIDs        []primitive.ObjectID `bson:"tag_ids,omitempty" json:"tag_ids,omitempty"`
+	Deleted       bool                 `bson:"deleted" json:"-"`
+	DeletedAt     time.Time            `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy     string               `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version       int64                `bson:"version" json:"version"`
 }
 
 // MaterialShow represents material category with slice of materials for redreding in the browser
@@ -45,5 +49,6 @@ func (m Material) Validate() error {
 		validation.Field(&m.Time, validation.Required),
 		validation.Field(&m.FileLink, validation.Required),
 		validation.Field(&m.Slug, validation.Required),
+		validation.Field(&m.TagIDs, validation.Each(validation.By(helpers.CheckObjectID))),
 	)
 }
@@ -1,19 +1,44 @@
 package models
 
 import (
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+	"github.com/the-NZA/acg-nikolaev/internal/app/typograph"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Page is basic model for each page
 type Page struct {
-	ID       primitive.ObjectID `bson:"_id" json:"_id"`
-	Title    string             `bson:"title,omitempty" json:"title,omitempty"`
-	Subtitle string             `bson:"subtitle,omitempty" json:"subtitle,omitempty"`
-	MetaDesc string             `bson:"desc,omitempty" json:"desc,omitempty"`
-	URL      string             `bson:"url,omitempty" json:"url,omitempty"`
-	PageData []Block            `bson:"pagedata,omitempty" json:"pagedata,omitempty"`
+	ID        primitive.ObjectID `bson:"_id" json:"_id"`
+	Title     string             `bson:"title,omitempty" json:"title,omitempty"`
+	Subtitle  string             `bson:"subtitle,omitempty" json:"subtitle,omitempty"`
+	MetaDesc  string             `bson:"desc,omitempty" json:"desc,omitempty"`
+	URL       string             `bson:"url,omitempty" json:"url,omitempty"`
+	PageData  []Block            `bson:"pagedata,omitempty" json:"pagedata,omitempty"`
+	Status    Status             `bson:"status,omitempty" json:"status,omitempty"`
+	Deleted   bool               `bson:"deleted" json:"-"`
+	DeletedAt time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version   int64              `bson:"version" json:"version"`
+}
+
+// IsPublished tells whether page is visible on site
+func (p Page) IsPublished() bool {
+	return p.Status == StatusPublished
+}
+
+// Sanitize cleans inline markup of page content by policy of site, server calls it before every save
+func (p *Page) Sanitize(policy *sanitize.Policy) {
+	p.PageData = SanitizeBlocks(p.PageData, policy)
+}
+
+// Typograph applies typography to title and content of page
+func (p *Page) Typograph() {
+	p.Title = typograph.Text(p.Title)
+	p.PageData = TypographBlocks(p.PageData)
 }
 
 // Validate page struct
@@ -25,5 +50,7 @@ func (p Page) Validate() error {
 		validation.Field(&p.MetaDesc, validation.Required, validation.RuneLength(50, 255)),
 		validation.Field(&p.URL, validation.Required, validation.When(p.URL != "/", validation.RuneLength(5, 255)).Else(validation.Skip)),
 		validation.Field(&p.PageData, validation.NilOrNotEmpty),
+		// Pages are not scheduled
+		validation.Field(&p.Status, validation.Required, validation.In(StatusDraft, StatusInReview, StatusPublished, StatusUnpublished)),
 	)
 }
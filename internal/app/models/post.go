@@ -5,22 +5,35 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+	"github.com/the-NZA/acg-nikolaev/internal/app/typograph"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Post is a structure for each post
 type Post struct {
-	ID           primitive.ObjectID `bson:"_id" json:"_id"`
-	Title        string             `bson:"title,omitempty" json:"title,omitempty"`
-	Snippet      string             `bson:"snippet,omitempty" json:"snippet,omitempty"`
-	Slug         string             `bson:"slug,omitempty" json:"slug,omitempty"`
-	CategoryID   primitive.ObjectID `bson:"category_id,omitempty" json:"category_id,omitempty"`
-	CategorySlug string             `bson:"category_slug"` // Not empty only during aggregation on posts collection
-	Time         time.Time          `bson:"time,omitempty" json:"time,omitempty"`
-	MetaDesc     string             `bson:"metadesc,omitempty" json:"metadesc,omitempty"`
-	PostImg      string             `bson:"postimg,omitempty" json:"postimg,omitempty"`
-	PageData     []Block            `bson:"pagedata,omitempty" json:"pagedata,omitempty"`
-	Deleted      bool               `bson:"deleted" json:"-"`
+	ID            primitive.ObjectID   `bson:"_id" json:"_id"`
+	Title         string               `bson:"title,omitempty" json:"title,omitempty"`
+	Snippet       string               `bson:"snippet,omitempty" json:"snippet,omitempty"`
+	Slug          string               `bson:"slug,omitempty" json:"slug,omitempty"`
+	CategoryID    primitive.ObjectID   `bson:"category_id,omitempty" json:"category_id,omitempty"`
+	CategorySlug  string               `bson:"category_slug"`                                            // Not empty only during aggregation on posts collection
+	CategoryTitle string               `bson:"category_title,omitempty" json:"category_title,omitempty"` // Like CategorySlug filled only by aggregation
+	Time          time.Time            `bson:"time,omitempty" json:"time,omitempty"`
+	MetaDesc      string               `bson:"metadesc,omitempty" json:"metadesc,omitempty"`
+	PostImg       string               `bson:"postimg,omitempty" json:"postimg,omitempty"`
+	PageData      []Block              `bson:"pagedata,omitempty" json:"pagedata,omitempty"`
+	TagIDs        []primitive.ObjectID `bson:"tag_ids,omitempty" json:"tag_ids,omitempty"`
+	Author        string               `bson:"author,omitempty" json:"author,omitempty"` // Username of editor who created post
+	WordCount     int                  `bson:"word_count" json:"word_count"`             // Words of PageData, cached on save
+	ReadingTime   int                  `bson:"reading_time" json:"reading_time"`         // Minutes to read PageData, cached on save
+	Status        Status               `bson:"status,omitempty" json:"status,omitempty"`
+	PublishAt     time.Time            `bson:"publish_at,omitempty" json:"publish_at,omitempty"`     // Moment post becomes or became public
+	UnpublishAt   time.Time            `bson:"unpublish_at,omitempty" json:"unpublish_at,omitempty"` // Optional moment post is hidden again
+	Deleted       bool                 `bson:"deleted" json:"-"`
+	DeletedAt     time.Time            `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy     string               `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version       int64                `bson:"version" json:"version"` // Incremented by every update, update of stale version is rejected
 }
 
 // TimeString return formated time string
@@ -33,6 +46,69 @@ func (p Post) GetURL() string {
 	return "/category/" + p.CategorySlug + "/" + p.Slug
 }
 
+// IsPublished tells whether post is visible on site
+func (p Post) IsPublished() bool {
+	return p.Status == StatusPublished
+}
+
+// ApplySchedule brings status and time of post in line with its schedule at moment now
+// Empty status means published for clients unaware of statuses, publish_at of published post defaults to its time
+// Time of scheduled and published post is set to publish_at, so it is shown and sorted by moment of publication
+func (p *Post) ApplySchedule(now time.Time) {
+	if p.Status == "" {
+		p.Status = StatusPublished
+	}
+
+	if p.Status == StatusScheduled && !p.PublishAt.IsZero() && !p.PublishAt.After(now) {
+		p.Status = StatusPublished
+	}
+
+	if p.Status == StatusPublished && !p.UnpublishAt.IsZero() && !p.UnpublishAt.After(now) {
+		p.Status = StatusUnpublished
+	}
+
+	switch p.Status {
+	case StatusPublished, StatusUnpublished:
+		if p.PublishAt.IsZero() {
+			p.PublishAt = p.Time
+		}
+		fallthrough
+	case StatusScheduled:
+		if !p.PublishAt.IsZero() {
+			p.Time = p.PublishAt
+		}
+	}
+}
+
+// Approve schedules post for publication at publish_at, or right at moment now when it is not set
+// Post with due publish_at is published at once
+func (p *Post) Approve(now time.Time) {
+	if p.PublishAt.IsZero() {
+		p.PublishAt = now
+	}
+
+	p.Status = StatusScheduled
+	p.ApplySchedule(now)
+}
+
+// Sanitize cleans inline markup of post content by policy of site, server calls it before every save
+func (p *Post) Sanitize(policy *sanitize.Policy) {
+	p.PageData = SanitizeBlocks(p.PageData, policy)
+}
+
+// Typograph applies typography to title, snippet and content of post
+func (p *Post) Typograph() {
+	p.Title = typograph.Text(p.Title)
+	p.Snippet = typograph.Text(p.Snippet)
+	p.PageData = TypographBlocks(p.PageData)
+}
+
+// CountReading caches word count and reading time of post content
+func (p *Post) CountReading() {
+	p.WordCount = CountWords(p.PageData)
+	p.ReadingTime = ReadingMinutes(p.WordCount)
+}
+
 // Validate check struct fields for correctness
 func (p Post) Validate() error {
 	return validation.ValidateStruct(&p,
@@ -42,9 +118,15 @@ func (p Post) Validate() error {
 		validation.Field(&p.Slug, validation.Required, validation.RuneLength(5, 255)),
 		validation.Field(&p.CategoryID, validation.Required, validation.By(helpers.CheckObjectID)),
 		validation.Field(&p.CategorySlug, validation.Empty),
+		validation.Field(&p.CategoryTitle, validation.Empty),
 		validation.Field(&p.MetaDesc, validation.Required, validation.RuneLength(50, 255)),
 		validation.Field(&p.Time, validation.Required),
 		validation.Field(&p.PostImg, validation.Required),
 		validation.Field(&p.PageData, validation.NilOrNotEmpty),
+		validation.Field(&p.TagIDs, validation.Each(validation.By(helpers.CheckObjectID))),
+		validation.Field(&p.Status, validation.Required, validation.In(Statuses...)),
+		validation.Field(&p.PublishAt, validation.When(p.Status == StatusScheduled, validation.Required)),
+		validation.Field(&p.UnpublishAt, validation.When(!p.UnpublishAt.IsZero() && !p.PublishAt.IsZero(),
+			validation.Min(p.PublishAt).Exclusive())),
 	)
 }
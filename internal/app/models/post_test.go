@@ -0,0 +1,110 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPost_ApplySchedule(t *testing.T) {
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name          string
+		post          Post
+		wantStatus    Status
+		wantPublishAt time.Time
+	}{
+		{
+			name:          "Empty status is published at post time",
+			post:          Post{Time: created},
+			wantStatus:    StatusPublished,
+			wantPublishAt: created,
+		},
+		{
+			name:          "Draft is left as is",
+			post:          Post{Time: created, Status: StatusDraft},
+			wantStatus:    StatusDraft,
+			wantPublishAt: time.Time{},
+		},
+		{
+			name:          "Scheduled in future",
+			post:          Post{Time: created, Status: StatusScheduled, PublishAt: now.AddDate(0, 0, 1)},
+			wantStatus:    StatusScheduled,
+			wantPublishAt: now.AddDate(0, 0, 1),
+		},
+		{
+			name:          "Scheduled is published when due",
+			post:          Post{Time: created, Status: StatusScheduled, PublishAt: now},
+			wantStatus:    StatusPublished,
+			wantPublishAt: now,
+		},
+		{
+			name:          "Published is unpublished when due",
+			post:          Post{Time: created, Status: StatusPublished, PublishAt: created, UnpublishAt: now.Add(-time.Minute)},
+			wantStatus:    StatusUnpublished,
+			wantPublishAt: created,
+		},
+		{
+			name:          "Published until future",
+			post:          Post{Time: created, Status: StatusPublished, UnpublishAt: now.Add(time.Minute)},
+			wantStatus:    StatusPublished,
+			wantPublishAt: created,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.post.ApplySchedule(now)
+
+			assert.Equal(t, tc.wantStatus, tc.post.Status)
+			assert.Equal(t, tc.wantPublishAt, tc.post.PublishAt)
+
+			if !tc.wantPublishAt.IsZero() {
+				assert.Equal(t, tc.wantPublishAt, tc.post.Time)
+			}
+		})
+	}
+}
+
+func TestPost_Approve(t *testing.T) {
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		post          Post
+		wantStatus    Status
+		wantPublishAt time.Time
+	}{
+		{
+			name:          "Without publish_at is published now",
+			post:          Post{Time: now.Add(-time.Hour), Status: StatusInReview},
+			wantStatus:    StatusPublished,
+			wantPublishAt: now,
+		},
+		{
+			name:          "Due publish_at is published",
+			post:          Post{Time: now.Add(-time.Hour), Status: StatusInReview, PublishAt: now.Add(-time.Minute)},
+			wantStatus:    StatusPublished,
+			wantPublishAt: now.Add(-time.Minute),
+		},
+		{
+			name:          "Future publish_at is scheduled",
+			post:          Post{Time: now.Add(-time.Hour), Status: StatusInReview, PublishAt: now.AddDate(0, 0, 1)},
+			wantStatus:    StatusScheduled,
+			wantPublishAt: now.AddDate(0, 0, 1),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.post.Approve(now)
+
+			assert.Equal(t, tc.wantStatus, tc.post.Status)
+			assert.Equal(t, tc.wantPublishAt, tc.post.PublishAt)
+			assert.Equal(t, tc.wantPublishAt, tc.post.Time)
+		})
+	}
+}
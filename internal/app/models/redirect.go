@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Types of documents which old URLs are redirected
+const (
+	RedirectPost        = "post"
+	RedirectCategory    = "category"
+	RedirectMatCategory = "matcategory"
+	RedirectPage        = "page"
+)
+
+// Redirect records slug which document had before change of its URL
+// Slug of page is its URL, post moved to another category keeps its slug
+type Redirect struct {
+	ID      primitive.ObjectID `bson:"_id" json:"_id"`
+	DocID   primitive.ObjectID `bson:"doc_id" json:"doc_id"`
+	DocType string             `bson:"doc_type" json:"doc_type"`
+	Slug    string             `bson:"slug" json:"slug"`
+	Time    time.Time          `bson:"time" json:"time"`
+}
+
+// NewRedirect returns redirect from old slug of document
+func NewRedirect(docType string, docID primitive.ObjectID, slug string) *Redirect {
+	return &Redirect{
+		ID:      primitive.NewObjectID(),
+		DocID:   docID,
+		DocType: docType,
+		Slug:    slug,
+		Time:    time.Now(),
+	}
+}
+
+// Validate redirect struct
+func (r Redirect) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.ID, validation.Required, validation.By(helpers.CheckObjectID)),
+		validation.Field(&r.DocID, validation.Required, validation.By(helpers.CheckObjectID)),
+		validation.Field(&r.DocType, validation.Required, validation.In(RedirectPost, RedirectCategory, RedirectMatCategory, RedirectPage)),
+		validation.Field(&r.Slug, validation.Required),
+		validation.Field(&r.Time, validation.Required),
+	)
+}
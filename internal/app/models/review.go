@@ -0,0 +1,72 @@
+package models
+
+import "github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+
+// Actions which change status of post or page
+const (
+	ActionSubmit    = "submit"    // Author sends draft to review
+	ActionApprove   = "approve"   // Reviewer publishes submitted document, post with future publish_at is scheduled
+	ActionReject    = "reject"    // Reviewer returns submitted document to author with comment
+	ActionUnpublish = "unpublish" // Reviewer withdraws published or scheduled document
+	ActionReopen    = "reopen"    // Author returns withdrawn document to drafts to rework it
+	ActionSchedule  = "schedule"  // Scheduler publishes or withdraws post, it is not available in API
+	ActionEdit      = "edit"      // Editor changes published or scheduled document, it goes back to review, not available in API
+)
+
+// Transition is change of status recorded in revision
+type Transition struct {
+	Action string `bson:"action" json:"action"`
+	From   Status `bson:"from" json:"from"`
+	To     Status `bson:"to" json:"to"`
+}
+
+// Editor is user who takes review action
+type Editor struct {
+	Username string
+	Reviewer bool // Editor may approve, reject and unpublish documents
+}
+
+// reviewRule tells from which statuses action is allowed and who may take it
+type reviewRule struct {
+	from      []Status
+	to        Status
+	reviewer  bool // Only reviewers may take action
+	notAuthor bool // Editor who submitted document may not take action
+	comment   bool // Comment is required
+}
+
+var reviewRules = map[string]reviewRule{
+	ActionSubmit:    {from: []Status{StatusDraft}, to: StatusInReview},
+	ActionApprove:   {from: []Status{StatusInReview}, to: StatusPublished, reviewer: true, notAuthor: true},
+	ActionReject:    {from: []Status{StatusInReview}, to: StatusDraft, reviewer: true, comment: true},
+	ActionUnpublish: {from: []Status{StatusPublished, StatusScheduled}, to: StatusUnpublished, reviewer: true},
+	ActionReopen:    {from: []Status{StatusUnpublished}, to: StatusDraft},
+}
+
+// Review returns transition made by action of editor from status, submitter is editor who sent document to review
+// It fails when action is unknown, not allowed in status, taken not by reviewer or by submitter
+// or lacks required comment
+func Review(action string, from Status, editor Editor, submitter, comment string) (*Transition, error) {
+	rule, ok := reviewRules[action]
+	if !ok {
+		return nil, helpers.ErrUnknownReviewAction
+	}
+
+	allowed := false
+	for _, s := range rule.from {
+		allowed = allowed || s == from
+	}
+
+	switch {
+	case !allowed:
+		return nil, helpers.ErrReviewNotAllowed
+	case rule.reviewer && !editor.Reviewer:
+		return nil, helpers.ErrNotReviewer
+	case rule.notAuthor && submitter != "" && submitter == editor.Username:
+		return nil, helpers.ErrSelfReview
+	case rule.comment && comment == "":
+		return nil, helpers.ErrNoReviewComment
+	}
+
+	return &Transition{Action: action, From: from, To: rule.to}, nil
+}
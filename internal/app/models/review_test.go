@@ -0,0 +1,127 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+)
+
+func TestReview(t *testing.T) {
+	testCases := []struct {
+		name      string
+		action    string
+		from      Status
+		reviewer  bool
+		submitter string
+		comment   string
+		wantTo    Status
+		wantErr   error
+	}{
+		{
+			name:   "Author submits draft",
+			action: ActionSubmit,
+			from:   StatusDraft,
+			wantTo: StatusInReview,
+		},
+		{
+			name:    "Submit of published",
+			action:  ActionSubmit,
+			from:    StatusPublished,
+			wantErr: helpers.ErrReviewNotAllowed,
+		},
+		{
+			name:     "Reviewer approves",
+			action:   ActionApprove,
+			from:     StatusInReview,
+			reviewer: true,
+			wantTo:   StatusPublished,
+		},
+		{
+			name:      "Reviewer approves own submission",
+			action:    ActionApprove,
+			from:      StatusInReview,
+			reviewer:  true,
+			submitter: "reviewer",
+			wantErr:   helpers.ErrSelfReview,
+		},
+		{
+			name:      "Reviewer approves submission of author",
+			action:    ActionApprove,
+			from:      StatusInReview,
+			reviewer:  true,
+			submitter: "author",
+			wantTo:    StatusPublished,
+		},
+		{
+			name:      "Reviewer rejects own submission",
+			action:    ActionReject,
+			from:      StatusInReview,
+			reviewer:  true,
+			submitter: "reviewer",
+			comment:   "Not ready",
+			wantTo:    StatusDraft,
+		},
+		{
+			name:    "Author approves",
+			action:  ActionApprove,
+			from:    StatusInReview,
+			wantErr: helpers.ErrNotReviewer,
+		},
+		{
+			name:    "Approve of draft",
+			action:  ActionApprove,
+			from:    StatusDraft,
+			wantErr: helpers.ErrReviewNotAllowed,
+		},
+		{
+			name:     "Reviewer rejects with comment",
+			action:   ActionReject,
+			from:     StatusInReview,
+			reviewer: true,
+			comment:  "Fix the title",
+			wantTo:   StatusDraft,
+		},
+		{
+			name:     "Reject without comment",
+			action:   ActionReject,
+			from:     StatusInReview,
+			reviewer: true,
+			wantErr:  helpers.ErrNoReviewComment,
+		},
+		{
+			name:     "Reviewer unpublishes scheduled",
+			action:   ActionUnpublish,
+			from:     StatusScheduled,
+			reviewer: true,
+			wantTo:   StatusUnpublished,
+		},
+		{
+			name:   "Author reopens unpublished",
+			action: ActionReopen,
+			from:   StatusUnpublished,
+			wantTo: StatusDraft,
+		},
+		{
+			name:     "Schedule is not review action",
+			action:   ActionSchedule,
+			from:     StatusScheduled,
+			reviewer: true,
+			wantErr:  helpers.ErrUnknownReviewAction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transition, err := Review(tc.action, tc.from, Editor{Username: "reviewer", Reviewer: tc.reviewer}, tc.submitter, tc.comment)
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, err)
+				assert.Nil(t, transition)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, &Transition{Action: tc.action, From: tc.from, To: tc.wantTo}, transition)
+		})
+	}
+}
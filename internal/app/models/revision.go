@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Types of documents which have revisions
+const (
+	RevisionPost = "post"
+	RevisionPage = "page"
+)
+
+// Revision is immutable snapshot of post or page saved on each change
+// Exactly one of Post and Page is set depending on DocType
+type Revision struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id"`
+	DocID      primitive.ObjectID `bson:"doc_id" json:"doc_id"`
+	DocType    string             `bson:"doc_type" json:"doc_type"`
+	Author     string             `bson:"author,omitempty" json:"author,omitempty"`
+	Time       time.Time          `bson:"time" json:"time"`
+	Comment    string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	Transition *Transition        `bson:"transition,omitempty" json:"transition,omitempty"` // Set when revision was saved after change of status
+	Post       *Post              `bson:"post,omitempty" json:"post,omitempty"`
+	Page       *Page              `bson:"page,omitempty" json:"page,omitempty"`
+}
+
+// NewPostRevision returns revision with snapshot of post
+func NewPostRevision(post *Post, author, comment string) *Revision {
+	snapshot := *post
+
+	return &Revision{
+		ID:      primitive.NewObjectID(),
+		DocID:   post.ID,
+		DocType: RevisionPost,
+		Author:  author,
+		Time:    time.Now(),
+		Comment: comment,
+		Post:    &snapshot,
+	}
+}
+
+// NewPageRevision returns revision with snapshot of page
+func NewPageRevision(page *Page, author, comment string) *Revision {
+	snapshot := *page
+
+	return &Revision{
+		ID:      primitive.NewObjectID(),
+		DocID:   page.ID,
+		DocType: RevisionPage,
+		Author:  author,
+		Time:    time.Now(),
+		Comment: comment,
+		Page:    &snapshot,
+	}
+}
+
+// Blocks returns content of snapshot
+func (r Revision) Blocks() []Block {
+	switch {
+	case r.Post != nil:
+		return r.Post.PageData
+	case r.Page != nil:
+		return r.Page.PageData
+	}
+
+	return nil
+}
+
+// Validate revision struct
+func (r Revision) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.ID, validation.Required, validation.By(helpers.CheckObjectID)),
+		validation.Field(&r.DocID, validation.Required, validation.By(helpers.CheckObjectID)),
+		validation.Field(&r.DocType, validation.Required, validation.In(RevisionPost, RevisionPage)),
+		validation.Field(&r.Time, validation.Required),
+		// Snapshots are not validated again, they were valid when saved
+		validation.Field(&r.Post, validation.When(r.DocType == RevisionPost, validation.Required).Else(validation.Nil), validation.Skip),
+		validation.Field(&r.Page, validation.When(r.DocType == RevisionPage, validation.Required).Else(validation.Nil), validation.Skip),
+	)
+}
@@ -1,19 +1,24 @@
 package models
 
 import (
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Service is a structure for representing each service
 type Service struct {
-	ID       primitive.ObjectID `bson:"_id" json:"_id"`
-	Img      *ServiceImage      `bson:"img,omitempty" json:"img,omitempty"`
-	Title    string             `bson:"title,omitempty" json:"title,omitempty"`
-	Subtitle string             `bson:"subtitle,omitempty" json:"subtitle,omitempty"`
-	Desc     string             `bson:"desc,omitempty" json:"desc,omitempty"`
-	Slug     string             `bson:"slug,omitempty" json:"slug,omitempty"`
-	Deleted  bool               `bson:"deleted" json:"-"`
+	ID        primitive.ObjectID `bson:"_id" json:"_id"`
+	Img       *ServiceImage      `bson:"img,omitempty" json:"img,omitempty"`
+	Title     string             `bson:"title,omitempty" json:"title,omitempty"`
+	Subtitle  string             `bson:"subtitle,omitempty" json:"subtitle,omitempty"`
+	Desc      string             `bson:"desc,omitempty" json:"desc,omitempty"`
+	Slug      string             `bson:"slug,omitempty" json:"slug,omitempty"`
+	Deleted   bool               `bson:"deleted" json:"-"`
+	DeletedAt time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version   int64              `bson:"version" json:"version"`
 }
 
 // ServiceImage represets basic structure of service card image
@@ -0,0 +1,26 @@
+package models
+
+// Status is publication status of post or page
+type Status string
+
+const (
+	StatusDraft       Status = "draft"       // Prepared by editors and visible only in admin
+	StatusInReview    Status = "in_review"   // Submitted by author and waiting for reviewer
+	StatusScheduled   Status = "scheduled"   // Approved and published by scheduler at publish_at, used only by posts
+	StatusPublished   Status = "published"   // Visible on site, post is hidden again at unpublish_at if it is set
+	StatusUnpublished Status = "unpublished" // Was published and then withdrawn
+)
+
+// Statuses lists all publication statuses
+var Statuses = []interface{}{StatusDraft, StatusInReview, StatusScheduled, StatusPublished, StatusUnpublished}
+
+// Valid tells whether status is one of Statuses
+func (s Status) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag represents topic of posts and materials, unlike category item may have many of them
+type Tag struct {
+	ID        primitive.ObjectID `bson:"_id" json:"_id"`
+	Title     string             `bson:"title,omitempty" json:"title,omitempty"`
+	Slug      string             `bson:"slug,omitempty" json:"slug,omitempty"`
+	Deleted   bool               `bson:"deleted" json:"-"`
+	DeletedAt time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	Version   int64              `bson:"version" json:"version"`
+}
+
+// URL returns format url with format: "/tag/tag_slug"
+func (t Tag) URL() string {
+	return "/tag/" + t.Slug
+}
+
+// Validate check struct fields for correctness
+// Tags are short, e.g. НДС or ИП
+func (t Tag) Validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.ID, validation.Required, validation.By(helpers.CheckObjectID)),
+		validation.Field(&t.Title, validation.Required, validation.RuneLength(2, 55)),
+		validation.Field(&t.Slug, validation.Required),
+	)
+}
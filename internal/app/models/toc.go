@@ -0,0 +1,156 @@
+package models
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/slug"
+)
+
+// wordsPerMinute is average speed of reading Russian text
+const wordsPerMinute = 180
+
+// defaultAnchor is anchor of header without letters and digits
+const defaultAnchor = "section"
+
+var (
+	markup = regexp.MustCompile(`<[^>]*>`)
+	anchor = regexp.MustCompile(`^[a-z0-9_-]*$`)
+)
+
+// TOCItem is header in table of contents, headers of deeper levels below it are nested in Items
+type TOCItem struct {
+	Title  string
+	Anchor string
+	Level  int
+	Items  []*TOCItem
+}
+
+// plainText returns inline markup of block as text without tags and entities
+func plainText(text string) string {
+	return strings.TrimSpace(html.UnescapeString(markup.ReplaceAllString(text, "")))
+}
+
+// Anchored returns blocks where every header has anchor unique in blocks, given blocks are not changed
+// Anchor is made from text of header by slug generator, taken anchor gets suffix like taken slug does
+// Header keeps anchor it already has, so anchors of saved content stay stable
+func Anchored(blocks []Block, slugs *slug.Generator) []Block {
+	if blocks == nil {
+		return nil
+	}
+
+	result := make([]Block, len(blocks))
+	copy(result, blocks)
+
+	taken := make(map[string]bool)
+	isTaken := func(_ context.Context, anchor string) (bool, error) {
+		return taken[anchor], nil
+	}
+
+	for i, block := range result {
+		if block.Type != "header" || block.Data == nil {
+			continue
+		}
+
+		data := *block.Data
+		if data.Anchor == "" || taken[data.Anchor] {
+			text := plainText(data.Text)
+			if slugs.Make(text) == "" {
+				text = defaultAnchor
+			}
+
+			// Lookup in map never fails and context is never canceled
+			data.Anchor, _ = slugs.Unique(context.Background(), text, isTaken)
+		}
+
+		taken[data.Anchor] = true
+		result[i].Data = &data
+	}
+
+	return result
+}
+
+// BuildTOC returns table of contents of headers with anchors nested by their levels
+// Header more than one level deeper than previous one is nested into it anyway, so skipped levels don't break tree
+func BuildTOC(blocks []Block) []*TOCItem {
+	var (
+		toc   []*TOCItem
+		stack []*TOCItem // Path from root to last item
+	)
+
+	for _, block := range blocks {
+		if block.Type != "header" || block.Data == nil || block.Data.Anchor == "" {
+			continue
+		}
+
+		item := &TOCItem{Title: plainText(block.Data.Text), Anchor: block.Data.Anchor, Level: int(block.Data.Level)}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			toc = append(toc, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Items = append(parent.Items, item)
+		}
+
+		stack = append(stack, item)
+	}
+
+	return toc
+}
+
+// CountWords returns number of words in text of blocks, markup and punctuation are not counted
+func CountWords(blocks []Block) int {
+	words := 0
+
+	for _, block := range blocks {
+		if block.Data == nil {
+			continue
+		}
+
+		for _, text := range block.Data.texts() {
+			for _, w := range strings.Fields(plainText(text)) {
+				if strings.IndexFunc(w, isWordRune) >= 0 {
+					words++
+				}
+			}
+		}
+	}
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ReadingMinutes returns time of reading words in minutes, rounded up
+func ReadingMinutes(words int) int {
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// texts returns every text field of block data
+func (d *BlockData) texts() []string {
+	texts := []string{d.Text, d.Caption, d.Title, d.Message}
+	texts = appendItems(texts, d.Items)
+
+	for _, row := range d.Content {
+		texts = append(texts, row...)
+	}
+
+	return texts
+}
+
+func appendItems(texts []string, items []ListItem) []string {
+	for _, item := range items {
+		texts = appendItems(append(texts, item.Text), item.Items)
+	}
+
+	return texts
+}
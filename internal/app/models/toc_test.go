@@ -0,0 +1,127 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/slug"
+)
+
+func heading(text string, level int8) Block {
+	return Block{Type: "header", Data: &BlockData{Text: text, Level: level}}
+}
+
+// slugs is generator of tests, it separates words by -
+var slugs = slug.New(slug.GOST779, 0)
+
+func anchors(blocks []Block) []string {
+	var result []string
+	for _, block := range blocks {
+		if block.Type == "header" {
+			result = append(result, block.Data.Anchor)
+		}
+	}
+
+	return result
+}
+
+func TestAnchored(t *testing.T) {
+	testCases := []struct {
+		name   string
+		blocks []Block
+		want   []string
+	}{
+		{
+			name:   "Anchors from text",
+			blocks: []Block{heading("Tax <b>deductions</b>", 2), paragraph("Text"), heading("Property", 3)},
+			want:   []string{"tax-deductions", "property"},
+		},
+		{
+			name:   "Repeated headers",
+			blocks: []Block{heading("Example", 2), heading("Example", 2), heading("Example", 3)},
+			want:   []string{"example", "example-2", "example-3"},
+		},
+		{
+			name:   "Header without letters",
+			blocks: []Block{heading("—", 2), heading("…", 2)},
+			want:   []string{"section", "section-2"},
+		},
+		{
+			name: "Saved anchor is kept",
+			blocks: []Block{
+				{Type: "header", Data: &BlockData{Text: "Renamed", Level: 2, Anchor: "original"}},
+				heading("Original", 2),
+			},
+			want: []string{"original", "original-2"},
+		},
+		{
+			name:   "Cyrillic headers",
+			blocks: []Block{heading("Налоговый вычет", 2), heading("Налоговый вычет", 2)},
+			want:   []string{"nalogovyj-vychet", "nalogovyj-vychet-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := anchors(tc.blocks)
+
+			got := Anchored(tc.blocks, slugs)
+			assert.Equal(t, tc.want, anchors(got))
+			assert.Equal(t, before, anchors(tc.blocks), "given blocks must not be changed")
+			assert.Equal(t, tc.want, anchors(Anchored(got, slugs)), "anchors must be stable")
+		})
+	}
+}
+
+func TestAnchored_Separator(t *testing.T) {
+	got := Anchored([]Block{heading("Моя статья", 2), heading("Моя статья", 2)}, slug.New(slug.Legacy, 0))
+	assert.Equal(t, []string{"moya_statya", "moya_statya_2"}, anchors(got))
+}
+
+func TestBuildTOC(t *testing.T) {
+	blocks := Anchored([]Block{
+		heading("Deductions", 2),
+		heading("Property", 3),
+		paragraph("Text"),
+		heading("Social", 3),
+		heading("Medicine", 4),
+		heading("Tax &amp; fees", 2),
+		heading("Skipped level", 4),
+	}, slugs)
+
+	toc := BuildTOC(blocks)
+
+	assert.Equal(t, []*TOCItem{
+		{Title: "Deductions", Anchor: "deductions", Level: 2, Items: []*TOCItem{
+			{Title: "Property", Anchor: "property", Level: 3},
+			{Title: "Social", Anchor: "social", Level: 3, Items: []*TOCItem{
+				{Title: "Medicine", Anchor: "medicine", Level: 4},
+			}},
+		}},
+		{Title: "Tax & fees", Anchor: "tax-fees", Level: 2, Items: []*TOCItem{
+			{Title: "Skipped level", Anchor: "skipped-level", Level: 4},
+		}},
+	}, toc)
+
+	assert.Nil(t, BuildTOC([]Block{paragraph("Text")}))
+}
+
+func TestCountWords(t *testing.T) {
+	blocks := []Block{
+		heading("Налоговый вычет", 2),
+		paragraph("Вычет <b>можно</b> получить&nbsp;— за 3 года"),
+		{Type: "list", Data: &BlockData{Items: []ListItem{{Text: "Паспорт", Items: []ListItem{{Text: "Копия паспорта"}}}}}},
+		{Type: "table", Data: &BlockData{Content: [][]string{{"Налог", "13 %"}}}},
+		{Type: "image", Data: &BlockData{File: &FileInfo{URL: "/uploads/report.jpg"}, Caption: "Отчет"}},
+		{Type: "delimiter", Data: &BlockData{}},
+	}
+
+	assert.Equal(t, 14, CountWords(blocks))
+}
+
+func TestReadingMinutes(t *testing.T) {
+	assert.Equal(t, 0, ReadingMinutes(0))
+	assert.Equal(t, 1, ReadingMinutes(1))
+	assert.Equal(t, 1, ReadingMinutes(wordsPerMinute))
+	assert.Equal(t, 2, ReadingMinutes(wordsPerMinute+1))
+}
@@ -21,7 +21,8 @@ type User struct {
 	EncryptedPassword string             `bson:"pswd" json:"-"`
 	Password          string             `bson:"-" json:"pswd"`
 	Email             string             `bson:"email,omitempty" json:"email,omitempty"`
-	deleted           bool               `bson:"deleted" json:"-"`
+	Author            *Author            `bson:"author,omitempty" json:"author,omitempty"` // Public profile, nil until user fills it
+	Deleted           bool               `bson:"deleted" json:"-"`
 }
 
 // Validate user struct
@@ -0,0 +1,206 @@
+package render
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+)
+
+// tags matches HTML tags of inline markup
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// plain returns text without inline markup safe to put into attribute
+// Text is already escaped by sanitizer on save, so entities are decoded before escaping it again
+func plain(text string) string {
+	return template.HTMLEscapeString(html.UnescapeString(strings.TrimSpace(tags.ReplaceAllString(text, ""))))
+}
+
+// safeURL returns escaped URL of file, only relative and http(s) URLs are allowed
+func safeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return template.HTMLEscapeString(u.String()), true
+	}
+
+	return "", false
+}
+
+// el returns BEM class of element, e.g. singlepost__text
+func el(class, element string) string {
+	if class == "" {
+		return element
+	}
+
+	return class + "__" + element
+}
+
+// modified returns class of element with its modifiers, empty modifiers are skipped
+func modified(class string, modifiers ...string) string {
+	names := []string{class}
+	for _, m := range modifiers {
+		if m != "" {
+			names = append(names, class+"--"+m)
+		}
+	}
+
+	return strings.Join(names, " ")
+}
+
+// when returns modifier when it is on
+func when(on bool, modifier string) string {
+	if on {
+		return modifier
+	}
+
+	return ""
+}
+
+func renderParagraph(b *strings.Builder, data *models.BlockData, class string) {
+	b.WriteString(`<p class="` + el(class, "text") + `">` + data.Text + `</p>`)
+}
+
+// renderHeader writes heading of level from 1 to 6 with its anchor, level out of range is second
+func renderHeader(b *strings.Builder, data *models.BlockData, class string) {
+	level := int(data.Level)
+	if level < 1 || level > 6 {
+		level = 2
+	}
+
+	tag := "h" + strconv.Itoa(level)
+
+	b.WriteString(`<` + tag)
+	if data.Anchor != "" {
+		b.WriteString(` id="` + template.HTMLEscapeString(data.Anchor) + `"`)
+	}
+	b.WriteString(` class="` + el(class, "header") + `">` + data.Text + `</` + tag + `>`)
+}
+
+// renderImage writes figure with image and caption, caption is alt text too
+// Image without file or with unsafe URL is skipped
+func renderImage(b *strings.Builder, data *models.BlockData, class string) {
+	if data.File == nil {
+		return
+	}
+
+	src, ok := safeURL(data.File.URL)
+	if !ok {
+		return
+	}
+
+	figure := modified(el(class, "figure"), when(data.WithBorder, "border"), when(data.WithBackground, "background"), when(data.Stretched, "stretched"))
+
+	b.WriteString(`<figure class="` + figure + `">`)
+	b.WriteString(`<img src="` + src + `" alt="` + plain(data.Caption) + `" class="` + el(class, "img") + `"`)
+	if data.File.Width > 0 && data.File.Height > 0 {
+		b.WriteString(` width="` + strconv.Itoa(int(data.File.Width)) + `" height="` + strconv.Itoa(int(data.File.Height)) + `"`)
+	}
+	b.WriteString(`>`)
+
+	if data.Caption != "" {
+		b.WriteString(`<figcaption class="` + el(class, "caption") + `">` + data.Caption + `</figcaption>`)
+	}
+
+	b.WriteString(`</figure>`)
+}
+
+// renderList writes ordered or unordered list with nested lists of the same style
+func renderList(b *strings.Builder, data *models.BlockData, class string) {
+	tag := "ul"
+	if data.Style == "ordered" {
+		tag = "ol"
+	}
+
+	writeItems(b, data.Items, tag, el(class, "list"))
+}
+
+func writeItems(b *strings.Builder, items []models.ListItem, tag, class string) {
+	b.WriteString(`<` + tag + ` class="` + class + `">`)
+
+	for _, item := range items {
+		b.WriteString(`<li>` + item.Text)
+		if len(item.Items) > 0 {
+			writeItems(b, item.Items, tag, class)
+		}
+		b.WriteString(`</li>`)
+	}
+
+	b.WriteString(`</` + tag + `>`)
+}
+
+func renderChecklist(b *strings.Builder, data *models.BlockData, class string) {
+	b.WriteString(`<ul class="` + el(class, "checklist") + `">`)
+
+	for _, item := range data.Items {
+		b.WriteString(`<li class="` + modified(el(class, "checklist-item"), when(item.Checked, "checked")) + `">`)
+		b.WriteString(item.Text + `</li>`)
+	}
+
+	b.WriteString(`</ul>`)
+}
+
+// renderQuote writes quote with its author, centered quote has modifier
+func renderQuote(b *strings.Builder, data *models.BlockData, class string) {
+	b.WriteString(`<blockquote class="` + modified(el(class, "quote"), when(data.Alignment == "center", "center")) + `">`)
+	b.WriteString(`<p>` + data.Text + `</p>`)
+
+	if data.Caption != "" {
+		b.WriteString(`<cite>` + data.Caption + `</cite>`)
+	}
+
+	b.WriteString(`</blockquote>`)
+}
+
+// renderTable writes table, its first row is head when table has headings
+func renderTable(b *strings.Builder, data *models.BlockData, class string) {
+	rows := data.Content
+
+	b.WriteString(`<table class="` + el(class, "table") + `">`)
+
+	if data.WithHeadings && len(rows) > 0 {
+		b.WriteString(`<thead><tr>`)
+		for _, cell := range rows[0] {
+			b.WriteString(`<th>` + cell + `</th>`)
+		}
+		b.WriteString(`</tr></thead>`)
+		rows = rows[1:]
+	}
+
+	b.WriteString(`<tbody>`)
+	for _, row := range rows {
+		b.WriteString(`<tr>`)
+		for _, cell := range row {
+			b.WriteString(`<td>` + cell + `</td>`)
+		}
+		b.WriteString(`</tr>`)
+	}
+	b.WriteString(`</tbody></table>`)
+}
+
+func renderDelimiter(b *strings.Builder, data *models.BlockData, class string) {
+	b.WriteString(`<hr class="` + el(class, "delimiter") + `">`)
+}
+
+func renderWarning(b *strings.Builder, data *models.BlockData, class string) {
+	b.WriteString(`<div class="` + el(class, "warning") + `" role="note">`)
+
+	if data.Title != "" {
+		b.WriteString(`<p class="` + el(class, "warning-title") + `">` + data.Title + `</p>`)
+	}
+
+	b.WriteString(`<p class="` + el(class, "warning-message") + `">` + data.Message + `</p></div>`)
+}
@@ -0,0 +1,84 @@
+// Package render turns Editor.js blocks of posts and pages into HTML
+// Every block type is rendered by its BlockRenderer from Registry, blocks of unknown types are skipped
+package render
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+	"github.com/the-NZA/acg-nikolaev/internal/app/typograph"
+)
+
+// BlockRenderer writes HTML of block data to b
+// Class is BEM block of page which names CSS classes of elements, e.g. singlepost
+// Text fields of data are sanitized by policy of registry already, so they are written as is
+type BlockRenderer func(b *strings.Builder, data *models.BlockData, class string)
+
+// Registry holds renderers of block types
+type Registry struct {
+	renderers map[string]BlockRenderer
+	policy    *sanitize.Policy
+	Typograph bool // Typography is applied to blocks while rendering, blocks with noTypograph tune are left as typed
+}
+
+// NewRegistry returns registry with renderers of all standard block types
+// Inline markup of blocks is sanitized by policy while rendering, it is done on save too,
+// but content saved before sanitizer or directly to db may still have unsafe markup
+func NewRegistry(policy *sanitize.Policy) *Registry {
+	r := &Registry{renderers: make(map[string]BlockRenderer), policy: policy}
+
+	r.Register("paragraph", renderParagraph)
+	r.Register("header", renderHeader)
+	r.Register("image", renderImage)
+	r.Register("list", renderList)
+	r.Register("checklist", renderChecklist)
+	r.Register("quote", renderQuote)
+	r.Register("table", renderTable)
+	r.Register("delimiter", renderDelimiter)
+	r.Register("warning", renderWarning)
+
+	return r
+}
+
+// Register sets renderer of block type, renderer of the same type is replaced
+func (r *Registry) Register(blockType string, fn BlockRenderer) {
+	r.renderers[blockType] = fn
+}
+
+// Render returns HTML of blocks
+// Blocks of unknown types and blocks without data are skipped, so broken content never breaks page
+func (r *Registry) Render(blocks []models.Block, class string) template.HTML {
+	b := &strings.Builder{}
+
+	for _, block := range blocks {
+		fn, ok := r.renderers[block.Type]
+		if !ok || block.Data == nil {
+			continue
+		}
+
+		if r.Typograph {
+			block = block.Typographed()
+		}
+		block = block.Sanitized(r.policy)
+
+		// Renderer may skip invalid block, e.g. image with unsafe URL
+		n := b.Len()
+		if fn(b, block.Data, class); b.Len() > n {
+			b.WriteString("\n")
+		}
+	}
+
+	return template.HTML(b.String())
+}
+
+// Text applies typography to plain text when registry applies it to blocks
+// It is used as template func for titles and snippets
+func (r *Registry) Text(text string) string {
+	if r.Typograph {
+		return typograph.Text(text)
+	}
+
+	return text
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/sanitize"
+)
+
+// update rewrites golden files with current output: go test ./internal/app/render -update
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRegistry_Render_Golden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var blocks []models.Block
+			if err = json.Unmarshal(data, &blocks); err != nil {
+				t.Fatal(err)
+			}
+
+			got := string(NewRegistry(sanitize.New()).Render(blocks, "singlepost"))
+			golden := filepath.Join("testdata", name+".golden.html")
+
+			if *update {
+				if err = os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry(sanitize.New())
+	r.Register("code", func(b *strings.Builder, data *models.BlockData, class string) {
+		b.WriteString(`<pre class="` + el(class, "code") + `">` + data.Text + `</pre>`)
+	})
+
+	got := r.Render([]models.Block{{Type: "code", Data: &models.BlockData{Text: "a < b"}}}, "singlepage")
+	assert.Equal(t, "<pre class=\"singlepage__code\">a &lt; b</pre>\n", string(got))
+}
+
+func TestRegistry_Render_Typograph(t *testing.T) {
+	blocks := []models.Block{
+		{Type: "paragraph", Data: &models.BlockData{Text: `Налог - "обязательный" платеж`}},
+		{Type: "paragraph", Data: &models.BlockData{Text: `Код "as is"`}, Tunes: &models.BlockTunes{NoTypograph: true}},
+	}
+
+	r := NewRegistry(sanitize.New())
+	r.Typograph = true
+
+	got := string(r.Render(blocks, "singlepost"))
+	assert.Equal(t, "<p class=\"singlepost__text\">Налог\u00a0— «обязательный» платеж</p>\n<p class=\"singlepost__text\">Код &#34;as is&#34;</p>\n", got)
+	assert.Equal(t, `Налог - "обязательный" платеж`, blocks[0].Data.Text, "saved text must be left as typed")
+
+	assert.Equal(t, "Налог\u00a0— платеж", r.Text("Налог - платеж"))
+	assert.Equal(t, "Налог - платеж", NewRegistry(sanitize.New()).Text("Налог - платеж"))
+}
+
+func TestRegistry_Render_Policy(t *testing.T) {
+	blocks := []models.Block{
+		{Type: "paragraph", Data: &models.BlockData{Text: `<a href="https://acg-nikolaev.ru/posts">Записи</a><script>alert(1)</script>`}},
+	}
+
+	got := string(NewRegistry(sanitize.New("acg-nikolaev.ru")).Render(blocks, "singlepost"))
+	assert.Equal(t, "<p class=\"singlepost__text\"><a href=\"https://acg-nikolaev.ru/posts\">Записи</a></p>\n", got)
+
+	got = string(NewRegistry(sanitize.New()).Render(blocks, "singlepost"))
+	assert.Equal(t, "<p class=\"singlepost__text\"><a href=\"https://acg-nikolaev.ru/posts\" rel=\"noopener nofollow\">Записи</a></p>\n", got)
+}
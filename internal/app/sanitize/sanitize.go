@@ -0,0 +1,206 @@
+// Package sanitize cleans inline markup of Editor.js blocks by allowlist
+// Allowed tags are kept without attributes except href of links, other tags are dropped with their text kept,
+// content of script and style is dropped entirely, the rest of text is escaped
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// allowed are inline tags kept by sanitizer
+var allowed = map[string]bool{
+	"a":      true,
+	"b":      true,
+	"strong": true,
+	"i":      true,
+	"em":     true,
+	"u":      true,
+	"s":      true,
+	"mark":   true,
+	"code":   true,
+	"sub":    true,
+	"sup":    true,
+	"br":     true,
+}
+
+// dropped are tags removed together with their content
+var dropped = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+var (
+	tag     = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:\s+[^\s"'>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'>]+))?)*)\s*/?>`)
+	attr    = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+	comment = regexp.MustCompile(`^<!--[\s\S]*?-->`)
+)
+
+// Policy sanitizes markup, links to hosts of site are internal and the rest are external
+type Policy struct {
+	hosts map[string]bool
+}
+
+// New returns policy with hosts of site, www prefix and port of host are ignored
+func New(hosts ...string) *Policy {
+	p := &Policy{hosts: make(map[string]bool)}
+
+	for _, h := range hosts {
+		if h = normalizeHost(h); h != "" {
+			p.hosts[h] = true
+		}
+	}
+
+	return p
+}
+
+// HTML returns text with allowed inline markup only
+// Unclosed tags are closed at the end, external links get rel="noopener nofollow"
+// Result is stable, sanitizing it again doesn't change it
+func (p *Policy) HTML(text string) string {
+	b := &strings.Builder{}
+	var open []string
+
+	for len(text) > 0 {
+		i := strings.IndexByte(text, '<')
+		if i < 0 {
+			b.WriteString(escape(text))
+			break
+		}
+
+		b.WriteString(escape(text[:i]))
+		text = text[i:]
+
+		if m := comment.FindString(text); m != "" {
+			text = text[len(m):]
+			continue
+		}
+
+		m := tag.FindStringSubmatch(text)
+		if m == nil {
+			b.WriteString("&lt;")
+			text = text[1:]
+			continue
+		}
+
+		text = text[len(m[0]):]
+		closing, name := m[1] == "/", strings.ToLower(m[2])
+
+		switch {
+		case dropped[name] && !closing:
+			text = skipContent(text, name)
+		case !allowed[name]:
+		case name == "br":
+			if !closing {
+				b.WriteString("<br>")
+			}
+		case closing:
+			open = closeTag(b, open, name)
+		default:
+			b.WriteString(p.openTag(name, m[3]))
+			open = append(open, name)
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+
+	return b.String()
+}
+
+// openTag returns allowed opening tag, link keeps only its safe href
+func (p *Policy) openTag(name, attrs string) string {
+	if name != "a" {
+		return "<" + name + ">"
+	}
+
+	for _, a := range attr.FindAllStringSubmatch(attrs, -1) {
+		if strings.ToLower(a[1]) != "href" {
+			continue
+		}
+
+		href, external, ok := p.link(html.UnescapeString(a[2] + a[3] + a[4]))
+		if !ok {
+			break
+		}
+
+		if external {
+			return `<a href="` + html.EscapeString(href) + `" rel="noopener nofollow">`
+		}
+
+		return `<a href="` + html.EscapeString(href) + `">`
+	}
+
+	return "<a>"
+}
+
+// link checks href, only relative, http(s) and mailto links are allowed
+// Link is external when it has host which is not host of site
+func (p *Policy) link(href string) (string, bool, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return "", false, false
+	}
+
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false, false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+	default:
+		return "", false, false
+	}
+
+	return u.String(), u.Host != "" && !p.hosts[normalizeHost(u.Host)], true
+}
+
+// closeTag closes tag with all tags opened inside it, closing tag without opening one is dropped
+func closeTag(b *strings.Builder, open []string, name string) []string {
+	for i := len(open) - 1; i >= 0; i-- {
+		if open[i] != name {
+			continue
+		}
+
+		for j := len(open) - 1; j >= i; j-- {
+			b.WriteString("</" + open[j] + ">")
+		}
+
+		return open[:i]
+	}
+
+	return open
+}
+
+// skipContent returns text after closing tag of name, whole text is skipped when tag is not closed
+func skipContent(text, name string) string {
+	i := strings.Index(strings.ToLower(text), "</"+name)
+	if i < 0 {
+		return ""
+	}
+
+	text = text[i:]
+	if j := strings.IndexByte(text, '>'); j >= 0 {
+		return text[j+1:]
+	}
+
+	return ""
+}
+
+// escape escapes text, entities of editor like &nbsp; are decoded first so they are not escaped twice
+func escape(text string) string {
+	return html.EscapeString(html.UnescapeString(text))
+}
+
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if u, err := url.Parse("//" + host); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	return strings.TrimPrefix(host, "www.")
+}
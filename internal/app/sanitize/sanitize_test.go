@@ -0,0 +1,136 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_HTML(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Plain text",
+			input: "Налоги в 2021 году",
+			want:  "Налоги в 2021 году",
+		},
+		{
+			name:  "Allowed tags",
+			input: "<b>Важно</b>, <i>курсив</i> и <mark class=\"cdx-marker\">маркер</mark><br>",
+			want:  "<b>Важно</b>, <i>курсив</i> и <mark>маркер</mark><br>",
+		},
+		{
+			name:  "Tags in upper case",
+			input: "<STRONG>Жирный</STRONG>",
+			want:  "<strong>Жирный</strong>",
+		},
+		{
+			name:  "Unknown tags keep text",
+			input: "<div><span style=\"color:red\">Текст</span></div>",
+			want:  "Текст",
+		},
+		{
+			name:  "Script is dropped with content",
+			input: "До<script>alert(1)</script>после",
+			want:  "Допосле",
+		},
+		{
+			name:  "Unclosed script drops rest",
+			input: "До<script>alert(1)",
+			want:  "До",
+		},
+		{
+			name:  "Event handlers are dropped",
+			input: "<b onclick=\"alert(1)\">Текст</b>",
+			want:  "<b>Текст</b>",
+		},
+		{
+			name:  "Comment",
+			input: "Текст<!-- <b>комментарий</b> -->",
+			want:  "Текст",
+		},
+		{
+			name:  "Stray brackets and ampersands",
+			input: "1 < 2 && 3 > 2",
+			want:  "1 &lt; 2 &amp;&amp; 3 &gt; 2",
+		},
+		{
+			name:  "Entities are not escaped twice",
+			input: "Тест&nbsp;&amp;&nbsp;тест",
+			want:  "Тест\u00a0&amp;\u00a0тест",
+		},
+		{
+			name:  "Unclosed tags are closed",
+			input: "<b>Жирный <i>курсив",
+			want:  "<b>Жирный <i>курсив</i></b>",
+		},
+		{
+			name:  "Closing tag closes inner tags",
+			input: "<b>Жирный <i>курсив</b> текст",
+			want:  "<b>Жирный <i>курсив</i></b> текст",
+		},
+		{
+			name:  "Closing tag without opening",
+			input: "Текст</b></a>",
+			want:  "Текст",
+		},
+		{
+			name:  "Relative link",
+			input: "<a href=\"/category/news\">Новости</a>",
+			want:  "<a href=\"/category/news\">Новости</a>",
+		},
+		{
+			name:  "Internal link",
+			input: "<a href=\"https://www.acg-nikolaev.ru/posts\">Записи</a>",
+			want:  "<a href=\"https://www.acg-nikolaev.ru/posts\">Записи</a>",
+		},
+		{
+			name:  "External link",
+			input: "<a href='https://example.com/?a=1&amp;b=2' target=\"_blank\">Ссылка</a>",
+			want:  "<a href=\"https://example.com/?a=1&amp;b=2\" rel=\"noopener nofollow\">Ссылка</a>",
+		},
+		{
+			name:  "Protocol relative link is external",
+			input: "<a href=\"//example.com\">Ссылка</a>",
+			want:  "<a href=\"//example.com\" rel=\"noopener nofollow\">Ссылка</a>",
+		},
+		{
+			name:  "Mailto link",
+			input: "<a href=\"mailto:info@acg-nikolaev.ru\">Почта</a>",
+			want:  "<a href=\"mailto:info@acg-nikolaev.ru\">Почта</a>",
+		},
+		{
+			name:  "Javascript link",
+			input: "<a href=\"javascript:alert(1)\">Ссылка</a>",
+			want:  "<a>Ссылка</a>",
+		},
+		{
+			name:  "Encoded javascript link",
+			input: "<a href=\"&#106;avascript:alert(1)\">Ссылка</a>",
+			want:  "<a>Ссылка</a>",
+		},
+		{
+			name:  "Quote in attribute",
+			input: "<a href=\"/posts\" title='a > b'>Записи</a>",
+			want:  "<a href=\"/posts\">Записи</a>",
+		},
+		{
+			name:  "Broken tag",
+			input: "<a href=\"/posts\"Записи",
+			want:  "&lt;a href=&#34;/posts&#34;Записи",
+		},
+	}
+
+	p := New("acg-nikolaev.ru:8080")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := p.HTML(tc.input)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, got, p.HTML(got), "sanitized text must be stable")
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package search
+
+import "github.com/the-NZA/acg-nikolaev/internal/app/models"
+
+// PostDocument returns searchable post, slug of its category is required for URL
+func PostDocument(post *models.Post, categorySlug string) Document {
+	p := *post
+	p.CategorySlug = categorySlug
+
+	return Document{
+		ID:       post.ID,
+		Type:     TypePost,
+		Title:    post.Title,
+		Snippet:  post.Snippet,
+		MetaDesc: post.MetaDesc,
+		Body:     blocksText(post.PageData),
+		URL:      p.GetURL(),
+		Time:     post.Time,
+		Deleted:  post.Deleted,
+		Hidden:   !post.IsPublished(),
+	}
+}
+
+// PageDocument returns searchable page
+func PageDocument(page *models.Page) Document {
+	return Document{
+		ID:       page.ID,
+		Type:     TypePage,
+		Title:    page.Title,
+		Snippet:  page.Subtitle,
+		MetaDesc: page.MetaDesc,
+		Body:     blocksText(page.PageData),
+		URL:      page.URL,
+		Deleted:  page.Deleted,
+		Hidden:   !page.IsPublished(),
+	}
+}
+
+// MaterialDocument returns searchable material, it links to its file
+func MaterialDocument(material *models.Material) Document {
+	return Document{
+		ID:      material.ID,
+		Type:    TypeMaterial,
+		Title:   material.Title,
+		Snippet: material.Desc,
+		URL:     material.FileLink,
+		Time:    material.Time,
+		Deleted: material.Deleted,
+	}
+}
+
+// ServiceDocument returns searchable service, it links to its card on services page
+func ServiceDocument(service *models.Service) Document {
+	return Document{
+		ID:       service.ID,
+		Type:     TypeService,
+		Title:    service.Title,
+		Snippet:  service.Subtitle,
+		MetaDesc: service.Desc,
+		URL:      "/services#" + service.Slug,
+		Deleted:  service.Deleted,
+	}
+}
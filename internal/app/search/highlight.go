@@ -0,0 +1,102 @@
+package search
+
+import (
+	"html/template"
+	"strings"
+)
+
+// fragmentWords is number of words in fragment of text shown in results
+const fragmentWords = 30
+
+// Highlight returns escaped text with words matching terms wrapped in <mark>
+// Positive maxWords cuts text to window of that many words around the first match
+func Highlight(text string, terms []string, maxWords int) template.HTML {
+	tokens := tokenize(text)
+
+	match := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		match[t] = true
+	}
+
+	from, to := 0, len(tokens)
+	if maxWords > 0 && len(tokens) > maxWords {
+		first := 0
+		for i, t := range tokens {
+			if s := term(t.word); s != "" && match[s] {
+				first = i
+				break
+			}
+		}
+
+		// Some context is kept before the first match
+		from = first - maxWords/4
+		if from < 0 {
+			from = 0
+		}
+
+		to = from + maxWords
+		if to > len(tokens) {
+			to, from = len(tokens), len(tokens)-maxWords
+		}
+	}
+
+	var (
+		sb    strings.Builder
+		start = 0
+		end   = len(text)
+	)
+
+	if from > 0 {
+		start = tokens[from].start
+		sb.WriteString("… ")
+	}
+
+	if to < len(tokens) {
+		end = tokens[to-1].end
+	}
+
+	pos := start
+	for _, t := range tokens[from:to] {
+		if s := term(t.word); s == "" || !match[s] {
+			continue
+		}
+
+		sb.WriteString(template.HTMLEscapeString(text[pos:t.start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(template.HTMLEscapeString(text[t.start:t.end]))
+		sb.WriteString("</mark>")
+		pos = t.end
+	}
+
+	sb.WriteString(template.HTMLEscapeString(text[pos:end]))
+
+	if end < len(text) {
+		sb.WriteString(" …")
+	}
+
+	return template.HTML(sb.String())
+}
+
+// fragment returns highlighted part of the first text field containing terms
+// Snippet is used when terms are found only in title
+func fragment(doc Document, terms []string) template.HTML {
+	fields := []string{doc.Body, doc.Snippet, doc.MetaDesc}
+
+	for _, text := range fields {
+		for _, t := range Terms(text) {
+			for _, q := range terms {
+				if t == q {
+					return Highlight(text, terms, fragmentWords)
+				}
+			}
+		}
+	}
+
+	for _, text := range []string{doc.Snippet, doc.MetaDesc, doc.Body} {
+		if text != "" {
+			return Highlight(text, terms, fragmentWords)
+		}
+	}
+
+	return ""
+}
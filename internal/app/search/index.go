@@ -0,0 +1,319 @@
+// Package search implements full-text search over site content
+// Title, snippet, meta description and text of blocks are indexed with Russian stemming
+// and results are ranked by BM25 with field weights
+package search
+
+import (
+	"html/template"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Types of searchable documents
+const (
+	TypePost     = "post"
+	TypePage     = "page"
+	TypeMaterial = "material"
+	TypeService  = "service"
+)
+
+// Indexed fields in order of their weight
+const (
+	fieldTitle = iota
+	fieldSnippet
+	fieldMetaDesc
+	fieldBody
+	numFields
+)
+
+var fieldWeights = [numFields]float64{3, 2, 1.5, 1}
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Document is searchable representation of content
+type Document struct {
+	ID       primitive.ObjectID `json:"_id"`
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Snippet  string             `json:"snippet,omitempty"`
+	MetaDesc string             `json:"metadesc,omitempty"`
+	Body     string             `json:"-"`
+	URL      string             `json:"url"`
+	Time     time.Time          `json:"time,omitempty"`
+	Deleted  bool               `json:"deleted"`
+	Hidden   bool               `json:"hidden"` // Not visible on site though not deleted, e.g. draft post
+}
+
+// Query describes search request
+type Query struct {
+	Text    string
+	Types   []string // Empty means all types
+	Deleted store.DeletedState
+	Hidden  bool // Hidden documents are found only by editors
+	Limit   int  // Zero means no limit
+	Offset  int
+}
+
+// Hit is found document with its score and highlighted parts
+type Hit struct {
+	Document
+	Score    float64       `json:"score"`
+	Headline template.HTML `json:"headline"` // Title with marked terms
+	Fragment template.HTML `json:"fragment"` // Part of text around matches with marked terms
+}
+
+// Result is page of hits ordered by score
+type Result struct {
+	Total int    `json:"total"` // Number of all hits ignoring Limit and Offset
+	Hits  []*Hit `json:"hits"`
+}
+
+// entry is indexed document
+type entry struct {
+	doc    Document
+	freqs  map[string]float64 // Weighted frequencies of terms in all fields
+	length float64            // Weighted number of terms
+}
+
+// Index is in-memory inverted index safe for concurrent use
+type Index struct {
+	mu       sync.RWMutex
+	entries  map[string]*entry
+	postings map[string]map[string]struct{} // Keys of entries by term
+	total    float64                        // Sum of lengths of entries
+}
+
+// NewIndex returns empty index
+func NewIndex() *Index {
+	return &Index{
+		entries:  make(map[string]*entry),
+		postings: make(map[string]map[string]struct{}),
+	}
+}
+
+func key(docType string, ID primitive.ObjectID) string {
+	return docType + ":" + ID.Hex()
+}
+
+func newEntry(doc Document) *entry {
+	e := &entry{doc: doc, freqs: make(map[string]float64)}
+
+	for f, text := range [numFields]string{doc.Title, doc.Snippet, doc.MetaDesc, doc.Body} {
+		for _, t := range Terms(text) {
+			e.freqs[t] += fieldWeights[f]
+			e.length += fieldWeights[f]
+		}
+	}
+
+	return e
+}
+
+// Len returns number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.entries)
+}
+
+// Put adds document to index or replaces its previous version
+func (idx *Index) Put(doc Document) {
+	e := newEntry(doc)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(key(doc.Type, doc.ID))
+	idx.add(e)
+}
+
+// Remove drops document from index
+func (idx *Index) Remove(docType string, ID primitive.ObjectID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(key(docType, ID))
+}
+
+// MarkDeleted moves document to trash or back without reindexing its text, missing document is skipped
+func (idx *Index) MarkDeleted(docType string, ID primitive.ObjectID, deleted bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if e, ok := idx.entries[key(docType, ID)]; ok {
+		e.doc.Deleted = deleted
+	}
+}
+
+// Replace drops all documents of type and adds docs instead of them
+func (idx *Index) Replace(docType string, docs []Document) {
+	entries := make([]*entry, len(docs))
+	for i, doc := range docs {
+		entries[i] = newEntry(doc)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for k, e := range idx.entries {
+		if e.doc.Type == docType {
+			idx.remove(k)
+		}
+	}
+
+	for _, e := range entries {
+		idx.add(e)
+	}
+}
+
+func (idx *Index) add(e *entry) {
+	k := key(e.doc.Type, e.doc.ID)
+
+	idx.entries[k] = e
+	idx.total += e.length
+
+	for t := range e.freqs {
+		if idx.postings[t] == nil {
+			idx.postings[t] = make(map[string]struct{})
+		}
+		idx.postings[t][k] = struct{}{}
+	}
+}
+
+func (idx *Index) remove(k string) {
+	e, ok := idx.entries[k]
+	if !ok {
+		return
+	}
+
+	delete(idx.entries, k)
+	idx.total -= e.length
+
+	for t := range e.freqs {
+		delete(idx.postings[t], k)
+		if len(idx.postings[t]) == 0 {
+			delete(idx.postings, t)
+		}
+	}
+}
+
+// Search returns documents which contain any term of query text
+// Documents containing more distinct terms of query are ranked higher
+func (idx *Index) Search(q Query) Result {
+	terms := uniqueTerms(q.Text)
+	res := Result{Hits: []*Hit{}}
+
+	if len(terms) == 0 {
+		return res
+	}
+
+	types := make(map[string]bool, len(q.Types))
+	for _, t := range q.Types {
+		types[t] = true
+	}
+
+	idx.mu.RLock()
+
+	n := float64(len(idx.entries))
+	avgLength := idx.total / math.Max(n, 1)
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+
+	for _, t := range terms {
+		keys := idx.postings[t]
+		df := float64(len(keys))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for k := range keys {
+			e := idx.entries[k]
+			if !visible(e.doc, q.Deleted) || (e.doc.Hidden && !q.Hidden) || (len(types) > 0 && !types[e.doc.Type]) {
+				continue
+			}
+
+			tf := e.freqs[t]
+			scores[k] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*e.length/avgLength))
+			matched[k]++
+		}
+	}
+
+	hits := make([]*Hit, 0, len(scores))
+	for k, score := range scores {
+		hits = append(hits, &Hit{
+			Document: idx.entries[k].doc,
+			Score:    score * float64(matched[k]) / float64(len(terms)),
+		})
+	}
+
+	idx.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		if !hits[i].Time.Equal(hits[j].Time) {
+			return hits[i].Time.After(hits[j].Time)
+		}
+
+		return hits[i].ID.Hex() < hits[j].ID.Hex()
+	})
+
+	res.Total = len(hits)
+
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	if q.Offset > len(hits) {
+		q.Offset = len(hits)
+	}
+	hits = hits[q.Offset:]
+
+	if q.Limit > 0 && q.Limit < len(hits) {
+		hits = hits[:q.Limit]
+	}
+
+	for _, h := range hits {
+		h.Headline = Highlight(h.Title, terms, 0)
+		h.Fragment = fragment(h.Document, terms)
+	}
+
+	res.Hits = hits
+
+	return res
+}
+
+// uniqueTerms returns distinct terms of text in order of appearance
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+
+	for _, t := range Terms(text) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+
+	return terms
+}
+
+func visible(doc Document, state store.DeletedState) bool {
+	switch state {
+	case store.OnlyDeleted:
+		return doc.Deleted
+	case store.AnyDeleted:
+		return true
+	}
+
+	return !doc.Deleted
+}
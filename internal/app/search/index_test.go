@@ -0,0 +1,139 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testIndex() (*Index, []Document) {
+	docs := []Document{
+		{ID: primitive.NewObjectID(), Type: TypePost, Title: "Налоговые проверки", Snippet: "Как подготовиться к проверке", URL: "/category/news/proverki", Time: time.Now()},
+		{ID: primitive.NewObjectID(), Type: TypePost, Title: "Новости компании", Body: "Мы провели консультации по налогам для клиентов", URL: "/category/news/novosti", Time: time.Now()},
+		{ID: primitive.NewObjectID(), Type: TypeService, Title: "Бухгалтерские консультации", Snippet: "Консультации по налогам и отчётности", URL: "/services#consult"},
+		{ID: primitive.NewObjectID(), Type: TypePage, Title: "Контакты", Body: "Телефон и адрес офиса", URL: "/contacts"},
+		{ID: primitive.NewObjectID(), Type: TypePost, Title: "Удалённая запись о налогах", URL: "/category/news/old", Deleted: true},
+		{ID: primitive.NewObjectID(), Type: TypePost, Title: "Черновик о налогах", URL: "/category/news/draft", Hidden: true},
+	}
+
+	idx := NewIndex()
+	for _, d := range docs {
+		idx.Put(d)
+	}
+
+	return idx, docs
+}
+
+func hitIDs(res Result) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		ids = append(ids, h.ID)
+	}
+
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx, docs := testIndex()
+
+	testCases := []struct {
+		name string
+		q    Query
+		want []primitive.ObjectID
+	}{
+		{
+			name: "Other word forms are found",
+			q:    Query{Text: "консультация"},
+			want: []primitive.ObjectID{docs[2].ID, docs[1].ID},
+		},
+		{
+			name: "Documents with all terms are ranked first",
+			q:    Query{Text: "консультации налоги"},
+			want: []primitive.ObjectID{docs[2].ID, docs[1].ID},
+		},
+		{
+			name: "Title outweighs body",
+			q:    Query{Text: "проверка"},
+			want: []primitive.ObjectID{docs[0].ID},
+		},
+		{
+			name: "Filter by type",
+			q:    Query{Text: "налог", Types: []string{TypeService}},
+			want: []primitive.ObjectID{docs[2].ID},
+		},
+		{
+			name: "Only deleted",
+			q:    Query{Text: "налог", Deleted: store.OnlyDeleted},
+			want: []primitive.ObjectID{docs[4].ID},
+		},
+		{
+			name: "Hidden are skipped",
+			q:    Query{Text: "черновик"},
+			want: []primitive.ObjectID{},
+		},
+		{
+			name: "Hidden are found when asked",
+			q:    Query{Text: "черновик", Hidden: true},
+			want: []primitive.ObjectID{docs[5].ID},
+		},
+		{
+			name: "Stop words only",
+			q:    Query{Text: "и по"},
+			want: []primitive.ObjectID{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, hitIDs(idx.Search(tc.q)))
+		})
+	}
+}
+
+func TestIndex_SearchPaginate(t *testing.T) {
+	idx, _ := testIndex()
+
+	all := idx.Search(Query{Text: "налог", Deleted: store.AnyDeleted})
+	assert.Equal(t, 3, all.Total)
+
+	page := idx.Search(Query{Text: "налог", Deleted: store.AnyDeleted, Limit: 1, Offset: 1})
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, hitIDs(all)[1:2], hitIDs(page))
+
+	past := idx.Search(Query{Text: "налог", Offset: 10})
+	assert.Empty(t, past.Hits)
+
+	negative := idx.Search(Query{Text: "налог", Deleted: store.AnyDeleted, Limit: 1, Offset: -1})
+	assert.Equal(t, hitIDs(all)[:1], hitIDs(negative))
+}
+
+func TestIndex_PutRemoveReplace(t *testing.T) {
+	idx, docs := testIndex()
+
+	changed := docs[3]
+	changed.Body = "Консультации по телефону"
+	idx.Put(changed)
+	assert.Equal(t, 6, idx.Len())
+	assert.Contains(t, hitIDs(idx.Search(Query{Text: "консультации"})), changed.ID)
+	assert.Empty(t, idx.Search(Query{Text: "адрес"}).Hits)
+
+	idx.Remove(TypeService, docs[2].ID)
+	assert.NotContains(t, hitIDs(idx.Search(Query{Text: "консультации"})), docs[2].ID)
+
+	idx.Replace(TypePost, nil)
+	assert.Equal(t, 1, idx.Len())
+}
+
+func TestHighlight(t *testing.T) {
+	terms := Terms("налоги")
+
+	assert.Equal(t, `Проверка <mark>налогов</mark> &amp; <mark>Налогам</mark> служба`,
+		string(Highlight("Проверка налогов & Налогам служба", terms, 0)))
+
+	long := "Раз два три четыре пять шесть семь восемь девять десять налогов одиннадцать двенадцать"
+	assert.Equal(t, `… десять <mark>налогов</mark> одиннадцать двенадцать`, string(Highlight(long, terms, 4)))
+	assert.Equal(t, `… четыре <mark>налогов</mark> пять шесть …`, string(Highlight("Раз два три четыре налогов пять шесть семь", terms, 4)))
+}
@@ -0,0 +1,216 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repositories embed wrapped ones and override only writes of indexed content
+
+// postRepository indexes posts after writes of store.IPostRepository
+type postRepository struct {
+	store.IPostRepository
+	store *Store
+}
+
+func (p postRepository) Create(ctx context.Context, post *models.Post) error {
+	defer p.store.written(ctx, TypePost, post.ID)
+	return p.IPostRepository.Create(ctx, post)
+}
+
+func (p postRepository) Update(ctx context.Context, post *models.Post) error {
+	defer p.store.written(ctx, TypePost, post.ID)
+	return p.IPostRepository.Update(ctx, post)
+}
+
+func (p postRepository) Patch(ctx context.Context, post *models.Post, fields []string) error {
+	defer p.store.written(ctx, TypePost, post.ID)
+	return p.IPostRepository.Patch(ctx, post, fields)
+}
+
+func (p postRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	err := p.IPostRepository.Delete(ctx, ID, deletedBy)
+	p.store.deleted(ctx, TypePost, ID, err)
+	return err
+}
+
+func (p postRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.written(ctx, TypePost, ID)
+	return p.IPostRepository.Restore(ctx, ID)
+}
+
+func (p postRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.written(ctx, TypePost, ID)
+	return p.IPostRepository.Purge(ctx, ID)
+}
+
+func (p postRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer p.store.writtenMany(ctx)
+	return p.IPostRepository.PurgeDeletedBefore(ctx, before)
+}
+
+func (p postRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	defer p.store.writtenMany(ctx)
+	return p.IPostRepository.ReassignCategory(ctx, from, to)
+}
+
+func (p postRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	defer p.store.writtenMany(ctx)
+	return p.IPostRepository.DeleteByCategory(ctx, categoryID, deletedBy)
+}
+
+// materialRepository indexes materials after writes of store.IMaterialRepository
+type materialRepository struct {
+	store.IMaterialRepository
+	store *Store
+}
+
+func (m materialRepository) Create(ctx context.Context, material *models.Material) error {
+	defer m.store.written(ctx, TypeMaterial, material.ID)
+	return m.IMaterialRepository.Create(ctx, material)
+}
+
+func (m materialRepository) Update(ctx context.Context, material *models.Material) error {
+	defer m.store.written(ctx, TypeMaterial, material.ID)
+	return m.IMaterialRepository.Update(ctx, material)
+}
+
+func (m materialRepository) Patch(ctx context.Context, material *models.Material, fields []string) error {
+	defer m.store.written(ctx, TypeMaterial, material.ID)
+	return m.IMaterialRepository.Patch(ctx, material, fields)
+}
+
+func (m materialRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	err := m.IMaterialRepository.Delete(ctx, ID, deletedBy)
+	m.store.deleted(ctx, TypeMaterial, ID, err)
+	return err
+}
+
+func (m materialRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer m.store.written(ctx, TypeMaterial, ID)
+	return m.IMaterialRepository.Restore(ctx, ID)
+}
+
+func (m materialRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer m.store.written(ctx, TypeMaterial, ID)
+	return m.IMaterialRepository.Purge(ctx, ID)
+}
+
+func (m materialRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer m.store.writtenMany(ctx)
+	return m.IMaterialRepository.PurgeDeletedBefore(ctx, before)
+}
+
+func (m materialRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	defer m.store.writtenMany(ctx)
+	return m.IMaterialRepository.ReassignCategory(ctx, from, to)
+}
+
+func (m materialRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	defer m.store.writtenMany(ctx)
+	return m.IMaterialRepository.DeleteByCategory(ctx, categoryID, deletedBy)
+}
+
+// serviceRepository indexes services after writes of store.IServiceRepository
+type serviceRepository struct {
+	store.IServiceRepository
+	store *Store
+}
+
+func (s serviceRepository) Create(ctx context.Context, service *models.Service) error {
+	defer s.store.written(ctx, TypeService, service.ID)
+	return s.IServiceRepository.Create(ctx, service)
+}
+
+func (s serviceRepository) Update(ctx context.Context, service *models.Service) error {
+	defer s.store.written(ctx, TypeService, service.ID)
+	return s.IServiceRepository.Update(ctx, service)
+}
+
+func (s serviceRepository) Patch(ctx context.Context, service *models.Service, fields []string) error {
+	defer s.store.written(ctx, TypeService, service.ID)
+	return s.IServiceRepository.Patch(ctx, service, fields)
+}
+
+func (s serviceRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	err := s.IServiceRepository.Delete(ctx, ID, deletedBy)
+	s.store.deleted(ctx, TypeService, ID, err)
+	return err
+}
+
+func (s serviceRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer s.store.written(ctx, TypeService, ID)
+	return s.IServiceRepository.Restore(ctx, ID)
+}
+
+func (s serviceRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer s.store.written(ctx, TypeService, ID)
+	return s.IServiceRepository.Purge(ctx, ID)
+}
+
+func (s serviceRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer s.store.writtenMany(ctx)
+	return s.IServiceRepository.PurgeDeletedBefore(ctx, before)
+}
+
+// pageRepository indexes pages after writes of store.IPageRepository
+type pageRepository struct {
+	store.IPageRepository
+	store *Store
+}
+
+func (p pageRepository) Create(ctx context.Context, page *models.Page) error {
+	defer p.store.written(ctx, TypePage, page.ID)
+	return p.IPageRepository.Create(ctx, page)
+}
+
+func (p pageRepository) Update(ctx context.Context, page *models.Page) error {
+	defer p.store.written(ctx, TypePage, page.ID)
+	return p.IPageRepository.Update(ctx, page)
+}
+
+func (p pageRepository) Patch(ctx context.Context, page *models.Page, fields []string) error {
+	defer p.store.written(ctx, TypePage, page.ID)
+	return p.IPageRepository.Patch(ctx, page, fields)
+}
+
+func (p pageRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	err := p.IPageRepository.Delete(ctx, ID, deletedBy)
+	p.store.deleted(ctx, TypePage, ID, err)
+	return err
+}
+
+func (p pageRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.written(ctx, TypePage, ID)
+	return p.IPageRepository.Restore(ctx, ID)
+}
+
+func (p pageRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.written(ctx, TypePage, ID)
+	return p.IPageRepository.Purge(ctx, ID)
+}
+
+func (p pageRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer p.store.writtenMany(ctx)
+	return p.IPageRepository.PurgeDeletedBefore(ctx, before)
+}
+
+// categoryRepository reindexes posts after writes of categories, because URLs of posts contain slugs of categories
+type categoryRepository struct {
+	store.ICategoryRepository
+	store *Store
+}
+
+func (c categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	defer c.store.writtenMany(ctx)
+	return c.ICategoryRepository.Update(ctx, category)
+}
+
+func (c categoryRepository) Patch(ctx context.Context, category *models.Category, fields []string) error {
+	defer c.store.writtenMany(ctx)
+	return c.ICategoryRepository.Patch(ctx, category, fields)
+}
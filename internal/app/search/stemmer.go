@@ -0,0 +1,154 @@
+package search
+
+// Russian stemmer implements Snowball algorithm for Russian
+// See https://snowballstem.org/algorithms/russian/stemmer.html
+// Words without Cyrillic vowels are returned as is
+
+var (
+	perfectiveGerund1 = runes("в", "вши", "вшись")
+	perfectiveGerund2 = runes("ив", "ивши", "ившись", "ыв", "ывши", "ывшись")
+	adjective         = runes("ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею")
+	participle1 = runes("ем", "нн", "вш", "ющ", "щ")
+	participle2 = runes("ивш", "ывш", "ующ")
+	reflexive   = runes("ся", "сь")
+	verb1       = runes("ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно")
+	verb2       = runes("ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю")
+	noun = runes("а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я")
+	superlative   = runes("ейш", "ейше")
+	derivational  = runes("ост", "ость")
+	russianVowels = map[rune]bool{'а': true, 'е': true, 'и': true, 'о': true, 'у': true, 'ы': true, 'э': true, 'ю': true, 'я': true}
+)
+
+func runes(endings ...string) [][]rune {
+	r := make([][]rune, len(endings))
+	for i, e := range endings {
+		r[i] = []rune(e)
+	}
+
+	return r
+}
+
+// Stem returns stem of lower case Russian word
+func Stem(word string) string {
+	w := []rune(word)
+	rv, r2 := regions(w)
+
+	if rv == len(w) {
+		return word
+	}
+
+	// Step 1
+	if n, g := longest(w, rv, perfectiveGerund1, perfectiveGerund2); n > 0 && (g == 1 || afterAOrYa(w, n, rv)) {
+		w = w[:len(w)-n]
+	} else {
+		if n, _ := longest(w, rv, reflexive); n > 0 {
+			w = w[:len(w)-n]
+		}
+
+		if n, _ := longest(w, rv, adjective); n > 0 {
+			w = w[:len(w)-n]
+
+			if n, g := longest(w, rv, participle1, participle2); n > 0 && (g == 1 || afterAOrYa(w, n, rv)) {
+				w = w[:len(w)-n]
+			}
+		} else if n, g := longest(w, rv, verb1, verb2); n > 0 && (g == 1 || afterAOrYa(w, n, rv)) {
+			w = w[:len(w)-n]
+		} else if n, _ := longest(w, rv, noun); n > 0 {
+			w = w[:len(w)-n]
+		}
+	}
+
+	// Step 2
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Step 3
+	if n, _ := longest(w, r2, derivational); n > 0 {
+		w = w[:len(w)-n]
+	}
+
+	// Step 4
+	switch n, _ := longest(w, rv, superlative); {
+	case n > 0:
+		w = w[:len(w)-n]
+		if doubleN(w, rv) {
+			w = w[:len(w)-1]
+		}
+	case doubleN(w, rv):
+		w = w[:len(w)-1]
+	case len(w) > rv && w[len(w)-1] == 'ь':
+		w = w[:len(w)-1]
+	}
+
+	return string(w)
+}
+
+// regions returns starts of RV and R2 regions of word, R2 is R1 of R1
+func regions(w []rune) (rv, r2 int) {
+	rv = len(w)
+
+	for i, r := range w {
+		if russianVowels[r] {
+			rv = i + 1
+			break
+		}
+	}
+
+	return rv, afterVowelConsonant(w, afterVowelConsonant(w, 0))
+}
+
+// afterVowelConsonant returns position after the first non-vowel following a vowel from start
+func afterVowelConsonant(w []rune, start int) int {
+	for i := start + 1; i < len(w); i++ {
+		if !russianVowels[w[i]] && russianVowels[w[i-1]] {
+			return i + 1
+		}
+	}
+
+	return len(w)
+}
+
+// longest returns length of the longest ending from groups which lies in region starting at limit
+// and index of its group
+func longest(w []rune, limit int, groups ...[][]rune) (int, int) {
+	n, group := 0, 0
+
+	for g, endings := range groups {
+		for _, e := range endings {
+			if len(e) > n && len(w)-len(e) >= limit && hasSuffix(w, e) {
+				n, group = len(e), g
+			}
+		}
+	}
+
+	return n, group
+}
+
+func hasSuffix(w, suffix []rune) bool {
+	if len(suffix) > len(w) {
+		return false
+	}
+
+	for i := range suffix {
+		if w[len(w)-len(suffix)+i] != suffix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// afterAOrYa tells whether ending of length n follows а or я inside region
+func afterAOrYa(w []rune, n, limit int) bool {
+	i := len(w) - n - 1
+	return i >= limit && (w[i] == 'а' || w[i] == 'я')
+}
+
+// doubleN tells whether word ends with нн inside region
+func doubleN(w []rune, limit int) bool {
+	return len(w)-2 >= limit && w[len(w)-1] == 'н' && w[len(w)-2] == 'н'
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	testCases := []struct {
+		word string
+		want string
+	}{
+		{word: "книги", want: "книг"},
+		{word: "книгами", want: "книг"},
+		{word: "красивая", want: "красив"},
+		{word: "важнейшие", want: "важн"},
+		{word: "консультации", want: "консультац"},
+		{word: "консультация", want: "консультац"},
+		{word: "бухгалтерский", want: "бухгалтерск"},
+		{word: "бухгалтерские", want: "бухгалтерск"},
+		{word: "налоги", want: "налог"},
+		{word: "налогов", want: "налог"},
+		{word: "отчетность", want: "отчетн"},
+		{word: "прочитавшись", want: "прочита"},
+		{word: "учатся", want: "учат"},
+		{word: "tax", want: "tax"},
+		{word: "2021", want: "2021"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.word, func(t *testing.T) {
+			assert.Equal(t, tc.want, Stem(tc.word))
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"налогов", "отчетн", "2021"}, Terms("Налоговая отчётность и 2021"))
+	assert.Empty(t, Terms("и в на"))
+}
@@ -0,0 +1,293 @@
+package search
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-pkgz/lgr"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store implements store.Storer on top of another store and keeps index in sync with its writes
+// Write of one document updates only its entry, also when it is made in transaction, writes of many documents
+// request Rebuild, which is run by background job, so requests never reload whole content
+// Failed indexing is only logged, because document itself is already saved,
+// periodic Rebuild repairs index and picks up writes of other instances
+type Store struct {
+	store.Storer
+	index   *Index
+	logger  lgr.L
+	pending chan struct{} // Requested rebuild, requests made before it is run are merged
+}
+
+// NewStore returns store which updates idx after writes to s
+func NewStore(s store.Storer, idx *Index, logger lgr.L) *Store {
+	return &Store{
+		Storer:  s,
+		index:   idx,
+		logger:  logger,
+		pending: make(chan struct{}, 1),
+	}
+}
+
+// Pending receives when writes need Rebuild of index, it is read by rebuilder job
+func (s *Store) Pending() <-chan struct{} {
+	return s.pending
+}
+
+// requestRebuild marks index as stale, request is dropped when one is pending already
+func (s *Store) requestRebuild() {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+// Rebuild reads all searchable content of store into index
+func (s *Store) Rebuild(ctx context.Context) error {
+	for _, docType := range []string{TypePost, TypePage, TypeMaterial, TypeService} {
+		if err := s.reindexType(ctx, docType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Search runs query against index
+func (s *Store) Search(q Query) Result {
+	return s.index.Search(q)
+}
+
+// txKey holds writes of transaction started through Store
+type txKey struct{}
+
+// txWrites are writes made in transaction, they are indexed after commit
+type txWrites struct {
+	mu      sync.Mutex
+	written []docRef // Documents to reindex
+	deleted []docRef // Documents moved to trash
+	many    bool     // Write of many documents needs rebuild
+}
+
+// docRef identifies indexed document
+type docRef struct {
+	docType string
+	ID      primitive.ObjectID
+}
+
+// WithTransaction runs fn in transaction of wrapped store and indexes its writes after commit
+// Writes inside transaction are not indexed one by one, because they may be rolled back
+// Failed transaction requests Rebuild, because not every store rolls back its writes
+func (s *Store) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return s.Storer.WithTransaction(ctx, fn)
+	}
+
+	tx := &txWrites{}
+	err := s.Storer.WithTransaction(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+
+	if err != nil || tx.many {
+		s.requestRebuild()
+		return err
+	}
+
+	for _, ref := range tx.written {
+		s.logError(s.reindex(ctx, ref.docType, ref.ID))
+	}
+
+	for _, ref := range tx.deleted {
+		s.index.MarkDeleted(ref.docType, ref.ID, true)
+	}
+
+	return nil
+}
+
+// transaction returns writes of transaction which ctx belongs to, nil outside of transaction
+func transaction(ctx context.Context) *txWrites {
+	tx, _ := ctx.Value(txKey{}).(*txWrites)
+	return tx
+}
+
+func (s *Store) logError(err error) {
+	if err != nil {
+		s.logger.Logf("[ERROR] During update of search index: %v\n", err)
+	}
+}
+
+// allContent selects live and deleted documents, deleted ones are searchable by editors
+var allContent = store.ListQuery{Deleted: store.AnyDeleted}
+
+// reindexType replaces all documents of type in index
+func (s *Store) reindexType(ctx context.Context, docType string) error {
+	var docs []Document
+
+	switch docType {
+	case TypePost:
+		posts, err := s.Storer.Posts().List(ctx, allContent)
+		if err != nil {
+			return err
+		}
+
+		categories, err := s.Storer.Categories().List(ctx, allContent)
+		if err != nil {
+			return err
+		}
+
+		slugs := make(map[primitive.ObjectID]string, len(categories))
+		for _, c := range categories {
+			slugs[c.ID] = c.Slug
+		}
+
+		for _, p := range posts {
+			docs = append(docs, PostDocument(p, slugs[p.CategoryID]))
+		}
+	case TypePage:
+		pages, err := s.Storer.Pages().List(ctx, allContent)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range pages {
+			docs = append(docs, PageDocument(p))
+		}
+	case TypeMaterial:
+		materials, err := s.Storer.Materials().List(ctx, allContent)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range materials {
+			docs = append(docs, MaterialDocument(m))
+		}
+	case TypeService:
+		services, err := s.Storer.Services().List(ctx, allContent)
+		if err != nil {
+			return err
+		}
+
+		for _, sv := range services {
+			docs = append(docs, ServiceDocument(sv))
+		}
+	}
+
+	s.index.Replace(docType, docs)
+
+	return nil
+}
+
+// reindex puts current state of document into index or removes it when document is gone
+func (s *Store) reindex(ctx context.Context, docType string, ID primitive.ObjectID) error {
+	var (
+		doc Document
+		err error
+	)
+
+	switch docType {
+	case TypePost:
+		var post *models.Post
+		if post, err = s.Storer.Posts().FindByID(ctx, ID); err == nil {
+			// Post of purged category is still indexed, though it has no URL
+			var slug string
+			if category, err := s.Storer.Categories().FindByID(ctx, post.CategoryID); err == nil {
+				slug = category.Slug
+			}
+
+			doc = PostDocument(post, slug)
+		}
+	case TypePage:
+		var page *models.Page
+		if page, err = s.Storer.Pages().FindByID(ctx, ID); err == nil {
+			doc = PageDocument(page)
+		}
+	case TypeMaterial:
+		var material *models.Material
+		if material, err = s.Storer.Materials().FindByID(ctx, ID); err == nil {
+			doc = MaterialDocument(material)
+		}
+	case TypeService:
+		var service *models.Service
+		if service, err = s.Storer.Services().FindByID(ctx, ID); err == nil {
+			doc = ServiceDocument(service)
+		}
+	}
+
+	switch err {
+	case nil:
+		s.index.Put(doc)
+	case store.ErrNotFound:
+		s.index.Remove(docType, ID)
+	default:
+		return err
+	}
+
+	return nil
+}
+
+// written updates index after write of one document, which failed or not
+func (s *Store) written(ctx context.Context, docType string, ID primitive.ObjectID) {
+	if tx := transaction(ctx); tx != nil {
+		tx.mu.Lock()
+		tx.written = append(tx.written, docRef{docType, ID})
+		tx.mu.Unlock()
+		return
+	}
+
+	s.logError(s.reindex(ctx, docType, ID))
+}
+
+// deleted moves document to trash in index after successful Delete
+// Document is not reindexed, because FindByID does not return documents in trash
+func (s *Store) deleted(ctx context.Context, docType string, ID primitive.ObjectID, err error) {
+	if err != nil {
+		return
+	}
+
+	if tx := transaction(ctx); tx != nil {
+		tx.mu.Lock()
+		tx.deleted = append(tx.deleted, docRef{docType, ID})
+		tx.mu.Unlock()
+		return
+	}
+
+	s.index.MarkDeleted(docType, ID, true)
+}
+
+// writtenMany requests Rebuild after write of many documents
+func (s *Store) writtenMany(ctx context.Context) {
+	if tx := transaction(ctx); tx != nil {
+		tx.mu.Lock()
+		tx.many = true
+		tx.mu.Unlock()
+		return
+	}
+
+	s.requestRebuild()
+}
+
+/*
+ * Implement Storer interface
+ */
+func (s *Store) Posts() store.IPostRepository {
+	return postRepository{IPostRepository: s.Storer.Posts(), store: s}
+}
+
+func (s *Store) Categories() store.ICategoryRepository {
+	return categoryRepository{ICategoryRepository: s.Storer.Categories(), store: s}
+}
+
+func (s *Store) Materials() store.IMaterialRepository {
+	return materialRepository{IMaterialRepository: s.Storer.Materials(), store: s}
+}
+
+func (s *Store) Services() store.IServiceRepository {
+	return serviceRepository{IServiceRepository: s.Storer.Services(), store: s}
+}
+
+func (s *Store) Pages() store.IPageRepository {
+	return pageRepository{IPageRepository: s.Storer.Pages(), store: s}
+}
@@ -0,0 +1,138 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-pkgz/lgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testPage(title, url string) *models.Page {
+	return &models.Page{
+		ID:       primitive.NewObjectID(),
+		Title:    title,
+		Subtitle: "Подзаголовок страницы достаточной длины для валидации",
+		MetaDesc: "Описание страницы для поисковых систем достаточной длины",
+		URL:      url,
+		Status:   models.StatusPublished,
+	}
+}
+
+func TestStore_Written(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), NewIndex(), lgr.NoOp)
+	page := testPage("Бухгалтерские услуги", "/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+	assert.Equal(t, 1, s.Search(Query{Text: "услуга"}).Total)
+
+	page.Title = "Налоговые консультации"
+	assert.NoError(t, s.Pages().Update(ctx, page))
+	assert.Equal(t, 0, s.Search(Query{Text: "услуга"}).Total)
+	assert.Equal(t, 1, s.Search(Query{Text: "консультация"}).Total)
+
+	// Deleted documents are found only when asked
+	assert.NoError(t, s.Pages().Delete(ctx, page.ID, "admin"))
+	assert.Equal(t, 0, s.Search(Query{Text: "консультация"}).Total)
+	assert.Equal(t, 1, s.Search(Query{Text: "консультация", Deleted: store.OnlyDeleted}).Total)
+
+	assert.NoError(t, s.Pages().Purge(ctx, page.ID))
+	assert.Equal(t, 0, s.Search(Query{Text: "консультация", Deleted: store.AnyDeleted}).Total)
+}
+
+func TestStore_WithTransaction(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), NewIndex(), lgr.NoOp)
+
+	err := s.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.Pages().Create(ctx, testPage("Бухгалтерские услуги", "/about")); err != nil {
+			return err
+		}
+
+		// Writes inside transaction are indexed only after commit
+		assert.Equal(t, 0, s.Search(Query{Text: "услуга"}).Total)
+
+		return s.Pages().Create(ctx, testPage("Юридические услуги", "/services"))
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, s.Search(Query{Text: "услуга"}).Total)
+
+	// Failed transaction and write of many documents request rebuild from background job
+	fail := errors.New("failed")
+	err = s.WithTransaction(ctx, func(ctx context.Context) error {
+		assert.NoError(t, s.Pages().Create(ctx, testPage("Аудит", "/audit")))
+		return fail
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 0, s.Search(Query{Text: "аудит"}).Total)
+	assertPending(t, s)
+
+	err = s.WithTransaction(ctx, func(ctx context.Context) error {
+		_, err := s.Pages().PurgeDeletedBefore(ctx, time.Now())
+		return err
+	})
+	assert.NoError(t, err)
+	assertPending(t, s)
+}
+
+func TestStore_WrittenMany(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), NewIndex(), lgr.NoOp)
+	page := testPage("Бухгалтерские услуги", "/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+	assert.NoError(t, s.Pages().Delete(ctx, page.ID, "admin"))
+
+	// Write of one document does not request rebuild
+	select {
+	case <-s.Pending():
+		t.Fatal("rebuild must not be requested")
+	default:
+	}
+	assert.Equal(t, 1, s.Search(Query{Text: "услуга", Deleted: store.OnlyDeleted}).Total)
+
+	// Requests made before rebuild are merged
+	_, err := s.Pages().PurgeDeletedBefore(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = s.Pages().PurgeDeletedBefore(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assertPending(t, s)
+
+	select {
+	case <-s.Pending():
+		t.Fatal("requests must be merged")
+	default:
+	}
+}
+
+func assertPending(t *testing.T, s *Store) {
+	t.Helper()
+
+	select {
+	case <-s.Pending():
+	default:
+		t.Fatal("rebuild must be requested")
+	}
+}
+
+func TestStore_Rebuild(t *testing.T) {
+	ctx := context.Background()
+	ms := memstore.NewStore()
+
+	// Content written past the decorator is picked up by rebuild
+	assert.NoError(t, ms.Pages().Create(ctx, testPage("Бухгалтерские услуги", "/about")))
+
+	s := NewStore(ms, NewIndex(), lgr.NoOp)
+	assert.Equal(t, 0, s.Search(Query{Text: "услуга"}).Total)
+
+	assert.NoError(t, s.Rebuild(ctx))
+	assert.Equal(t, 1, s.Search(Query{Text: "услуга"}).Total)
+}
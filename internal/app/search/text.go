@@ -0,0 +1,101 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+)
+
+// stopWords are too frequent to be searched for
+var stopWords = map[string]bool{
+	"а": true, "в": true, "во": true, "и": true, "к": true, "ко": true, "на": true, "не": true, "но": true,
+	"о": true, "об": true, "от": true, "по": true, "с": true, "со": true, "у": true, "за": true, "из": true,
+	"до": true, "для": true, "что": true, "как": true, "это": true, "или": true, "же": true, "ли": true,
+}
+
+// token is word of text with its byte offsets
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text into lower case words of letters and digits, ё is replaced by е
+func tokenize(text string) []token {
+	var (
+		tokens []token
+		start  = -1
+	)
+
+	flush := func(end int) {
+		if start >= 0 {
+			word := strings.ReplaceAll(strings.ToLower(text[start:end]), "ё", "е")
+			tokens = append(tokens, token{word: word, start: start, end: end})
+			start = -1
+		}
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		flush(i)
+	}
+
+	flush(len(text))
+
+	return tokens
+}
+
+// term returns index term of lower case word or empty string for stop word
+func term(word string) string {
+	if stopWords[word] {
+		return ""
+	}
+
+	return Stem(word)
+}
+
+// Terms returns index terms of text in order of appearance
+func Terms(text string) []string {
+	tokens := tokenize(text)
+	terms := make([]string, 0, len(tokens))
+
+	for _, t := range tokens {
+		if s := term(t.word); s != "" {
+			terms = append(terms, s)
+		}
+	}
+
+	return terms
+}
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips inline markup of block text
+func plainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(tagRe.ReplaceAllString(s, " ")))
+}
+
+// blocksText returns plain text of all blocks separated by new lines
+func blocksText(blocks []models.Block) string {
+	parts := make([]string, 0, len(blocks))
+
+	for _, b := range blocks {
+		if b.Data == nil {
+			continue
+		}
+
+		if text := plainText(b.Data.Text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,150 @@
+// Package slug makes URL slugs from titles
+// Cyrillic is transliterated by selectable standard, the result contains only [a-z0-9_-]
+// which is allowed by router
+package slug
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxLength is used when maximum length of slug is not set
+const DefaultMaxLength = 100
+
+// ErrUnknownStandard is returned by ParseStandard for unsupported name
+var ErrUnknownStandard = errors.New("Slug standard must be one of legacy, gost or iso9")
+
+// Standard is transliteration scheme of Cyrillic letters
+type Standard int
+
+const (
+	Legacy  Standard = iota // Scheme used by site from the beginning, words are separated by _
+	GOST779                 // GOST 7.79-2000 system B without apostrophes
+	ISO9                    // ISO 9:1995 with diacritics dropped
+)
+
+// ParseStandard returns standard by its name from config
+func ParseStandard(name string) (Standard, error) {
+	switch name {
+	case "", "legacy":
+		return Legacy, nil
+	case "gost":
+		return GOST779, nil
+	case "iso9":
+		return ISO9, nil
+	}
+
+	return Legacy, ErrUnknownStandard
+}
+
+// Generator makes slugs by chosen standard
+type Generator struct {
+	standard  Standard
+	maxLength int
+}
+
+// New returns generator of slugs not longer than maxLength, zero maxLength means DefaultMaxLength
+func New(standard Standard, maxLength int) *Generator {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	return &Generator{
+		standard:  standard,
+		maxLength: maxLength,
+	}
+}
+
+// Make returns slug of s
+func (g *Generator) Make(s string) string {
+	return Make(s, g.standard, g.maxLength)
+}
+
+// Unique returns slug of s which is not taken yet
+// Taken slug gets suffix 2, 3 and so on after separator of standard, e.g. novosti-2 or novosti_2,
+// base is shortened to keep suffix within maximum length
+func (g *Generator) Unique(ctx context.Context, s string, taken func(ctx context.Context, slug string) (bool, error)) (string, error) {
+	base := g.Make(s)
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			suffix := string(separator(g.standard)) + strconv.Itoa(n)
+			candidate = trim(truncate(base, g.maxLength-len(suffix)), separator(g.standard)) + suffix
+		}
+
+		exists, err := taken(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return candidate, nil
+		}
+
+		if err = ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+}
+
+// Make returns slug of s transliterated by standard and cut to maxLength
+// Zero maxLength means no limit
+func Make(s string, standard Standard, maxLength int) string {
+	sep := separator(standard)
+	s = replacer(standard).Replace(strings.ToLower(s))
+
+	var (
+		sb      strings.Builder
+		pending bool // Separator is written only before next allowed character
+	)
+
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if pending && sb.Len() > 0 {
+				sb.WriteByte(sep)
+			}
+			pending = false
+			sb.WriteRune(r)
+		case isApostrophe(r):
+			// Apostrophes and quotes inside words are dropped, e.g. in Ukrainian words
+		default:
+			pending = true
+		}
+	}
+
+	return trim(truncate(sb.String(), maxLength), sep)
+}
+
+func separator(standard Standard) byte {
+	if standard == Legacy {
+		return '_'
+	}
+
+	return '-'
+}
+
+func isApostrophe(r rune) bool {
+	switch r {
+	case '\'', '`', '"', '’', 'ʼ', '‘', '«', '»', '„', '“', '”':
+		return true
+	}
+
+	return false
+}
+
+// truncate cuts ASCII string to n bytes, non-positive n means no limit
+func truncate(s string, n int) string {
+	if n > 0 && len(s) > n {
+		return s[:n]
+	}
+
+	return s
+}
+
+func trim(s string, sep byte) string {
+	return strings.Trim(s, string(sep))
+}
@@ -0,0 +1,159 @@
+package slug
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		standard  Standard
+		maxLength int
+		want      string
+	}{
+		{
+			name:     "Legacy word",
+			input:    "Первая",
+			standard: Legacy,
+			want:     "pervaya",
+		},
+		{
+			name:     "Legacy words",
+			input:    "ЛУЧШИЙ ОТрывок",
+			standard: Legacy,
+			want:     "luchshiy_otryvok",
+		},
+		{
+			name:     "Empty",
+			input:    "",
+			standard: Legacy,
+			want:     "",
+		},
+		{
+			name:     "Punctuation and quotes",
+			input:    "«Налоги» в 2021-м году: что нового?!",
+			standard: Legacy,
+			want:     "nalogi_v_2021_m_godu_chto_novogo",
+		},
+		{
+			name:     "Digits with symbols",
+			input:    "НДС 20% — с 01.01.2019",
+			standard: GOST779,
+			want:     "nds-20-s-01-01-2019",
+		},
+		{
+			name:     "Ukrainian letters",
+			input:    "Ґрунт їжака, п’єса і ще",
+			standard: GOST779,
+			want:     "grunt-yizhaka-pyesa-i-shhe",
+		},
+		{
+			name:     "GOST ts before vowels",
+			input:    "Царь цирка, лицей",
+			standard: GOST779,
+			want:     "czar-cirka-licej",
+		},
+		{
+			name:     "GOST letters",
+			input:    "Хорошая щука съела ёжика",
+			standard: GOST779,
+			want:     "xoroshaya-shhuka-sela-yozhika",
+		},
+		{
+			name:     "ISO 9 letters",
+			input:    "Хорошая щука съела ёжика",
+			standard: ISO9,
+			want:     "horosaa-suka-sela-ezika",
+		},
+		{
+			name:     "Latin is kept",
+			input:    "  Go & MongoDB  ",
+			standard: ISO9,
+			want:     "go-mongodb",
+		},
+		{
+			name:      "Max length",
+			input:     "Бухгалтерские услуги для бизнеса",
+			standard:  GOST779,
+			maxLength: 24,
+			want:      "buxgalterskie-uslugi-dly",
+		},
+		{
+			name:      "Max length at separator",
+			input:     "Бухгалтерские услуги для бизнеса",
+			standard:  GOST779,
+			maxLength: 21,
+			want:      "buxgalterskie-uslugi",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Make(tc.input, tc.standard, tc.maxLength))
+		})
+	}
+}
+
+func TestParseStandard(t *testing.T) {
+	for name, want := range map[string]Standard{"": Legacy, "legacy": Legacy, "gost": GOST779, "iso9": ISO9} {
+		std, err := ParseStandard(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, std)
+	}
+
+	_, err := ParseStandard("bgn")
+	assert.Equal(t, ErrUnknownStandard, err)
+}
+
+func TestGenerator_Unique(t *testing.T) {
+	ctx := context.Background()
+
+	taken := func(slugs ...string) func(context.Context, string) (bool, error) {
+		return func(ctx context.Context, slug string) (bool, error) {
+			for _, s := range slugs {
+				if s == slug {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		}
+	}
+
+	g := New(GOST779, 0)
+
+	s, err := g.Unique(ctx, "Новости", taken())
+	assert.NoError(t, err)
+	assert.Equal(t, "novosti", s)
+
+	s, err = g.Unique(ctx, "Новости", taken("novosti", "novosti-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "novosti-3", s)
+
+	// Suffix fits into maximum length
+	g = New(GOST779, 10)
+	s, err = g.Unique(ctx, "Новости компании", taken("novosti-ko"))
+	assert.NoError(t, err)
+	assert.Equal(t, "novosti-2", s)
+	assert.LessOrEqual(t, len(s), 10)
+
+	// Suffix is separated like words of standard
+	s, err = New(Legacy, 0).Unique(ctx, "Моя статья", taken("moya_statya"))
+	assert.NoError(t, err)
+	assert.Equal(t, "moya_statya_2", s)
+
+	fail := errors.New("lookup failed")
+	_, err = g.Unique(ctx, "Новости", func(context.Context, string) (bool, error) { return false, fail })
+	assert.Equal(t, fail, err)
+
+	// Result is always allowed by router
+	s = New(Legacy, 0).Make(strings.Repeat("Ї'ж!? ", 50))
+	assert.Regexp(t, `^[a-z0-9_-]+$`, s)
+	assert.LessOrEqual(t, len(s), DefaultMaxLength)
+}
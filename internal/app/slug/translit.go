@@ -0,0 +1,156 @@
+package slug
+
+import "strings"
+
+// Replacers get lower case text, pairs listed earlier take priority
+
+var legacy = strings.NewReplacer(
+	"а", "a",
+	"б", "b",
+	"в", "v",
+	"г", "g",
+	"ґ", "g",
+	"д", "d",
+	"е", "e",
+	"ё", "yo",
+	"є", "ye",
+	"ж", "zh",
+	"з", "z",
+	"и", "i",
+	"і", "i",
+	"ї", "yi",
+	"й", "y",
+	"к", "k",
+	"л", "l",
+	"м", "m",
+	"н", "n",
+	"о", "o",
+	"п", "p",
+	"р", "r",
+	"с", "s",
+	"т", "t",
+	"у", "u",
+	"ў", "u",
+	"ф", "f",
+	"х", "kh",
+	"ц", "ts",
+	"ч", "ch",
+	"ш", "sh",
+	"щ", "sch",
+	"ъе", "ye",
+	"ъ", "",
+	"ый", "iy",
+	"ий", "iy",
+	"ы", "y",
+	"ь", "",
+	"э", "e",
+	"ю", "yu",
+	"я", "ya",
+)
+
+// gost779 is system B of GOST 7.79-2000, where ц is c before i, e, y and j and cz otherwise
+// Apostrophes of ъ, ь and of ы, э, ґ, ў are dropped, because they are not allowed in slugs
+var gost779 = strings.NewReplacer(
+	"це", "ce",
+	"цё", "cyo",
+	"цє", "cye",
+	"ци", "ci",
+	"ці", "ci",
+	"цї", "cyi",
+	"цй", "cj",
+	"цы", "cy",
+	"цэ", "ce",
+	"цю", "cyu",
+	"ця", "cya",
+	"ц", "cz",
+	"а", "a",
+	"б", "b",
+	"в", "v",
+	"г", "g",
+	"ґ", "g",
+	"д", "d",
+	"е", "e",
+	"ё", "yo",
+	"є", "ye",
+	"ж", "zh",
+	"з", "z",
+	"и", "i",
+	"і", "i",
+	"ї", "yi",
+	"й", "j",
+	"к", "k",
+	"л", "l",
+	"м", "m",
+	"н", "n",
+	"о", "o",
+	"п", "p",
+	"р", "r",
+	"с", "s",
+	"т", "t",
+	"у", "u",
+	"ў", "u",
+	"ф", "f",
+	"х", "x",
+	"ч", "ch",
+	"ш", "sh",
+	"щ", "shh",
+	"ъ", "",
+	"ы", "y",
+	"ь", "",
+	"э", "e",
+	"ю", "yu",
+	"я", "ya",
+)
+
+// iso9 is ISO 9:1995 where each letter has single Latin counterpart, diacritics are dropped
+var iso9 = strings.NewReplacer(
+	"а", "a",
+	"б", "b",
+	"в", "v",
+	"г", "g",
+	"ґ", "g",
+	"д", "d",
+	"е", "e",
+	"ё", "e",
+	"є", "e",
+	"ж", "z",
+	"з", "z",
+	"и", "i",
+	"і", "i",
+	"ї", "i",
+	"й", "j",
+	"к", "k",
+	"л", "l",
+	"м", "m",
+	"н", "n",
+	"о", "o",
+	"п", "p",
+	"р", "r",
+	"с", "s",
+	"т", "t",
+	"у", "u",
+	"ў", "u",
+	"ф", "f",
+	"х", "h",
+	"ц", "c",
+	"ч", "c",
+	"ш", "s",
+	"щ", "s",
+	"ъ", "",
+	"ы", "y",
+	"ь", "",
+	"э", "e",
+	"ю", "u",
+	"я", "a",
+)
+
+func replacer(standard Standard) *strings.Replacer {
+	switch standard {
+	case GOST779:
+		return gost779
+	case ISO9:
+		return iso9
+	}
+
+	return legacy
+}
@@ -0,0 +1,144 @@
+package cachestore
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats describes usage of cache since its creation
+type Stats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"` // Entries removed because cache was full
+	Expired   int64 `json:"expired"`   // Entries removed because their TTL passed
+	Size      int   `json:"size"`
+	Capacity  int   `json:"capacity"`
+}
+
+// entry is cached result of one read
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// cache is LRU cache with TTL of entries
+// Keys are prefixed by namespace, so all results of one repository can be dropped at once
+type cache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List // Front is the most recently used entry
+	entries  map[string]*list.Element
+	// generations are incremented by invalidation, result read before it is not cached
+	generations map[string]uint64
+	stats       Stats
+	now         func() time.Time
+}
+
+func newCache(capacity int, ttl time.Duration) *cache {
+	return &cache{
+		capacity:    capacity,
+		ttl:         ttl,
+		order:       list.New(),
+		entries:     make(map[string]*list.Element),
+		generations: make(map[string]uint64),
+		now:         time.Now,
+	}
+}
+
+// get returns live entry and marks it as recently used
+func (c *cache) get(namespace, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[namespace+":"+key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if c.ttl > 0 && !c.now().Before(e.expires) {
+		c.remove(el)
+		c.stats.Expired++
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+
+	return e.value, true
+}
+
+// generation returns current generation of namespace
+func (c *cache) generation(namespace string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generations[namespace]
+}
+
+// set stores value only if namespace was not invalidated since gen was read
+// Least recently used entry is evicted when cache is full
+func (c *cache) set(namespace, key string, value interface{}, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[namespace] != gen {
+		return
+	}
+
+	key = namespace + ":" + key
+	expires := c.now().Add(c.ttl)
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &entry{key: key, value: value, expires: expires}
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// invalidate drops all entries of namespaces
+func (c *cache) invalidate(namespaces ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ns := range namespaces {
+		c.generations[ns]++
+
+		prefix := ns + ":"
+		for key, el := range c.entries {
+			if strings.HasPrefix(key, prefix) {
+				c.remove(el)
+			}
+		}
+	}
+}
+
+func (c *cache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
+
+// snapshot returns copy of statistics
+func (c *cache) snapshot() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats
+	s.Size = c.order.Len()
+	s.Capacity = c.capacity
+
+	return s
+}
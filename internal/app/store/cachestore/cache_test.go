@@ -0,0 +1,69 @@
+package cachestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Evict(t *testing.T) {
+	c := newCache(2, 0)
+
+	c.set("posts", "a", 1, 0)
+	c.set("posts", "b", 2, 0)
+
+	// a becomes recently used, so b is evicted
+	_, ok := c.get("posts", "a")
+	assert.True(t, ok)
+	c.set("posts", "c", 3, 0)
+
+	_, ok = c.get("posts", "b")
+	assert.False(t, ok)
+
+	v, ok := c.get("posts", "c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	stats := c.snapshot()
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Evictions: 1, Size: 2, Capacity: 2}, stats)
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Now()
+	c := newCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.set("pages", "/about", "О нас", 0)
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.get("pages", "/about")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.get("pages", "/about")
+	assert.False(t, ok)
+	assert.Equal(t, int64(1), c.snapshot().Expired)
+	assert.Equal(t, 0, c.snapshot().Size)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	c := newCache(10, 0)
+
+	c.set("posts", "a", 1, 0)
+	c.set("pages", "a", 2, 0)
+
+	gen := c.generation("posts")
+	c.invalidate("posts")
+
+	_, ok := c.get("posts", "a")
+	assert.False(t, ok)
+
+	_, ok = c.get("pages", "a")
+	assert.True(t, ok)
+
+	// Result read before invalidation is stale
+	c.set("posts", "a", 1, gen)
+	_, ok = c.get("posts", "a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,791 @@
+package cachestore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cached documents are shared, so each read returns copy of them
+// Slices and blocks are copied too, so changes of returned document never reach cache
+// Repositories embed wrapped ones, so methods which are not overridden bypass cache
+
+func copyIDs(IDs []primitive.ObjectID) []primitive.ObjectID {
+	if IDs == nil {
+		return nil
+	}
+
+	return append([]primitive.ObjectID{}, IDs...)
+}
+
+func copyBlocks(blocks []models.Block) []models.Block {
+	if blocks == nil {
+		return nil
+	}
+
+	c := make([]models.Block, len(blocks))
+	for i, block := range blocks {
+		c[i] = block
+
+		if block.Data != nil {
+			data := *block.Data
+			if data.File != nil {
+				file := *data.File
+				data.File = &file
+			}
+			data.Items = copyItems(data.Items)
+			if data.Content != nil {
+				data.Content = make([][]string, len(block.Data.Content))
+				for j, row := range block.Data.Content {
+					data.Content[j] = append([]string(nil), row...)
+				}
+			}
+			c[i].Data = &data
+		}
+
+		if block.Tunes != nil {
+			tunes := *block.Tunes
+			c[i].Tunes = &tunes
+		}
+	}
+
+	return c
+}
+
+func copyItems(items []models.ListItem) []models.ListItem {
+	if items == nil {
+		return nil
+	}
+
+	c := make([]models.ListItem, len(items))
+	for i, item := range items {
+		c[i] = item
+		c[i].Items = copyItems(item.Items)
+	}
+
+	return c
+}
+
+// postRepository caches reads of store.IPostRepository
+type postRepository struct {
+	store.IPostRepository
+	store *CacheStore
+}
+
+func copyPost(post *models.Post) *models.Post {
+	c := *post
+	c.PageData = copyBlocks(post.PageData)
+	c.TagIDs = copyIDs(post.TagIDs)
+	return &c
+}
+
+func copyPosts(posts []*models.Post) []*models.Post {
+	c := make([]*models.Post, len(posts))
+	for i, post := range posts {
+		c[i] = copyPost(post)
+	}
+
+	return c
+}
+
+func (p postRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Post, error) {
+	v, err := p.store.load(ctx, postsNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return p.IPostRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPost(v.(*models.Post)), nil
+}
+
+func (p postRepository) FindBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	v, err := p.store.load(ctx, postsNS, "slug:"+slug, func() (interface{}, error) {
+		return p.IPostRepository.FindBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPost(v.(*models.Post)), nil
+}
+
+func (p postRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	v, err := p.store.load(ctx, postsNS, listKey("list", q), func() (interface{}, error) {
+		return p.IPostRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPosts(v.([]*models.Post)), nil
+}
+
+func (p postRepository) ListPublishedWithCategory(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	v, err := p.store.load(ctx, postsNS, listKey("published", q), func() (interface{}, error) {
+		return p.IPostRepository.ListPublishedWithCategory(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPosts(v.([]*models.Post)), nil
+}
+
+func (p postRepository) ListPublishedWithTotal(ctx context.Context, q store.ListQuery) (*store.PostListing, error) {
+	v, err := p.store.load(ctx, postsNS, listKey("total", q), func() (interface{}, error) {
+		return p.IPostRepository.ListPublishedWithTotal(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l := v.(*store.PostListing)
+	return &store.PostListing{Posts: copyPosts(l.Posts), Total: l.Total}, nil
+}
+
+func (p postRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	v, err := p.store.load(ctx, postsNS, listKey("count", q), func() (interface{}, error) {
+		return p.IPostRepository.Count(ctx, q)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return v.(int64), nil
+}
+
+func (p postRepository) Create(ctx context.Context, post *models.Post) error {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.Create(ctx, post)
+}
+
+func (p postRepository) Update(ctx context.Context, post *models.Post) error {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.Update(ctx, post)
+}
+
+func (p postRepository) Patch(ctx context.Context, post *models.Post, fields []string) error {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.Patch(ctx, post, fields)
+}
+
+func (p postRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (p postRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.Restore(ctx, ID)
+}
+
+func (p postRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.Purge(ctx, ID)
+}
+
+func (p postRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.PurgeDeletedBefore(ctx, before)
+}
+
+func (p postRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.ReassignCategory(ctx, from, to)
+}
+
+func (p postRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	defer p.store.invalidate(postsNS)
+	return p.IPostRepository.DeleteByCategory(ctx, categoryID, deletedBy)
+}
+
+// categoryRepository caches reads of store.ICategoryRepository
+type categoryRepository struct {
+	store.ICategoryRepository
+	store *CacheStore
+}
+
+func copyCategory(category *models.Category) *models.Category {
+	c := *category
+	return &c
+}
+
+func copyCategories(categories []*models.Category) []*models.Category {
+	c := make([]*models.Category, len(categories))
+	for i, category := range categories {
+		c[i] = copyCategory(category)
+	}
+
+	return c
+}
+
+func (c categoryRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Category, error) {
+	v, err := c.store.load(ctx, categoriesNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return c.ICategoryRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyCategory(v.(*models.Category)), nil
+}
+
+func (c categoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	v, err := c.store.load(ctx, categoriesNS, "slug:"+slug, func() (interface{}, error) {
+		return c.ICategoryRepository.FindBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyCategory(v.(*models.Category)), nil
+}
+
+func (c categoryRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Category, error) {
+	v, err := c.store.load(ctx, categoriesNS, listKey("list", q), func() (interface{}, error) {
+		return c.ICategoryRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyCategories(v.([]*models.Category)), nil
+}
+
+func (c categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.Create(ctx, category)
+}
+
+func (c categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.Update(ctx, category)
+}
+
+func (c categoryRepository) Patch(ctx context.Context, category *models.Category, fields []string) error {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.Patch(ctx, category, fields)
+}
+
+func (c categoryRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (c categoryRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.Restore(ctx, ID)
+}
+
+func (c categoryRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.Purge(ctx, ID)
+}
+
+func (c categoryRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer c.store.invalidate(categoriesNS)
+	return c.ICategoryRepository.PurgeDeletedBefore(ctx, before)
+}
+
+// materialRepository caches reads of store.IMaterialRepository
+type materialRepository struct {
+	store.IMaterialRepository
+	store *CacheStore
+}
+
+func copyMaterial(material *models.Material) *models.Material {
+	c := *material
+	c.TagIDs = copyIDs(material.TagIDs)
+	return &c
+}
+
+func copyMaterials(materials []*models.Material) []*models.Material {
+	c := make([]*models.Material, len(materials))
+	for i, material := range materials {
+		c[i] = copyMaterial(material)
+	}
+
+	return c
+}
+
+func (m materialRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Material, error) {
+	v, err := m.store.load(ctx, materialsNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return m.IMaterialRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyMaterial(v.(*models.Material)), nil
+}
+
+func (m materialRepository) FindBySlug(ctx context.Context, slug string) (*models.Material, error) {
+	v, err := m.store.load(ctx, materialsNS, "slug:"+slug, func() (interface{}, error) {
+		return m.IMaterialRepository.FindBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyMaterial(v.(*models.Material)), nil
+}
+
+func (m materialRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Material, error) {
+	v, err := m.store.load(ctx, materialsNS, listKey("list", q), func() (interface{}, error) {
+		return m.IMaterialRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyMaterials(v.([]*models.Material)), nil
+}
+
+func (m materialRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	v, err := m.store.load(ctx, materialsNS, listKey("count", q), func() (interface{}, error) {
+		return m.IMaterialRepository.Count(ctx, q)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return v.(int64), nil
+}
+
+func (m materialRepository) Create(ctx context.Context, material *models.Material) error {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.Create(ctx, material)
+}
+
+func (m materialRepository) Update(ctx context.Context, material *models.Material) error {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.Update(ctx, material)
+}
+
+func (m materialRepository) Patch(ctx context.Context, material *models.Material, fields []string) error {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.Patch(ctx, material, fields)
+}
+
+func (m materialRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (m materialRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.Restore(ctx, ID)
+}
+
+func (m materialRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.Purge(ctx, ID)
+}
+
+func (m materialRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.PurgeDeletedBefore(ctx, before)
+}
+
+func (m materialRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.ReassignCategory(ctx, from, to)
+}
+
+func (m materialRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	defer m.store.invalidate(materialsNS)
+	return m.IMaterialRepository.DeleteByCategory(ctx, categoryID, deletedBy)
+}
+
+// matCatRepository caches reads of store.IMatCategoryRepository
+type matCatRepository struct {
+	store.IMatCategoryRepository
+	store *CacheStore
+}
+
+func copyMatCategory(matCategory *models.MatCategory) *models.MatCategory {
+	c := *matCategory
+	return &c
+}
+
+func copyMatCategories(matCategories []*models.MatCategory) []*models.MatCategory {
+	c := make([]*models.MatCategory, len(matCategories))
+	for i, matCategory := range matCategories {
+		c[i] = copyMatCategory(matCategory)
+	}
+
+	return c
+}
+
+func (m matCatRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.MatCategory, error) {
+	v, err := m.store.load(ctx, matCategoriesNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return m.IMatCategoryRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyMatCategory(v.(*models.MatCategory)), nil
+}
+
+func (m matCatRepository) FindBySlug(ctx context.Context, slug string) (*models.MatCategory, error) {
+	v, err := m.store.load(ctx, matCategoriesNS, "slug:"+slug, func() (interface{}, error) {
+		return m.IMatCategoryRepository.FindBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyMatCategory(v.(*models.MatCategory)), nil
+}
+
+func (m matCatRepository) List(ctx context.Context, q store.ListQuery) ([]*models.MatCategory, error) {
+	v, err := m.store.load(ctx, matCategoriesNS, listKey("list", q), func() (interface{}, error) {
+		return m.IMatCategoryRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyMatCategories(v.([]*models.MatCategory)), nil
+}
+
+func (m matCatRepository) ListWithMaterials(ctx context.Context, q store.ListQuery, perCategory int64) ([]*models.MaterialShow, error) {
+	v, err := m.store.load(ctx, matCategoriesNS, listKey("materials", q)+"|"+strconv.FormatInt(perCategory, 10), func() (interface{}, error) {
+		return m.IMatCategoryRepository.ListWithMaterials(ctx, q, perCategory)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shows := v.([]*models.MaterialShow)
+	c := make([]*models.MaterialShow, len(shows))
+	for i, show := range shows {
+		s := *show
+		s.Materials = copyMaterials(show.Materials)
+		c[i] = &s
+	}
+
+	return c, nil
+}
+
+func (m matCatRepository) Create(ctx context.Context, matCategory *models.MatCategory) error {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.Create(ctx, matCategory)
+}
+
+func (m matCatRepository) Update(ctx context.Context, matCategory *models.MatCategory) error {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.Update(ctx, matCategory)
+}
+
+func (m matCatRepository) Patch(ctx context.Context, matCategory *models.MatCategory, fields []string) error {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.Patch(ctx, matCategory, fields)
+}
+
+func (m matCatRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (m matCatRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.Restore(ctx, ID)
+}
+
+func (m matCatRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.Purge(ctx, ID)
+}
+
+func (m matCatRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer m.store.invalidate(matCategoriesNS)
+	return m.IMatCategoryRepository.PurgeDeletedBefore(ctx, before)
+}
+
+// serviceRepository caches reads of store.IServiceRepository
+type serviceRepository struct {
+	store.IServiceRepository
+	store *CacheStore
+}
+
+func copyService(service *models.Service) *models.Service {
+	c := *service
+	return &c
+}
+
+func copyServices(services []*models.Service) []*models.Service {
+	c := make([]*models.Service, len(services))
+	for i, service := range services {
+		c[i] = copyService(service)
+	}
+
+	return c
+}
+
+func (s serviceRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Service, error) {
+	v, err := s.store.load(ctx, servicesNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return s.IServiceRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyService(v.(*models.Service)), nil
+}
+
+func (s serviceRepository) FindBySlug(ctx context.Context, slug string) (*models.Service, error) {
+	v, err := s.store.load(ctx, servicesNS, "slug:"+slug, func() (interface{}, error) {
+		return s.IServiceRepository.FindBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyService(v.(*models.Service)), nil
+}
+
+func (s serviceRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Service, error) {
+	v, err := s.store.load(ctx, servicesNS, listKey("list", q), func() (interface{}, error) {
+		return s.IServiceRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyServices(v.([]*models.Service)), nil
+}
+
+func (s serviceRepository) Create(ctx context.Context, service *models.Service) error {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.Create(ctx, service)
+}
+
+func (s serviceRepository) Update(ctx context.Context, service *models.Service) error {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.Update(ctx, service)
+}
+
+func (s serviceRepository) Patch(ctx context.Context, service *models.Service, fields []string) error {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.Patch(ctx, service, fields)
+}
+
+func (s serviceRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (s serviceRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.Restore(ctx, ID)
+}
+
+func (s serviceRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.Purge(ctx, ID)
+}
+
+func (s serviceRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer s.store.invalidate(servicesNS)
+	return s.IServiceRepository.PurgeDeletedBefore(ctx, before)
+}
+
+// pageRepository caches reads of store.IPageRepository
+type pageRepository struct {
+	store.IPageRepository
+	store *CacheStore
+}
+
+func copyPage(page *models.Page) *models.Page {
+	c := *page
+	c.PageData = copyBlocks(page.PageData)
+	return &c
+}
+
+func copyPages(pages []*models.Page) []*models.Page {
+	c := make([]*models.Page, len(pages))
+	for i, page := range pages {
+		c[i] = copyPage(page)
+	}
+
+	return c
+}
+
+func (p pageRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Page, error) {
+	v, err := p.store.load(ctx, pagesNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return p.IPageRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPage(v.(*models.Page)), nil
+}
+
+func (p pageRepository) FindByURL(ctx context.Context, URL string) (*models.Page, error) {
+	v, err := p.store.load(ctx, pagesNS, "url:"+URL, func() (interface{}, error) {
+		return p.IPageRepository.FindByURL(ctx, URL)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPage(v.(*models.Page)), nil
+}
+
+func (p pageRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Page, error) {
+	v, err := p.store.load(ctx, pagesNS, listKey("list", q), func() (interface{}, error) {
+		return p.IPageRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyPages(v.([]*models.Page)), nil
+}
+
+func (p pageRepository) Create(ctx context.Context, page *models.Page) error {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.Create(ctx, page)
+}
+
+func (p pageRepository) Update(ctx context.Context, page *models.Page) error {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.Update(ctx, page)
+}
+
+func (p pageRepository) Patch(ctx context.Context, page *models.Page, fields []string) error {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.Patch(ctx, page, fields)
+}
+
+func (p pageRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (p pageRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.Restore(ctx, ID)
+}
+
+func (p pageRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.Purge(ctx, ID)
+}
+
+func (p pageRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer p.store.invalidate(pagesNS)
+	return p.IPageRepository.PurgeDeletedBefore(ctx, before)
+}
+
+// tagRepository caches reads of store.ITagRepository
+type tagRepository struct {
+	store.ITagRepository
+	store *CacheStore
+}
+
+func copyTag(tag *models.Tag) *models.Tag {
+	c := *tag
+	return &c
+}
+
+func copyTags(tags []*models.Tag) []*models.Tag {
+	c := make([]*models.Tag, len(tags))
+	for i, tag := range tags {
+		c[i] = copyTag(tag)
+	}
+
+	return c
+}
+
+func (t tagRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Tag, error) {
+	v, err := t.store.load(ctx, tagsNS, "id:"+ID.Hex(), func() (interface{}, error) {
+		return t.ITagRepository.FindByID(ctx, ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyTag(v.(*models.Tag)), nil
+}
+
+func (t tagRepository) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	v, err := t.store.load(ctx, tagsNS, "slug:"+slug, func() (interface{}, error) {
+		return t.ITagRepository.FindBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyTag(v.(*models.Tag)), nil
+}
+
+func (t tagRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Tag, error) {
+	v, err := t.store.load(ctx, tagsNS, listKey("list", q), func() (interface{}, error) {
+		return t.ITagRepository.List(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyTags(v.([]*models.Tag)), nil
+}
+
+func (t tagRepository) ListByIDs(ctx context.Context, IDs []primitive.ObjectID) ([]*models.Tag, error) {
+	key := "ids:"
+	for _, ID := range IDs {
+		key += ID.Hex() + ","
+	}
+
+	v, err := t.store.load(ctx, tagsNS, key, func() (interface{}, error) {
+		return t.ITagRepository.ListByIDs(ctx, IDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyTags(v.([]*models.Tag)), nil
+}
+
+func (t tagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.Create(ctx, tag)
+}
+
+func (t tagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.Update(ctx, tag)
+}
+
+func (t tagRepository) Patch(ctx context.Context, tag *models.Tag, fields []string) error {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.Patch(ctx, tag, fields)
+}
+
+func (t tagRepository) Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.Delete(ctx, ID, deletedBy)
+}
+
+func (t tagRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.Restore(ctx, ID)
+}
+
+func (t tagRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.Purge(ctx, ID)
+}
+
+func (t tagRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer t.store.invalidate(tagsNS)
+	return t.ITagRepository.PurgeDeletedBefore(ctx, before)
+}
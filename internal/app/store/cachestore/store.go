@@ -0,0 +1,130 @@
+// Package cachestore wraps any store.Storer with read-through LRU cache of public reads
+// Results of FindByID, FindBySlug, FindByURL, listings and counts are cached,
+// writes made through the same wrapper drop cached results of affected repositories
+// Cache is local to process, so writes made by other instances are seen only after TTL
+package cachestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
+// Namespaces of cached results, one per repository
+const (
+	postsNS         = "posts"
+	categoriesNS    = "categories"
+	materialsNS     = "materials"
+	matCategoriesNS = "matcategories"
+	servicesNS      = "services"
+	pagesNS         = "pages"
+	tagsNS          = "tags"
+)
+
+// dependents lists namespaces whose results include documents of the key namespace
+// e.g. public post listing joins slugs of categories
+var dependents = map[string][]string{
+	categoriesNS: {postsNS},
+	materialsNS:  {matCategoriesNS},
+}
+
+// CacheStore implements store.Storer on top of another store
+type CacheStore struct {
+	store.Storer
+	cache *cache
+}
+
+// NewStore returns store which caches up to size results of s for ttl, zero ttl keeps them until eviction
+func NewStore(s store.Storer, size int, ttl time.Duration) *CacheStore {
+	return &CacheStore{
+		Storer: s,
+		cache:  newCache(size, ttl),
+	}
+}
+
+// Stats returns hit and miss statistics of cache
+func (s *CacheStore) Stats() Stats {
+	return s.cache.snapshot()
+}
+
+// txKey marks context of transaction started through CacheStore
+type txKey struct{}
+
+// WithTransaction runs fn in transaction of wrapped store
+// Reads inside transaction bypass cache, because they may see uncommitted changes,
+// and whole cache is dropped afterwards, because rolled back writes were invalidated too early
+func (s *CacheStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	defer s.cache.invalidate(postsNS, categoriesNS, materialsNS, matCategoriesNS, servicesNS, pagesNS, tagsNS)
+
+	return s.Storer.WithTransaction(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, txKey{}, true))
+	})
+}
+
+// load returns cached result of read or runs read and caches its result
+// Errors are not cached
+func (s *CacheStore) load(ctx context.Context, namespace, key string, read func() (interface{}, error)) (interface{}, error) {
+	if ctx.Value(txKey{}) != nil {
+		return read()
+	}
+
+	if v, ok := s.cache.get(namespace, key); ok {
+		return v, nil
+	}
+
+	gen := s.cache.generation(namespace)
+
+	v, err := read()
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.set(namespace, key, v, gen)
+
+	return v, nil
+}
+
+// invalidate drops cached results of namespace and its dependents when write succeeded
+// It is called after failed writes too, because failure may come after document was changed
+func (s *CacheStore) invalidate(namespace string) {
+	s.cache.invalidate(append([]string{namespace}, dependents[namespace]...)...)
+}
+
+// listKey returns cache key of listing
+func listKey(method string, q store.ListQuery) string {
+	return fmt.Sprintf("%s:%s|%s|%q|%d|%s|%d|%d|%d|%q|%d|%d|%d", method, q.CategoryID.Hex(), q.TagID.Hex(), q.Author, q.Deleted, q.Status,
+		q.From.UnixNano(), q.To.UnixNano(), q.UnpublishBefore.UnixNano(), q.Text, q.Sort, q.Limit, q.Offset)
+}
+
+/*
+ * Implement Storer interface
+ */
+func (s *CacheStore) Posts() store.IPostRepository {
+	return postRepository{IPostRepository: s.Storer.Posts(), store: s}
+}
+
+func (s *CacheStore) Categories() store.ICategoryRepository {
+	return categoryRepository{ICategoryRepository: s.Storer.Categories(), store: s}
+}
+
+func (s *CacheStore) Materials() store.IMaterialRepository {
+	return materialRepository{IMaterialRepository: s.Storer.Materials(), store: s}
+}
+
+func (s *CacheStore) MatCategories() store.IMatCategoryRepository {
+	return matCatRepository{IMatCategoryRepository: s.Storer.MatCategories(), store: s}
+}
+
+func (s *CacheStore) Services() store.IServiceRepository {
+	return serviceRepository{IServiceRepository: s.Storer.Services(), store: s}
+}
+
+func (s *CacheStore) Pages() store.IPageRepository {
+	return pageRepository{IPageRepository: s.Storer.Pages(), store: s}
+}
+
+func (s *CacheStore) Tags() store.ITagRepository {
+	return tagRepository{ITagRepository: s.Storer.Tags(), store: s}
+}
@@ -0,0 +1,162 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testPage(url string) *models.Page {
+	return &models.Page{
+		ID:       primitive.NewObjectID(),
+		Title:    "О компании",
+		Subtitle: "Подзаголовок страницы достаточной длины для валидации",
+		MetaDesc: "Описание страницы для поисковых систем достаточной длины",
+		URL:      url,
+		Status:   models.StatusPublished,
+	}
+}
+
+func testCategory(title, slug string) *models.Category {
+	return &models.Category{
+		ID:       primitive.NewObjectID(),
+		Title:    title,
+		Subtitle: "Подзаголовок категории достаточной длины",
+		Slug:     slug,
+		MetaDesc: "Описание категории для поисковых систем достаточной длины",
+	}
+}
+
+func TestCacheStore_FindByURL(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), 100, time.Minute)
+	page := testPage("/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+
+	for i := 0; i < 3; i++ {
+		found, err := s.Pages().FindByURL(ctx, "/about")
+		assert.NoError(t, err)
+		assert.Equal(t, page.Title, found.Title)
+
+		// Changes of returned document must not leak into cache
+		found.Title = "Изменено вызывающим"
+	}
+
+	assert.Equal(t, int64(2), s.Stats().Hits)
+	assert.Equal(t, int64(1), s.Stats().Misses)
+
+	// Errors are not cached
+	_, err := s.Pages().FindByURL(ctx, "/missing")
+	assert.Equal(t, store.ErrNotFound, err)
+	assert.Equal(t, 1, s.Stats().Size)
+}
+
+func TestCacheStore_CopyBlocks(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), 100, time.Minute)
+	page := testPage("/about")
+	page.PageData = []models.Block{
+		{Type: "paragraph", Data: &models.BlockData{Text: "Текст"}},
+		{Type: "list", Data: &models.BlockData{Style: "ordered", Items: []models.ListItem{
+			{Text: "Пункт", Items: []models.ListItem{{Text: "Вложенный"}}},
+		}}},
+		{Type: "table", Data: &models.BlockData{Content: [][]string{{"Налог", "13 %"}}}},
+	}
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+
+	found, err := s.Pages().FindByURL(ctx, "/about")
+	assert.NoError(t, err)
+
+	// Changes of blocks of returned document must not leak into cache
+	found.PageData[0].Data.Text = "Изменено"
+	found.PageData[1].Data.Items[0].Items[0].Text = "Изменено"
+	found.PageData[2].Data.Content[0][0] = "Изменено"
+
+	cached, err := s.Pages().FindByURL(ctx, "/about")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), s.Stats().Hits)
+	assert.Equal(t, "Текст", cached.PageData[0].Data.Text)
+	assert.Equal(t, "Вложенный", cached.PageData[1].Data.Items[0].Items[0].Text)
+	assert.Equal(t, "Налог", cached.PageData[2].Data.Content[0][0])
+}
+
+func TestCacheStore_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), 100, time.Minute)
+	page := testPage("/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+
+	list, err := s.Pages().List(ctx, store.ListQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = s.Pages().FindByID(ctx, page.ID)
+	assert.NoError(t, err)
+
+	page.Title = "О нас"
+	assert.NoError(t, s.Pages().Update(ctx, page))
+
+	found, err := s.Pages().FindByID(ctx, page.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "О нас", found.Title)
+	assert.Equal(t, int64(1), found.Version)
+
+	assert.NoError(t, s.Pages().Delete(ctx, page.ID, "admin"))
+
+	list, err = s.Pages().List(ctx, store.ListQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCacheStore_InvalidateDependents(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), 100, time.Minute)
+	category := testCategory("Новости", "news")
+
+	assert.NoError(t, s.Categories().Create(ctx, category))
+
+	s.cache.set(postsNS, "published", "joined slugs", s.cache.generation(postsNS))
+
+	category.Slug = "novosti"
+	assert.NoError(t, s.Categories().Update(ctx, category))
+
+	_, ok := s.cache.get(postsNS, "published")
+	assert.False(t, ok)
+}
+
+func TestCacheStore_WithTransaction(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore(), 100, time.Minute)
+	page := testPage("/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+
+	err := s.WithTransaction(ctx, func(ctx context.Context) error {
+		page.Title = "Незафиксированный заголовок"
+		if err := s.Pages().Update(ctx, page); err != nil {
+			return err
+		}
+
+		// Uncommitted read is not cached
+		_, err := s.Pages().FindByID(ctx, page.ID)
+		assert.NoError(t, err)
+
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 0, s.Stats().Size)
+
+	found, err := s.Pages().FindByID(ctx, page.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "О компании", found.Title)
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeletePolicy decides what happens with live children of deleted category
+type DeletePolicy string
+
+const (
+	DeleteRestrict DeletePolicy = "restrict" // Refuse to delete category while it has children, default
+	DeleteReassign DeletePolicy = "reassign" // Move children to another category
+	DeleteCascade  DeletePolicy = "cascade"  // Move children to trash together with category
+)
+
+// relation binds parent repository to repository of its children
+type relation struct {
+	notEmpty error
+	exists   func(ctx context.Context, ID primitive.ObjectID) error
+	delete   func(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
+	count    func(ctx context.Context, q ListQuery) (int64, error)
+	reassign func(ctx context.Context, from, to primitive.ObjectID) (int64, error)
+	cascade  func(ctx context.Context, parentID primitive.ObjectID, deletedBy string) (int64, error)
+}
+
+// DeleteCategory moves category to trash and applies policy to its live posts
+// reassignTo is used only by DeleteReassign
+func DeleteCategory(ctx context.Context, s Storer, ID primitive.ObjectID, deletedBy string, policy DeletePolicy, reassignTo primitive.ObjectID) error {
+	return deleteParent(ctx, s, relation{
+		notEmpty: helpers.ErrCategoryNotEmpty,
+		exists: func(ctx context.Context, ID primitive.ObjectID) error {
+			_, err := s.Categories().FindByID(ctx, ID)
+			return err
+		},
+		delete:   s.Categories().Delete,
+		count:    s.Posts().Count,
+		reassign: s.Posts().ReassignCategory,
+		cascade:  s.Posts().DeleteByCategory,
+	}, ID, deletedBy, policy, reassignTo)
+}
+
+// DeleteMatCategory moves material category to trash and applies policy to its live materials
+// reassignTo is used only by DeleteReassign
+func DeleteMatCategory(ctx context.Context, s Storer, ID primitive.ObjectID, deletedBy string, policy DeletePolicy, reassignTo primitive.ObjectID) error {
+	return deleteParent(ctx, s, relation{
+		notEmpty: helpers.ErrMatCategoryNotEmpty,
+		exists: func(ctx context.Context, ID primitive.ObjectID) error {
+			_, err := s.MatCategories().FindByID(ctx, ID)
+			return err
+		},
+		delete:   s.MatCategories().Delete,
+		count:    s.Materials().Count,
+		reassign: s.Materials().ReassignCategory,
+		cascade:  s.Materials().DeleteByCategory,
+	}, ID, deletedBy, policy, reassignTo)
+}
+
+// deleteParent applies policy and deletes parent in single transaction
+// ErrNotFound is returned when parent is not live
+func deleteParent(ctx context.Context, s Storer, rel relation, ID primitive.ObjectID, deletedBy string, policy DeletePolicy, reassignTo primitive.ObjectID) error {
+	return s.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := rel.exists(ctx, ID); err != nil {
+			return err
+		}
+
+		switch policy {
+		case "", DeleteRestrict:
+			n, err := rel.count(ctx, ListQuery{CategoryID: ID})
+			if err != nil {
+				return err
+			}
+
+			if n > 0 {
+				return rel.notEmpty
+			}
+		case DeleteReassign:
+			if reassignTo.IsZero() || reassignTo == ID {
+				return helpers.ErrNoReassignTarget
+			}
+
+			if err := rel.exists(ctx, reassignTo); err != nil {
+				if err == ErrNotFound {
+					return helpers.ErrNoReassignTarget
+				}
+				return err
+			}
+
+			if _, err := rel.reassign(ctx, ID, reassignTo); err != nil {
+				return err
+			}
+		case DeleteCascade:
+			if _, err := rel.cascade(ctx, ID, deletedBy); err != nil {
+				return err
+			}
+		default:
+			return helpers.ErrUnknownDeletePolicy
+		}
+
+		return rel.delete(ctx, ID, deletedBy)
+	})
+}
@@ -0,0 +1,112 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryRepository implements ICategoryRepository
+type CategoryRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create new category
+func (c *CategoryRepository) Create(ctx context.Context, cat *models.Category) error {
+	if err := cat.Validate(); err != nil {
+		return err
+	}
+
+	fcat, _ := c.FindBySlug(ctx, cat.Slug)
+	if fcat != nil {
+		return helpers.ErrCategoryAlreadyExist
+	}
+
+	return c.store.insertOne(ctx, c.collectionName, cat)
+}
+
+func (c *CategoryRepository) findOne(ctx context.Context, filter bson.M) (*models.Category, error) {
+	cat := &models.Category{}
+
+	if err := c.store.findOne(ctx, c.collectionName, filter, cat); err != nil {
+		return nil, err
+	}
+
+	return cat, nil
+}
+
+// FindByID finds category by it ID
+func (c *CategoryRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Category, error) {
+	return c.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// FindBySlug finds category by it slug
+func (c *CategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	return c.findOne(ctx, bson.M{"slug": slug, "deleted": false})
+}
+
+// List return categories selected by query
+func (c *CategoryRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Category, error) {
+	cats := make([]*models.Category, 0)
+
+	err := c.store.find(ctx, c.collectionName, mongoquery.Filter(mongoquery.Categories, q), &cats, mongoquery.FindOptions(mongoquery.Categories, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return cats, nil
+}
+
+// Delete moves category to trash
+func (c *CategoryRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return c.store.moveToTrash(ctx, c.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings category back from trash unless its slug is taken by another category
+func (c *CategoryRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	category, err := c.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fcategory, _ := c.FindBySlug(ctx, category.Slug); fcategory != nil {
+		return helpers.ErrCategoryAlreadyExist
+	}
+
+	return c.store.restore(ctx, c.collectionName, ID)
+}
+
+// Purge permanently removes category from trash
+func (c *CategoryRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return c.store.purge(ctx, c.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes categories deleted before given time
+func (c *CategoryRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return c.store.purgeDeletedBefore(ctx, c.collectionName, before)
+}
+
+// Update validate category and try to save it
+func (c *CategoryRepository) Update(ctx context.Context, updatedCategory *models.Category) error {
+	if err := updatedCategory.Validate(); err != nil {
+		return err
+	}
+
+	return c.store.updateVersion(ctx, c.collectionName, updatedCategory.ID, &updatedCategory.Version, updatedCategory)
+}
+
+// Patch validate category and save only its given fields
+func (c *CategoryRepository) Patch(ctx context.Context, updatedCategory *models.Category, fields []string) error {
+	if err := updatedCategory.Validate(); err != nil {
+		return err
+	}
+
+	return c.store.patchVersion(ctx, c.collectionName, updatedCategory.ID, &updatedCategory.Version, updatedCategory, fields)
+}
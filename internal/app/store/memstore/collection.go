@@ -0,0 +1,358 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collection returns collection by name and creates it on first use
+// Caller must hold write lock
+func (s *MemStore) collection(name string) *collection {
+	col, ok := s.collections[name]
+	if !ok {
+		col = &collection{}
+		s.collections[name] = col
+	}
+
+	return col
+}
+
+// docs returns documents of collection or nil if collection is not created yet
+// Caller must hold lock
+func (s *MemStore) docs(name string) []bson.M {
+	if col, ok := s.collections[name]; ok {
+		return col.docs
+	}
+
+	return nil
+}
+
+// filterDocs returns documents that match filter
+// Caller must hold lock
+func filterDocs(docs []bson.M, filter interface{}) ([]bson.M, error) {
+	f, err := toFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]bson.M, 0)
+	for _, doc := range docs {
+		ok, err := matchDocument(doc, f, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+// insertOne saves new document into collection
+func (s *MemStore) insertOne(ctx context.Context, name string, v interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	doc, err := toDocument(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col := s.collection(name)
+
+	if id, ok := doc["_id"]; ok {
+		for _, existing := range col.docs {
+			if valuesEqual(existing["_id"], id) {
+				return fmt.Errorf("memstore: duplicate key error collection %s _id: %v", name, id)
+			}
+		}
+	}
+
+	col.docs = append(col.docs, doc)
+
+	return nil
+}
+
+// findOne decodes first document that match filter into v
+func (s *MemStore) findOne(ctx context.Context, name string, filter interface{}, v interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs, err := filterDocs(s.docs(name), filter)
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return store.ErrNotFound
+	}
+
+	return decode(docs[0], v)
+}
+
+// find decodes documents that match filter into results with respect to sort, skip and limit options
+func (s *MemStore) find(ctx context.Context, name string, filter interface{}, results interface{}, opts ...*options.FindOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs, err := filterDocs(s.docs(name), filter)
+	if err != nil {
+		return err
+	}
+
+	fo := options.MergeFindOptions(opts...)
+
+	if fo.Sort != nil {
+		if docs, err = stageSort(docs, fo.Sort); err != nil {
+			return err
+		}
+	}
+
+	if fo.Skip != nil {
+		if docs, err = stageSkip(docs, *fo.Skip); err != nil {
+			return err
+		}
+	}
+
+	if fo.Limit != nil {
+		if docs, err = stageLimit(docs, *fo.Limit); err != nil {
+			return err
+		}
+	}
+
+	if fo.Projection != nil {
+		if docs, err = stageProject(docs, fo.Projection, nil); err != nil {
+			return err
+		}
+	}
+
+	return decodeAll(docs, results)
+}
+
+// count returns number of documents that match filter with respect to skip and limit options
+func (s *MemStore) count(ctx context.Context, name string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs, err := filterDocs(s.docs(name), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	co := options.MergeCountOptions(opts...)
+
+	if co.Skip != nil {
+		if docs, err = stageSkip(docs, *co.Skip); err != nil {
+			return 0, err
+		}
+	}
+
+	if co.Limit != nil {
+		if docs, err = stageLimit(docs, *co.Limit); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(docs)), nil
+}
+
+// aggregate runs pipeline on collection and decodes result into results
+func (s *MemStore) aggregate(ctx context.Context, name string, pipeline interface{}, results interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docs, err := s.runPipeline(cloneDocs(s.docs(name)), pipeline, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	return decodeAll(docs, results)
+}
+
+// updateOne applies update operators to first document that match filter
+// Like mongo it is not an error when nothing matched
+func (s *MemStore) updateOne(ctx context.Context, name string, filter interface{}, update interface{}) error {
+	_, err := s.update(ctx, name, filter, update)
+
+	return err
+}
+
+// update applies update operators to first document that match filter and returns number of matched documents
+func (s *MemStore) update(ctx context.Context, name string, filter interface{}, update interface{}) (int64, error) {
+	return s.updateDocs(ctx, name, filter, update, false)
+}
+
+// updateMany applies update operators to every document that match filter and returns number of matched documents
+func (s *MemStore) updateMany(ctx context.Context, name string, filter interface{}, update interface{}) (int64, error) {
+	return s.updateDocs(ctx, name, filter, update, true)
+}
+
+func (s *MemStore) updateDocs(ctx context.Context, name string, filter interface{}, update interface{}, many bool) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	ops, err := orderedKeys(update)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs, err := filterDocs(s.docs(name), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if !many && len(docs) > 1 {
+		docs = docs[:1]
+	}
+
+	// Build updated copies first so failed update leaves documents untouched
+	updated := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		if updated[i], err = applyUpdate(doc, ops); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, doc := range docs {
+		for k := range doc {
+			delete(doc, k)
+		}
+		for k, v := range updated[i] {
+			doc[k] = v
+		}
+	}
+
+	return int64(len(docs)), nil
+}
+
+// applyUpdate returns copy of document with applied update operators
+func applyUpdate(doc bson.M, ops bson.D) (bson.M, error) {
+	updated := clone(doc).(bson.M)
+
+	for _, op := range ops {
+		fields, err := toDocument(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Key {
+		case "$set":
+			for k, v := range fields {
+				setPath(updated, k, v)
+			}
+		case "$unset":
+			for k := range fields {
+				unsetPath(updated, k)
+			}
+		case "$inc":
+			for k, v := range fields {
+				cur, _ := lookupPath(updated, k)
+
+				sum, err := addNumbers(cur, v)
+				if err != nil {
+					return nil, err
+				}
+
+				setPath(updated, k, sum)
+			}
+		default:
+			return nil, fmt.Errorf("memstore: unsupported update operator %q", op.Key)
+		}
+	}
+
+	if !valuesEqual(updated["_id"], doc["_id"]) {
+		return nil, fmt.Errorf("memstore: performing an update on the path '_id' would modify the immutable field '_id'")
+	}
+
+	return updated, nil
+}
+
+// addNumbers adds increment to current value of field, missing field is zero
+func addNumbers(cur, inc interface{}) (interface{}, error) {
+	if cur == nil {
+		cur = int64(0)
+	}
+
+	for _, v := range []interface{}{cur, inc} {
+		switch v.(type) {
+		case int64, float64:
+		default:
+			return nil, fmt.Errorf("memstore: cannot apply $inc to %T with %T", cur, inc)
+		}
+	}
+
+	a, aInt := cur.(int64)
+	b, bInt := inc.(int64)
+	if aInt && bInt {
+		return a + b, nil
+	}
+
+	return toFloat(cur) + toFloat(inc), nil
+}
+
+// deleteMany removes documents that match filter and returns their number
+func (s *MemStore) deleteMany(ctx context.Context, name string, filter interface{}) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	f, err := toFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col, ok := s.collections[name]
+	if !ok {
+		return 0, nil
+	}
+
+	kept := make([]bson.M, 0, len(col.docs))
+	for _, doc := range col.docs {
+		matched, err := matchDocument(doc, f, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		if !matched {
+			kept = append(kept, doc)
+		}
+	}
+
+	deleted := int64(len(col.docs) - len(kept))
+	col.docs = kept
+
+	return deleted, nil
+}
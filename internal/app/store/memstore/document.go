@@ -0,0 +1,405 @@
+package memstore
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// toDocument converts model (or any bson marshallable value) into normalized document
+func toDocument(v interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := bson.M{}
+	if err = bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	return normalize(doc).(bson.M), nil
+}
+
+// decode fills v with document data the same way mongo driver does
+func decode(doc bson.M, v interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(raw, v)
+}
+
+// decodeAll decodes every document into new element of slice pointed by results
+func decodeAll(docs []bson.M, results interface{}) error {
+	arr := make(bson.A, 0, len(docs))
+	for _, doc := range docs {
+		arr = append(arr, doc)
+	}
+
+	raw, err := bson.Marshal(bson.M{"all": arr})
+	if err != nil {
+		return err
+	}
+
+	return bson.RawValue(bson.Raw(raw).Lookup("all")).Unmarshal(results)
+}
+
+// normalize converts every document like value to bson.M, every array to bson.A
+// and every number and time to one comparable representation
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case bson.M:
+		m := make(bson.M, len(val))
+		for k, item := range val {
+			m[k] = normalize(item)
+		}
+		return m
+	case map[string]interface{}:
+		return normalize(bson.M(val))
+	case bson.D:
+		m := make(bson.M, len(val))
+		for _, e := range val {
+			m[e.Key] = normalize(e.Value)
+		}
+		return m
+	case bson.A:
+		a := make(bson.A, len(val))
+		for i, item := range val {
+			a[i] = normalize(item)
+		}
+		return a
+	case []interface{}:
+		return normalize(bson.A(val))
+	case []bson.D:
+		a := make(bson.A, len(val))
+		for i, item := range val {
+			a[i] = normalize(item)
+		}
+		return a
+	case []primitive.ObjectID:
+		a := make(bson.A, len(val))
+		for i, item := range val {
+			a[i] = item
+		}
+		return a
+	case []string:
+		a := make(bson.A, len(val))
+		for i, item := range val {
+			a[i] = item
+		}
+		return a
+	case int:
+		return int64(val)
+	case int8:
+		return int64(val)
+	case int16:
+		return int64(val)
+	case int32:
+		return int64(val)
+	case uint:
+		return int64(val)
+	case uint8:
+		return int64(val)
+	case uint16:
+		return int64(val)
+	case uint32:
+		return int64(val)
+	case uint64:
+		return int64(val)
+	case float32:
+		return float64(val)
+	case time.Time:
+		return primitive.NewDateTimeFromTime(val)
+	default:
+		return v
+	}
+}
+
+// clone makes deep copy of normalized value
+func clone(v interface{}) interface{} {
+	switch val := v.(type) {
+	case bson.M:
+		m := make(bson.M, len(val))
+		for k, item := range val {
+			m[k] = clone(item)
+		}
+		return m
+	case bson.A:
+		a := make(bson.A, len(val))
+		for i, item := range val {
+			a[i] = clone(item)
+		}
+		return a
+	default:
+		return v
+	}
+}
+
+// cloneDocs makes deep copy of every document
+func cloneDocs(docs []bson.M) []bson.M {
+	res := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		res[i] = clone(doc).(bson.M)
+	}
+
+	return res
+}
+
+// orderedKeys returns elements of document like value keeping order for bson.D
+// bson.M has no order so its keys are sorted alphabetically
+func orderedKeys(v interface{}) (bson.D, error) {
+	switch val := v.(type) {
+	case bson.D:
+		return val, nil
+	case bson.M:
+		return sortedD(val), nil
+	case map[string]interface{}:
+		return sortedD(bson.M(val)), nil
+	default:
+		return nil, fmt.Errorf("memstore: expected document, got %T", v)
+	}
+}
+
+func sortedD(m bson.M) bson.D {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	d := make(bson.D, 0, len(keys))
+	for _, k := range keys {
+		d = append(d, bson.E{Key: k, Value: m[k]})
+	}
+
+	return d
+}
+
+// lookupPath returns value by dotted path
+// Arrays on the way are traversed like mongo does: "a.b" on array of documents returns array of "b" values
+func lookupPath(doc bson.M, path string) (interface{}, bool) {
+	return lookupParts(doc, strings.Split(path, "."))
+}
+
+func lookupParts(v interface{}, parts []string) (interface{}, bool) {
+	if len(parts) == 0 {
+		return v, true
+	}
+
+	switch val := v.(type) {
+	case bson.M:
+		next, ok := val[parts[0]]
+		if !ok {
+			return nil, false
+		}
+		return lookupParts(next, parts[1:])
+	case bson.A:
+		res := bson.A{}
+		for _, item := range val {
+			if _, isDoc := item.(bson.M); !isDoc {
+				continue
+			}
+
+			if found, ok := lookupParts(item, parts); ok {
+				res = append(res, found)
+			}
+		}
+		return res, true
+	default:
+		return nil, false
+	}
+}
+
+// setPath sets value by dotted path creating missing documents on the way
+func setPath(doc bson.M, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	cur := doc
+
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cur[part].(bson.M)
+		if !ok {
+			next = bson.M{}
+			cur[part] = next
+		}
+		cur = next
+	}
+
+	cur[parts[len(parts)-1]] = value
+}
+
+// unsetPath removes value by dotted path
+func unsetPath(doc bson.M, path string) {
+	parts := strings.Split(path, ".")
+	cur := doc
+
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cur[part].(bson.M)
+		if !ok {
+			return
+		}
+		cur = next
+	}
+
+	delete(cur, parts[len(parts)-1])
+}
+
+// typeRank follows mongo BSON comparison order
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int64, float64, primitive.Decimal128:
+		return 2
+	case string, primitive.Symbol:
+		return 3
+	case bson.M:
+		return 4
+	case bson.A:
+		return 5
+	case primitive.Binary:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	case primitive.Regex:
+		return 11
+	default:
+		return 12
+	}
+}
+
+// compareValues compares two normalized values using mongo BSON order
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+
+	switch av := a.(type) {
+	case int64, float64:
+		af, bf := toFloat(a), toFloat(b)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(av, b.(string))
+	case primitive.ObjectID:
+		bv := b.(primitive.ObjectID)
+		return bytes.Compare(av[:], bv[:])
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		bv := b.(primitive.DateTime)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case bson.A:
+		bv := b.(bson.A)
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compareValues(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(av), len(bv))
+	case bson.M:
+		ad, bd := sortedD(av), sortedD(b.(bson.M))
+		for i := 0; i < len(ad) && i < len(bd); i++ {
+			if c := strings.Compare(ad[i].Key, bd[i].Key); c != 0 {
+				return c
+			}
+			if c := compareValues(ad[i].Value, bd[i].Value); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(ad), len(bd))
+	}
+
+	return 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// valuesEqual reports equality of two normalized values
+func valuesEqual(a, b interface{}) bool {
+	if typeRank(a) != typeRank(b) {
+		return false
+	}
+
+	return compareValues(a, b) == 0
+}
+
+func toFloat(v interface{}) float64 {
+	switch val := v.(type) {
+	case int64:
+		return float64(val)
+	case float64:
+		return val
+	}
+	return 0
+}
+
+// toInt64 converts numeric stage or option argument
+func toInt64(v interface{}) (int64, error) {
+	switch val := normalize(v).(type) {
+	case int64:
+		return val, nil
+	case float64:
+		return int64(val), nil
+	}
+
+	return 0, fmt.Errorf("memstore: expected number, got %T", v)
+}
+
+// isTruthy follows aggregation expressions rules
+func isTruthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return false
+	case bool:
+		return val
+	case int64:
+		return val != 0
+	case float64:
+		return val != 0
+	}
+
+	return true
+}
@@ -0,0 +1,23 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reassignChildren moves live children of category to another one
+func (s *MemStore) reassignChildren(ctx context.Context, name string, schema mongoquery.Schema, from, to primitive.ObjectID) (int64, error) {
+	filter, update := mongoquery.ReassignChildren(schema, from, to)
+
+	return s.updateMany(ctx, name, filter, update)
+}
+
+// childrenToTrash marks live children of category as deleted
+func (s *MemStore) childrenToTrash(ctx context.Context, name string, schema mongoquery.Schema, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	filter, update := mongoquery.ChildrenToTrash(schema, categoryID, deletedBy, time.Now())
+
+	return s.updateMany(ctx, name, filter, update)
+}
@@ -0,0 +1,123 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MatCatRepository implements IMatCatRepository
+type MatCatRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create new material category
+func (m MatCatRepository) Create(ctx context.Context, matcat *models.MatCategory) error {
+	if err := matcat.Validate(); err != nil {
+		return err
+	}
+
+	fcat, _ := m.FindBySlug(ctx, matcat.Slug)
+	if fcat != nil {
+		return helpers.ErrMatCategoryAlreadyExist
+	}
+
+	return m.store.insertOne(ctx, m.collectionName, matcat)
+}
+
+func (m MatCatRepository) findOne(ctx context.Context, filter bson.M) (*models.MatCategory, error) {
+	matcat := &models.MatCategory{}
+
+	if err := m.store.findOne(ctx, m.collectionName, filter, matcat); err != nil {
+		return nil, err
+	}
+
+	return matcat, nil
+}
+
+// FindBySlug material category by slug
+func (m MatCatRepository) FindBySlug(ctx context.Context, slug string) (*models.MatCategory, error) {
+	return m.findOne(ctx, bson.M{"slug": slug, "deleted": false})
+}
+
+// FindByID material category by it ID
+func (m MatCatRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.MatCategory, error) {
+	return m.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// List return material categories selected by query
+func (m MatCatRepository) List(ctx context.Context, q store.ListQuery) ([]*models.MatCategory, error) {
+	matcats := make([]*models.MatCategory, 0)
+
+	err := m.store.find(ctx, m.collectionName, mongoquery.Filter(mongoquery.MatCategories, q), &matcats, mongoquery.FindOptions(mongoquery.MatCategories, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return matcats, nil
+}
+
+// ListWithMaterials used to find and join materials and materials' categories for rendering in browser
+func (m MatCatRepository) ListWithMaterials(ctx context.Context, q store.ListQuery, perCategory int64) ([]*models.MaterialShow, error) {
+	mats := make([]*models.MaterialShow, 0)
+
+	if err := m.store.aggregate(ctx, m.collectionName, mongoquery.MatCategoriesWithMaterials(q, perCategory), &mats); err != nil {
+		return nil, err
+	}
+
+	return mats, nil
+}
+
+// Update validate matcategory and try to save it
+func (m MatCatRepository) Update(ctx context.Context, updatedMatCategory *models.MatCategory) error {
+	if err := updatedMatCategory.Validate(); err != nil {
+		return err
+	}
+
+	return m.store.updateVersion(ctx, m.collectionName, updatedMatCategory.ID, &updatedMatCategory.Version, updatedMatCategory)
+}
+
+// Patch validate matcategory and save only its given fields
+func (m MatCatRepository) Patch(ctx context.Context, updatedMatCategory *models.MatCategory, fields []string) error {
+	if err := updatedMatCategory.Validate(); err != nil {
+		return err
+	}
+
+	return m.store.patchVersion(ctx, m.collectionName, updatedMatCategory.ID, &updatedMatCategory.Version, updatedMatCategory, fields)
+}
+
+// Delete moves material category to trash
+func (m MatCatRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return m.store.moveToTrash(ctx, m.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings material category back from trash unless its slug is taken by another material category
+func (m MatCatRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	matcat, err := m.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fmatcat, _ := m.FindBySlug(ctx, matcat.Slug); fmatcat != nil {
+		return helpers.ErrMatCategoryAlreadyExist
+	}
+
+	return m.store.restore(ctx, m.collectionName, ID)
+}
+
+// Purge permanently removes material category from trash
+func (m MatCatRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return m.store.purge(ctx, m.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes material categories deleted before given time
+func (m MatCatRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.store.purgeDeletedBefore(ctx, m.collectionName, before)
+}
@@ -0,0 +1,127 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaterialRepository implements IMaterialRepository
+type MaterialRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new material
+func (m MaterialRepository) Create(ctx context.Context, material *models.Material) error {
+	if err := material.Validate(); err != nil {
+		return err
+	}
+
+	fmaterial, _ := m.FindBySlug(ctx, material.Slug)
+	if fmaterial != nil {
+		return helpers.ErrMaterialAlreadyExist
+	}
+
+	return m.store.insertOne(ctx, m.collectionName, material)
+}
+
+func (m MaterialRepository) findOne(ctx context.Context, filter bson.M) (*models.Material, error) {
+	material := &models.Material{}
+
+	if err := m.store.findOne(ctx, m.collectionName, filter, material); err != nil {
+		return nil, err
+	}
+
+	return material, nil
+}
+
+// FindBySlug lookup material by it slug
+func (m MaterialRepository) FindBySlug(ctx context.Context, slug string) (*models.Material, error) {
+	return m.findOne(ctx, bson.M{"slug": slug, "deleted": false})
+}
+
+// FindByID lookup material by ID
+func (m MaterialRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Material, error) {
+	return m.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// List return materials selected by query
+func (m MaterialRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Material, error) {
+	materials := make([]*models.Material, 0)
+
+	err := m.store.find(ctx, m.collectionName, mongoquery.Filter(mongoquery.Materials, q), &materials, mongoquery.FindOptions(mongoquery.Materials, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+// Update recieve material, validate it and try to update it
+func (m MaterialRepository) Update(ctx context.Context, updatedMaterial *models.Material) error {
+	if err := updatedMaterial.Validate(); err != nil {
+		return err
+	}
+
+	return m.store.updateVersion(ctx, m.collectionName, updatedMaterial.ID, &updatedMaterial.Version, updatedMaterial)
+}
+
+// Patch validate material and save only its given fields
+func (m MaterialRepository) Patch(ctx context.Context, updatedMaterial *models.Material, fields []string) error {
+	if err := updatedMaterial.Validate(); err != nil {
+		return err
+	}
+
+	return m.store.patchVersion(ctx, m.collectionName, updatedMaterial.ID, &updatedMaterial.Version, updatedMaterial, fields)
+}
+
+// Delete moves material to trash
+func (m MaterialRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return m.store.moveToTrash(ctx, m.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings material back from trash unless its slug is taken by another material
+func (m MaterialRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	material, err := m.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fmaterial, _ := m.FindBySlug(ctx, material.Slug); fmaterial != nil {
+		return helpers.ErrMaterialAlreadyExist
+	}
+
+	return m.store.restore(ctx, m.collectionName, ID)
+}
+
+// Purge permanently removes material from trash
+func (m MaterialRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return m.store.purge(ctx, m.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes materials deleted before given time
+func (m MaterialRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.store.purgeDeletedBefore(ctx, m.collectionName, before)
+}
+
+// Count return number of materials selected by query
+func (m MaterialRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	return m.store.count(ctx, m.collectionName, mongoquery.Filter(mongoquery.Materials, q))
+}
+
+// ReassignCategory moves live materials of category to another one
+func (m MaterialRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	return m.store.reassignChildren(ctx, m.collectionName, mongoquery.Materials, from, to)
+}
+
+// DeleteByCategory moves live materials of category to trash
+func (m MaterialRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	return m.store.childrenToTrash(ctx, m.collectionName, mongoquery.Materials, categoryID, deletedBy)
+}
@@ -0,0 +1,112 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PageRepository implements IPageRepository
+type PageRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new page
+func (p PageRepository) Create(ctx context.Context, page *models.Page) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	fpage, _ := p.FindByURL(ctx, page.URL)
+	if fpage != nil {
+		return helpers.ErrPageAlreadyExist
+	}
+
+	return p.store.insertOne(ctx, p.collectionName, page)
+}
+
+func (p PageRepository) findOne(ctx context.Context, filter bson.M) (*models.Page, error) {
+	page := &models.Page{}
+
+	if err := p.store.findOne(ctx, p.collectionName, filter, page); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// FindByURL return page by it URL
+func (p PageRepository) FindByURL(ctx context.Context, URL string) (*models.Page, error) {
+	return p.findOne(ctx, bson.M{"url": URL, "deleted": false})
+}
+
+// FindByID return page by it ID
+func (p PageRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Page, error) {
+	return p.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// List return pages selected by query
+func (p PageRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Page, error) {
+	pages := make([]*models.Page, 0)
+
+	err := p.store.find(ctx, p.collectionName, mongoquery.Filter(mongoquery.Pages, q), &pages, mongoquery.FindOptions(mongoquery.Pages, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Update validate update page model and try to update it
+func (p PageRepository) Update(ctx context.Context, updatedPage *models.Page) error {
+	if err := updatedPage.Validate(); err != nil {
+		return err
+	}
+
+	return p.store.updateVersion(ctx, p.collectionName, updatedPage.ID, &updatedPage.Version, updatedPage)
+}
+
+// Patch validate page and save only its given fields
+func (p PageRepository) Patch(ctx context.Context, updatedPage *models.Page, fields []string) error {
+	if err := updatedPage.Validate(); err != nil {
+		return err
+	}
+
+	return p.store.patchVersion(ctx, p.collectionName, updatedPage.ID, &updatedPage.Version, updatedPage, fields)
+}
+
+// Delete moves page to trash
+func (p PageRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return p.store.moveToTrash(ctx, p.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings page back from trash unless its url is taken by another page
+func (p PageRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	page, err := p.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fpage, _ := p.FindByURL(ctx, page.URL); fpage != nil {
+		return helpers.ErrPageAlreadyExist
+	}
+
+	return p.store.restore(ctx, p.collectionName, ID)
+}
+
+// Purge permanently removes page from trash
+func (p PageRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return p.store.purge(ctx, p.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes pages deleted before given time
+func (p PageRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.store.purgeDeletedBefore(ctx, p.collectionName, before)
+}
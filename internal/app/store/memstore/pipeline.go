@@ -0,0 +1,427 @@
+package memstore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// stages converts mongo.Pipeline or bson.A from $lookup into ordered stages list
+func stages(pipeline interface{}) (bson.D, error) {
+	var raw []interface{}
+
+	switch p := pipeline.(type) {
+	case mongo.Pipeline:
+		for _, st := range p {
+			raw = append(raw, st)
+		}
+	case []bson.D:
+		for _, st := range p {
+			raw = append(raw, st)
+		}
+	case bson.A:
+		raw = p
+	case []interface{}:
+		raw = p
+	case nil:
+	default:
+		return nil, fmt.Errorf("memstore: unsupported pipeline type %T", pipeline)
+	}
+
+	res := make(bson.D, 0, len(raw))
+	for _, st := range raw {
+		d, err := orderedKeys(st)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(d) != 1 {
+			return nil, fmt.Errorf("memstore: pipeline stage must have exactly one field")
+		}
+
+		res = append(res, d[0])
+	}
+
+	return res, nil
+}
+
+// runPipeline evaluates aggregation pipeline on documents
+// Caller must hold store lock because $lookup reads other collections
+func (s *MemStore) runPipeline(docs []bson.M, pipeline interface{}, vars bson.M) ([]bson.M, error) {
+	list, err := stages(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, st := range list {
+		switch st.Key {
+		case "$match":
+			docs, err = stageMatch(docs, st.Value, vars)
+		case "$sort":
+			docs, err = stageSort(docs, st.Value)
+		case "$skip":
+			docs, err = stageSkip(docs, st.Value)
+		case "$limit":
+			docs, err = stageLimit(docs, st.Value)
+		case "$project":
+			docs, err = stageProject(docs, st.Value, vars)
+		case "$unwind":
+			docs, err = stageUnwind(docs, st.Value)
+		case "$lookup":
+			docs, err = s.stageLookup(docs, st.Value, vars)
+		case "$count":
+			docs, err = stageCount(docs, st.Value)
+		case "$facet":
+			docs, err = s.stageFacet(docs, st.Value, vars)
+		default:
+			err = fmt.Errorf("memstore: unsupported pipeline stage %q", st.Key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return docs, nil
+}
+
+func stageMatch(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	filter, err := toFilter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		ok, err := matchDocument(doc, filter, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+func stageSort(docs []bson.M, spec interface{}) ([]bson.M, error) {
+	keys, err := orderedKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]int64, len(keys))
+	for i, k := range keys {
+		if dirs[i], err = toInt64(k.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for n, k := range keys {
+			a, _ := lookupPath(docs[i], k.Key)
+			b, _ := lookupPath(docs[j], k.Key)
+
+			c := compareValues(normalize(a), normalize(b))
+			if c == 0 {
+				continue
+			}
+
+			if dirs[n] < 0 {
+				return c > 0
+			}
+			return c < 0
+		}
+
+		return false
+	})
+
+	return docs, nil
+}
+
+func stageSkip(docs []bson.M, spec interface{}) ([]bson.M, error) {
+	n, err := toInt64(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if n >= int64(len(docs)) {
+		return []bson.M{}, nil
+	}
+
+	if n > 0 {
+		docs = docs[n:]
+	}
+
+	return docs, nil
+}
+
+func stageLimit(docs []bson.M, spec interface{}) ([]bson.M, error) {
+	n, err := toInt64(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 {
+		n = -n
+	}
+
+	if n > 0 && n < int64(len(docs)) {
+		docs = docs[:n]
+	}
+
+	return docs, nil
+}
+
+func stageProject(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	keys, err := orderedKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	inclusion, excludeID := false, false
+	for _, k := range keys {
+		switch v := normalize(k.Value).(type) {
+		case bool:
+			if k.Key == "_id" {
+				excludeID = !v
+			} else if v {
+				inclusion = true
+			}
+		case int64, float64:
+			if k.Key == "_id" {
+				excludeID = !isTruthy(v)
+			} else if isTruthy(v) {
+				inclusion = true
+			}
+		default:
+			inclusion = true
+		}
+	}
+
+	res := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		if !inclusion {
+			out := clone(doc).(bson.M)
+			for _, k := range keys {
+				if !isTruthy(normalize(k.Value)) {
+					unsetPath(out, k.Key)
+				}
+			}
+			res = append(res, out)
+			continue
+		}
+
+		out := bson.M{}
+		if id, ok := doc["_id"]; ok && !excludeID {
+			out["_id"] = id
+		}
+
+		for _, k := range keys {
+			if k.Key == "_id" && !isComputed(k.Value) {
+				continue
+			}
+
+			if isComputed(k.Value) {
+				val, err := evalExpr(doc, vars, normalize(k.Value))
+				if err != nil {
+					return nil, err
+				}
+				setPath(out, k.Key, val)
+				continue
+			}
+
+			if !isTruthy(normalize(k.Value)) {
+				continue
+			}
+
+			if val, ok := lookupPath(doc, k.Key); ok {
+				setPath(out, k.Key, val)
+			}
+		}
+
+		res = append(res, out)
+	}
+
+	return res, nil
+}
+
+// isComputed reports whether $project value is expression instead of inclusion flag
+func isComputed(v interface{}) bool {
+	switch normalize(v).(type) {
+	case bool, int64, float64:
+		return false
+	}
+
+	return true
+}
+
+func stageUnwind(docs []bson.M, spec interface{}) ([]bson.M, error) {
+	var (
+		path     string
+		preserve bool
+	)
+
+	switch v := normalize(spec).(type) {
+	case string:
+		path = v
+	case bson.M:
+		path, _ = v["path"].(string)
+		preserve = isTruthy(v["preserveNullAndEmptyArrays"])
+	}
+
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("memstore: $unwind path must start with '$'")
+	}
+	path = path[1:]
+
+	res := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		val, exists := lookupPath(doc, path)
+		arr, isArr := val.(bson.A)
+
+		switch {
+		case !exists || val == nil || (isArr && len(arr) == 0):
+			if preserve {
+				out := clone(doc).(bson.M)
+				unsetPath(out, path)
+				res = append(res, out)
+			}
+		case !isArr:
+			res = append(res, doc)
+		default:
+			for _, item := range arr {
+				out := clone(doc).(bson.M)
+				setPath(out, path, clone(item))
+				res = append(res, out)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (s *MemStore) stageLookup(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	raw, err := orderedKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{}
+	for _, e := range raw {
+		params[e.Key] = e.Value
+	}
+
+	from, _ := params["from"].(string)
+	as, _ := params["as"].(string)
+	if from == "" || as == "" {
+		return nil, fmt.Errorf("memstore: $lookup needs 'from' and 'as'")
+	}
+
+	var foreign []bson.M
+	if col, ok := s.collections[from]; ok {
+		foreign = col.docs
+	}
+
+	localField, _ := params["localField"].(string)
+	foreignField, _ := params["foreignField"].(string)
+
+	letSpec, _ := toFilter(params["let"])
+
+	res := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		joined := cloneDocs(foreign)
+
+		if localField != "" {
+			local, exists := lookupPath(doc, localField)
+
+			candidates := bson.A{local}
+			if arr, ok := local.(bson.A); ok && exists {
+				candidates = arr
+			}
+
+			matched := make([]bson.M, 0)
+			for _, fdoc := range joined {
+				fval, fexists := lookupPath(fdoc, foreignField)
+				for _, c := range candidates {
+					if matchEq(fval, fexists, c) {
+						matched = append(matched, fdoc)
+						break
+					}
+				}
+			}
+			joined = matched
+		}
+
+		if sub, ok := params["pipeline"]; ok {
+			subVars := bson.M{}
+			for k, v := range vars {
+				subVars[k] = v
+			}
+
+			for name, expr := range letSpec {
+				if subVars[name], err = evalExpr(doc, vars, expr); err != nil {
+					return nil, err
+				}
+			}
+
+			if joined, err = s.runPipeline(joined, sub, subVars); err != nil {
+				return nil, err
+			}
+		}
+
+		out := clone(doc).(bson.M)
+		arr := make(bson.A, len(joined))
+		for i, j := range joined {
+			arr[i] = j
+		}
+		setPath(out, as, arr)
+
+		res = append(res, out)
+	}
+
+	return res, nil
+}
+
+func stageCount(docs []bson.M, spec interface{}) ([]bson.M, error) {
+	name, ok := spec.(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("memstore: $count needs field name")
+	}
+
+	if len(docs) == 0 {
+		return []bson.M{}, nil
+	}
+
+	return []bson.M{{name: int64(len(docs))}}, nil
+}
+
+// stageFacet runs each sub-pipeline on documents and returns single document with their results
+// Stages never modify documents in place, so sub-pipelines share them and get only own slice for sorting
+func (s *MemStore) stageFacet(docs []bson.M, spec interface{}, vars bson.M) ([]bson.M, error) {
+	facets, err := orderedKeys(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	out := bson.M{}
+	for _, f := range facets {
+		res, err := s.runPipeline(append([]bson.M(nil), docs...), f.Value, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		arr := make(bson.A, len(res))
+		for i, d := range res {
+			arr[i] = d
+		}
+		out[f.Key] = arr
+	}
+
+	return []bson.M{out}, nil
+}
@@ -0,0 +1,157 @@
+package memstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPostRepository_ListPublishedWithCategory(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	cat := storetest.Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, cat))
+
+	base := time.Now()
+	for i, title := range []string{"Первая запись", "Вторая запись", "Третья запись", "Четвертая запись"} {
+		post := storetest.Post(title, cat.ID)
+		post.Time = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	posts, err := s.Posts().ListPublishedWithCategory(ctx, store.ListQuery{Offset: 1, Limit: 2})
+
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 2) {
+		assert.Equal(t, "Третья запись", posts[0].Title)
+		assert.Equal(t, "Вторая запись", posts[1].Title)
+		assert.Equal(t, cat.Slug, posts[0].CategorySlug)
+		assert.Equal(t, "/category/"+cat.Slug+"/"+posts[0].Slug, posts[0].GetURL())
+		assert.Empty(t, posts[0].MetaDesc)
+	}
+}
+
+func TestPostRepository_ListPublishedWithTotal(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	cat := storetest.Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, cat))
+
+	base := time.Now()
+	for i, title := range []string{"Первая запись", "Вторая запись", "Третья запись", "Четвертая запись"} {
+		post := storetest.Post(title, cat.ID)
+		post.Time = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	// Deleted post is neither listed nor counted
+	deleted := storetest.Post("Удаленная запись", cat.ID)
+	assert.NoError(t, s.Posts().Create(ctx, deleted))
+	assert.NoError(t, s.Posts().Delete(ctx, deleted.ID, "editor"))
+
+	testCases := []struct {
+		name  string
+		query store.ListQuery
+		want  []string
+		total int64
+	}{
+		{
+			name:  "Page",
+			query: store.ListQuery{Offset: 1, Limit: 2},
+			want:  []string{"Третья запись", "Вторая запись"},
+			total: 4,
+		},
+		{
+			name:  "Page out of range",
+			query: store.ListQuery{Offset: 10, Limit: 2},
+			want:  []string{},
+			total: 4,
+		},
+		{
+			name:  "Empty category",
+			query: store.ListQuery{CategoryID: primitive.NewObjectID(), Limit: 2},
+			want:  []string{},
+			total: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listing, err := s.Posts().ListPublishedWithTotal(ctx, tc.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.total, listing.Total)
+
+			titles := make([]string, 0)
+			for _, p := range listing.Posts {
+				titles = append(titles, p.Title)
+			}
+			assert.Equal(t, tc.want, titles)
+
+			if len(listing.Posts) > 0 {
+				assert.Equal(t, cat.Slug, listing.Posts[0].CategorySlug)
+				assert.Equal(t, cat.Title, listing.Posts[0].CategoryTitle)
+			}
+		})
+	}
+}
+
+func TestMatCatRepository_ListWithMaterials(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	matcat := &models.MatCategory{
+		ID:    primitive.NewObjectID(),
+		Title: "Бухгалтерия",
+		Slug:  "buhgalteriya",
+		Desc:  "Документы и шаблоны для ведения бухгалтерского учета организации",
+	}
+	assert.NoError(t, s.MatCategories().Create(ctx, matcat))
+
+	other := &models.MatCategory{
+		ID:    primitive.NewObjectID(),
+		Title: "Налоги",
+		Slug:  "nalogi",
+		Desc:  "Документы и шаблоны для расчета и уплаты налогов для организаций",
+	}
+	assert.NoError(t, s.MatCategories().Create(ctx, other))
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, s.Materials().Create(ctx, &models.Material{
+			ID:            primitive.NewObjectID(),
+			Title:         "Материал номер " + string(rune('A'+i)),
+			MatCategoryID: matcat.ID,
+			Slug:          "material_" + string(rune('a'+i)),
+			Desc:          "Описание материала, которое достаточно длинное для валидации",
+			Time:          time.Now().Add(time.Duration(i) * time.Minute),
+			FileLink:      "/uploads/documents/file.pdf",
+		}))
+	}
+
+	mats, err := s.MatCategories().ListWithMaterials(ctx, store.ListQuery{}, 3)
+
+	assert.NoError(t, err)
+	if assert.Len(t, mats, 2) {
+		assert.Len(t, mats[0].Materials, 3)
+		assert.Equal(t, "Материал номер E", mats[0].Materials[0].Title)
+		assert.Empty(t, mats[1].Materials)
+	}
+}
+
+func TestRunPipeline_UnsupportedStage(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	err := s.aggregate(ctx, "posts", mongo.Pipeline{{{Key: "$group", Value: bson.D{}}}}, &[]bson.M{})
+
+	assert.Error(t, err)
+}
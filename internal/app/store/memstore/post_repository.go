@@ -0,0 +1,168 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostRepository implements IPostRepository
+type PostRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new post
+func (p PostRepository) Create(ctx context.Context, post *models.Post) error {
+	if err := post.Validate(); err != nil {
+		return err
+	}
+
+	fpost, _ := p.FindBySlug(ctx, post.Slug)
+	if fpost != nil {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	return p.store.insertOne(ctx, p.collectionName, post)
+}
+
+func (p PostRepository) findOne(ctx context.Context, filter bson.M) (*models.Post, error) {
+	post := &models.Post{}
+
+	if err := p.store.findOne(ctx, p.collectionName, filter, post); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// FindBySlug lookup post by it slug
+func (p PostRepository) FindBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return p.findOne(ctx, bson.M{"slug": slug, "deleted": false})
+}
+
+// FindByID lookup post by it ID
+func (p PostRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Post, error) {
+	return p.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// List return posts selected by query
+func (p PostRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	posts := make([]*models.Post, 0)
+
+	err := p.store.find(ctx, p.collectionName, mongoquery.Filter(mongoquery.Posts, q), &posts, mongoquery.FindOptions(mongoquery.Posts, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// ListPublishedWithCategory return published posts selected by query with joined category slug
+func (p PostRepository) ListPublishedWithCategory(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	q.Deleted = store.NotDeleted
+	q.Status = models.StatusPublished
+	posts := make([]*models.Post, 0)
+
+	if err := p.store.aggregate(ctx, p.collectionName, mongoquery.PostsWithCategory(q), &posts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// ListPublishedWithTotal return page of published posts with joined category and number of all posts selected by query
+func (p PostRepository) ListPublishedWithTotal(ctx context.Context, q store.ListQuery) (*store.PostListing, error) {
+	q.Deleted = store.NotDeleted
+	q.Status = models.StatusPublished
+	listings := make([]*store.PostListing, 0, 1)
+
+	if err := p.store.aggregate(ctx, p.collectionName, mongoquery.PostsWithTotal(q), &listings); err != nil {
+		return nil, err
+	}
+
+	return store.FirstListing(listings), nil
+}
+
+// Count return number of posts selected by query
+func (p PostRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	return p.store.count(ctx, p.collectionName, mongoquery.Filter(mongoquery.Posts, q))
+}
+
+// Update recieve post, validate it and try to update it
+func (p PostRepository) Update(ctx context.Context, updatedPost *models.Post) error {
+	if err := updatedPost.Validate(); err != nil {
+		return err
+	}
+
+	if p.slugTaken(ctx, updatedPost) {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	return p.store.updateVersion(ctx, p.collectionName, updatedPost.ID, &updatedPost.Version, updatedPost)
+}
+
+// Patch validate post and save only its given fields
+func (p PostRepository) Patch(ctx context.Context, updatedPost *models.Post, fields []string) error {
+	if err := updatedPost.Validate(); err != nil {
+		return err
+	}
+
+	if p.slugTaken(ctx, updatedPost) {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	return p.store.patchVersion(ctx, p.collectionName, updatedPost.ID, &updatedPost.Version, updatedPost, fields)
+}
+
+// slugTaken reports whether slug of post is used by another live post, like unique index of MongoDB
+func (p PostRepository) slugTaken(ctx context.Context, post *models.Post) bool {
+	fpost, _ := p.FindBySlug(ctx, post.Slug)
+
+	return fpost != nil && fpost.ID != post.ID
+}
+
+// Delete moves post to trash
+func (p PostRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return p.store.moveToTrash(ctx, p.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings post back from trash unless its slug is taken by another post
+func (p PostRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	post, err := p.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fpost, _ := p.FindBySlug(ctx, post.Slug); fpost != nil {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	return p.store.restore(ctx, p.collectionName, ID)
+}
+
+// Purge permanently removes post from trash
+func (p PostRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return p.store.purge(ctx, p.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes posts deleted before given time
+func (p PostRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.store.purgeDeletedBefore(ctx, p.collectionName, before)
+}
+
+// ReassignCategory moves live posts of category to another one
+func (p PostRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	return p.store.reassignChildren(ctx, p.collectionName, mongoquery.Posts, from, to)
+}
+
+// DeleteByCategory moves live posts of category to trash
+func (p PostRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	return p.store.childrenToTrash(ctx, p.collectionName, mongoquery.Posts, categoryID, deletedBy)
+}
@@ -0,0 +1,388 @@
+package memstore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// toFilter converts filter passed to repository into normalized document
+func toFilter(filter interface{}) (bson.M, error) {
+	if filter == nil {
+		return bson.M{}, nil
+	}
+
+	f, ok := normalize(filter).(bson.M)
+	if !ok {
+		return nil, fmt.Errorf("memstore: filter must be a document, got %T", filter)
+	}
+
+	return f, nil
+}
+
+// matchDocument checks document against query filter
+// vars used by $expr when filter is part of $lookup sub-pipeline
+func matchDocument(doc bson.M, filter bson.M, vars bson.M) (bool, error) {
+	for key, cond := range filter {
+		var (
+			ok  bool
+			err error
+		)
+
+		switch key {
+		case "$and", "$or", "$nor":
+			ok, err = matchLogical(doc, key, cond, vars)
+		case "$expr":
+			var res interface{}
+			res, err = evalExpr(doc, vars, cond)
+			ok = isTruthy(res)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("memstore: unsupported query operator %q", key)
+			}
+
+			val, exists := lookupPath(doc, key)
+			ok, err = matchField(val, exists, cond)
+		}
+
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchLogical(doc bson.M, op string, cond interface{}, vars bson.M) (bool, error) {
+	arr, ok := cond.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("memstore: %s must be an array", op)
+	}
+
+	for _, item := range arr {
+		sub, ok := item.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("memstore: %s entries must be documents", op)
+		}
+
+		matched, err := matchDocument(doc, sub, vars)
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case op == "$and" && !matched:
+			return false, nil
+		case op == "$or" && matched:
+			return true, nil
+		case op == "$nor" && matched:
+			return false, nil
+		}
+	}
+
+	return op != "$or", nil
+}
+
+// isOperatorDoc reports whether condition is document like {"$gt": 1, "$lt": 5}
+func isOperatorDoc(cond interface{}) (bson.M, bool) {
+	m, ok := cond.(bson.M)
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return nil, false
+		}
+	}
+
+	return m, true
+}
+
+// matchField checks single field value against condition
+func matchField(val interface{}, exists bool, cond interface{}) (bool, error) {
+	ops, ok := isOperatorDoc(cond)
+	if !ok {
+		return matchEq(val, exists, cond), nil
+	}
+
+	for op, arg := range ops {
+		matched, err := matchOperator(val, exists, op, arg, ops)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchEq follows mongo equality: arrays match when any element is equal
+// and null matches missing fields
+func matchEq(val interface{}, exists bool, cond interface{}) bool {
+	if cond == nil {
+		return !exists || typeRank(val) == 1
+	}
+
+	if !exists {
+		return false
+	}
+
+	if valuesEqual(val, cond) {
+		return true
+	}
+
+	if arr, ok := val.(bson.A); ok {
+		for _, item := range arr {
+			if valuesEqual(item, cond) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchCompare checks ordered comparisons, mongo compares only values of same type
+func matchCompare(val interface{}, exists bool, arg interface{}, check func(int) bool) bool {
+	if !exists {
+		return false
+	}
+
+	candidates := bson.A{val}
+	if arr, ok := val.(bson.A); ok {
+		candidates = arr
+	}
+
+	for _, item := range candidates {
+		if typeRank(item) == typeRank(arg) && check(compareValues(item, arg)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchOperator(val interface{}, exists bool, op string, arg interface{}, ops bson.M) (bool, error) {
+	switch op {
+	case "$eq":
+		return matchEq(val, exists, arg), nil
+	case "$ne":
+		return !matchEq(val, exists, arg), nil
+	case "$gt":
+		return matchCompare(val, exists, arg, func(c int) bool { return c > 0 }), nil
+	case "$gte":
+		return matchCompare(val, exists, arg, func(c int) bool { return c >= 0 }), nil
+	case "$lt":
+		return matchCompare(val, exists, arg, func(c int) bool { return c < 0 }), nil
+	case "$lte":
+		return matchCompare(val, exists, arg, func(c int) bool { return c <= 0 }), nil
+	case "$in", "$nin":
+		arr, ok := arg.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("memstore: %s needs an array", op)
+		}
+
+		found := false
+		for _, item := range arr {
+			if matchEq(val, exists, item) {
+				found = true
+				break
+			}
+		}
+
+		return found == (op == "$in"), nil
+	case "$exists":
+		return exists == isTruthy(arg), nil
+	case "$not":
+		matched, err := matchField(val, exists, arg)
+		return !matched, err
+	case "$regex":
+		return matchRegex(val, exists, arg, ops["$options"])
+	case "$options":
+		// handled together with $regex
+		return true, nil
+	}
+
+	return false, fmt.Errorf("memstore: unsupported query operator %q", op)
+}
+
+func matchRegex(val interface{}, exists bool, pattern, opts interface{}) (bool, error) {
+	var expr string
+
+	switch p := pattern.(type) {
+	case string:
+		expr = p
+	case primitive.Regex:
+		expr, opts = p.Pattern, p.Options
+	default:
+		return false, fmt.Errorf("memstore: $regex must be a string")
+	}
+
+	if o, ok := opts.(string); ok && o != "" {
+		expr = "(?" + strings.ReplaceAll(o, "x", "") + ")" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false, err
+	}
+
+	if !exists {
+		return false, nil
+	}
+
+	candidates := bson.A{val}
+	if arr, ok := val.(bson.A); ok {
+		candidates = arr
+	}
+
+	for _, item := range candidates {
+		if s, ok := item.(string); ok && re.MatchString(s) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// evalExpr evaluates aggregation expression like "$field", "$$var" or {"$eq": [...]}
+func evalExpr(doc bson.M, vars bson.M, expr interface{}) (interface{}, error) {
+	switch e := expr.(type) {
+	case string:
+		switch {
+		case strings.HasPrefix(e, "$$"):
+			parts := strings.SplitN(e[2:], ".", 2)
+			val, ok := vars[parts[0]]
+			if parts[0] == "ROOT" || parts[0] == "CURRENT" {
+				val, ok = doc, true
+			}
+			if !ok {
+				return nil, fmt.Errorf("memstore: undefined variable %q", parts[0])
+			}
+			if len(parts) == 1 {
+				return val, nil
+			}
+			res, _ := lookupParts(val, strings.Split(parts[1], "."))
+			return res, nil
+		case strings.HasPrefix(e, "$"):
+			res, _ := lookupPath(doc, e[1:])
+			return res, nil
+		}
+		return e, nil
+	case bson.A:
+		res := make(bson.A, len(e))
+		for i, item := range e {
+			val, err := evalExpr(doc, vars, item)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = val
+		}
+		return res, nil
+	case bson.M:
+		if len(e) == 1 {
+			for op, arg := range e {
+				if strings.HasPrefix(op, "$") {
+					return evalOperator(doc, vars, op, arg)
+				}
+			}
+		}
+
+		res := bson.M{}
+		for k, item := range e {
+			val, err := evalExpr(doc, vars, item)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = val
+		}
+		return res, nil
+	}
+
+	return expr, nil
+}
+
+func evalOperator(doc bson.M, vars bson.M, op string, arg interface{}) (interface{}, error) {
+	if op == "$literal" {
+		return arg, nil
+	}
+
+	args, ok := arg.(bson.A)
+	if !ok {
+		args = bson.A{arg}
+	}
+
+	vals := make(bson.A, len(args))
+	for i, a := range args {
+		val, err := evalExpr(doc, vars, a)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+
+	switch op {
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+		if len(vals) != 2 {
+			return nil, fmt.Errorf("memstore: %s needs exactly 2 arguments", op)
+		}
+
+		c := compareValues(vals[0], vals[1])
+		switch op {
+		case "$eq":
+			return c == 0, nil
+		case "$ne":
+			return c != 0, nil
+		case "$gt":
+			return c > 0, nil
+		case "$gte":
+			return c >= 0, nil
+		case "$lt":
+			return c < 0, nil
+		}
+		return c <= 0, nil
+	case "$and":
+		for _, v := range vals {
+			if !isTruthy(v) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$or":
+		for _, v := range vals {
+			if isTruthy(v) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$not":
+		return !isTruthy(vals[0]), nil
+	case "$in":
+		if len(vals) != 2 {
+			return nil, fmt.Errorf("memstore: $in needs exactly 2 arguments")
+		}
+
+		arr, ok := vals[1].(bson.A)
+		if !ok {
+			return nil, fmt.Errorf("memstore: second argument of $in must be an array")
+		}
+
+		for _, item := range arr {
+			if valuesEqual(vals[0], item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$size":
+		arr, ok := vals[0].(bson.A)
+		if !ok {
+			return nil, fmt.Errorf("memstore: argument of $size must be an array")
+		}
+		return int64(len(arr)), nil
+	}
+
+	return nil, fmt.Errorf("memstore: unsupported expression operator %q", op)
+}
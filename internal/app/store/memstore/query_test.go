@@ -0,0 +1,117 @@
+package memstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMatchDocument(t *testing.T) {
+	catID := primitive.NewObjectID()
+	now := time.Now()
+
+	doc, err := toDocument(bson.M{
+		"_id":         primitive.NewObjectID(),
+		"title":       "Первая запись",
+		"category_id": catID,
+		"deleted":     false,
+		"views":       int32(10),
+		"time":        now,
+		"tags":        []string{"vat", "reports"},
+		"img":         bson.M{"url": "/uploads/1.png"},
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		filter     interface{}
+		wantOutput bool
+	}{
+		{
+			name:       "Empty filter",
+			filter:     bson.M{},
+			wantOutput: true,
+		},
+		{
+			name:       "Equality with bson.D",
+			filter:     bson.D{{Key: "deleted", Value: false}, {Key: "category_id", Value: catID}},
+			wantOutput: true,
+		},
+		{
+			name:       "Missing field does not equal false",
+			filter:     bson.M{"hidden": false},
+			wantOutput: false,
+		},
+		{
+			name:       "Missing field equals null",
+			filter:     bson.M{"hidden": nil},
+			wantOutput: true,
+		},
+		{
+			name:       "Number of other type",
+			filter:     bson.M{"views": 10},
+			wantOutput: true,
+		},
+		{
+			name:       "Array contains",
+			filter:     bson.M{"tags": "vat"},
+			wantOutput: true,
+		},
+		{
+			name:       "Nested path",
+			filter:     bson.M{"img.url": "/uploads/1.png"},
+			wantOutput: true,
+		},
+		{
+			name:       "Time range",
+			filter:     bson.M{"time": bson.M{"$gte": now.Add(-time.Hour), "$lt": now.Add(time.Hour)}},
+			wantOutput: true,
+		},
+		{
+			name:       "$in",
+			filter:     bson.M{"views": bson.M{"$in": bson.A{1, 10}}},
+			wantOutput: true,
+		},
+		{
+			name:       "$ne",
+			filter:     bson.M{"deleted": bson.M{"$ne": true}},
+			wantOutput: true,
+		},
+		{
+			name:       "$or",
+			filter:     bson.M{"$or": bson.A{bson.M{"views": 1}, bson.M{"deleted": true}}},
+			wantOutput: false,
+		},
+		{
+			name:       "$regex",
+			filter:     bson.M{"title": bson.M{"$regex": "запись$"}},
+			wantOutput: true,
+		},
+		{
+			name:       "$expr",
+			filter:     bson.M{"$expr": bson.M{"$eq": bson.A{"$category_id", catID}}},
+			wantOutput: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filter, err := toFilter(testCase.filter)
+			assert.NoError(t, err)
+
+			res, err := matchDocument(doc, filter, nil)
+			assert.NoError(t, err)
+
+			assert.Equal(t, testCase.wantOutput, res)
+		})
+	}
+}
+
+func TestMatchDocument_UnsupportedOperator(t *testing.T) {
+	_, err := matchDocument(bson.M{"a": int64(1)}, bson.M{"a": bson.M{"$near": bson.A{}}}, nil)
+
+	assert.Error(t, err)
+}
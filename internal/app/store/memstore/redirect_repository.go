@@ -0,0 +1,40 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+)
+
+// RedirectRepository implements IRedirectRepository
+type RedirectRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new redirect
+func (r RedirectRepository) Create(ctx context.Context, redirect *models.Redirect) error {
+	if err := redirect.Validate(); err != nil {
+		return err
+	}
+
+	return r.store.insertOne(ctx, r.collectionName, redirect)
+}
+
+// FindBySlug lookup the latest redirect from slug of document type
+func (r RedirectRepository) FindBySlug(ctx context.Context, docType, slug string) (*models.Redirect, error) {
+	redirects := make([]*models.Redirect, 0, 1)
+	filter, opts := mongoquery.LatestRedirect(docType, slug)
+
+	if err := r.store.find(ctx, r.collectionName, filter, &redirects, opts); err != nil {
+		return nil, err
+	}
+
+	if len(redirects) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	return redirects[0], nil
+}
@@ -0,0 +1,49 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevisionRepository implements IRevisionRepository
+type RevisionRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new revision
+func (r RevisionRepository) Create(ctx context.Context, rev *models.Revision) error {
+	if err := rev.Validate(); err != nil {
+		return err
+	}
+
+	return r.store.insertOne(ctx, r.collectionName, rev)
+}
+
+// FindByID lookup revision by it ID
+func (r RevisionRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Revision, error) {
+	rev := &models.Revision{}
+
+	if err := r.store.findOne(ctx, r.collectionName, bson.M{"_id": ID}, rev); err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+// ListByDocument return revisions of document selected by query
+func (r RevisionRepository) ListByDocument(ctx context.Context, docID primitive.ObjectID, q store.ListQuery) ([]*models.Revision, error) {
+	revs := make([]*models.Revision, 0)
+	filter, opts := mongoquery.RevisionsOfDocument(docID, q)
+
+	if err := r.store.find(ctx, r.collectionName, filter, &revs, opts); err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
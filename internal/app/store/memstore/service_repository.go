@@ -0,0 +1,112 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServiceRepository implements IServiceRepository
+type ServiceRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new service
+func (s ServiceRepository) Create(ctx context.Context, service *models.Service) error {
+	if err := service.Validate(); err != nil {
+		return err
+	}
+
+	fservice, _ := s.FindBySlug(ctx, service.Slug)
+	if fservice != nil {
+		return helpers.ErrServiceAlreadyExist
+	}
+
+	return s.store.insertOne(ctx, s.collectionName, service)
+}
+
+func (s ServiceRepository) findOne(ctx context.Context, filter bson.M) (*models.Service, error) {
+	service := &models.Service{}
+
+	if err := s.store.findOne(ctx, s.collectionName, filter, service); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// FindBySlug lookup service by it slug
+func (s ServiceRepository) FindBySlug(ctx context.Context, slug string) (*models.Service, error) {
+	return s.findOne(ctx, bson.M{"slug": slug, "deleted": false})
+}
+
+// FindByID lookup service by it id
+func (s ServiceRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Service, error) {
+	return s.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// List return services selected by query
+func (s ServiceRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Service, error) {
+	services := make([]*models.Service, 0)
+
+	err := s.store.find(ctx, s.collectionName, mongoquery.Filter(mongoquery.Services, q), &services, mongoquery.FindOptions(mongoquery.Services, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// Update validate updated service and try to update it
+func (s ServiceRepository) Update(ctx context.Context, updatedService *models.Service) error {
+	if err := updatedService.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.updateVersion(ctx, s.collectionName, updatedService.ID, &updatedService.Version, updatedService)
+}
+
+// Patch validate service and save only its given fields
+func (s ServiceRepository) Patch(ctx context.Context, updatedService *models.Service, fields []string) error {
+	if err := updatedService.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.patchVersion(ctx, s.collectionName, updatedService.ID, &updatedService.Version, updatedService, fields)
+}
+
+// Delete moves service to trash
+func (s ServiceRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return s.store.moveToTrash(ctx, s.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings service back from trash unless its slug is taken by another service
+func (s ServiceRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	service, err := s.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fservice, _ := s.FindBySlug(ctx, service.Slug); fservice != nil {
+		return helpers.ErrServiceAlreadyExist
+	}
+
+	return s.store.restore(ctx, s.collectionName, ID)
+}
+
+// Purge permanently removes service from trash
+func (s ServiceRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return s.store.purge(ctx, s.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes services deleted before given time
+func (s ServiceRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return s.store.purgeDeletedBefore(ctx, s.collectionName, before)
+}
@@ -0,0 +1,210 @@
+package memstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MemStore represents in-memory implementation of store.Storer
+// Documents are kept in the same bson form as in MongoDB, so filters,
+// updates and aggregation pipelines from handlers can be evaluated as is
+type MemStore struct {
+	mu                  sync.RWMutex
+	txMu                sync.Mutex
+	collections         map[string]*collection
+	postRepository      *PostRepository
+	categoryRepository  *CategoryRepository
+	materialsRepository *MaterialRepository
+	matCatRepository    *MatCatRepository
+	userRepository      *UserRepository
+	pageRepository      *PageRepository
+	serviceRepository   *ServiceRepository
+	revisionRepository  *RevisionRepository
+	redirectRepository  *RedirectRepository
+	tagRepository       *TagRepository
+}
+
+// collection keeps documents in insertion order like mongo natural order
+type collection struct {
+	docs []bson.M
+}
+
+// NewStore return new empty in-memory Store object
+func NewStore() *MemStore {
+	return &MemStore{
+		collections: make(map[string]*collection),
+	}
+}
+
+// Close does nothing and exists for symmetry with MongoStore
+func (s *MemStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// txKey marks context of running transaction
+type txKey struct{}
+
+// WithTransaction runs fn and brings all collections back to their state before fn when it fails
+// Transactions are serialized, but writes made outside of them are lost on rollback too,
+// which is fine for tests and development MemStore is meant for
+func (s *MemStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.RLock()
+	snapshot := make(map[string]*collection, len(s.collections))
+	for name, col := range s.collections {
+		snapshot[name] = &collection{docs: cloneDocs(col.docs)}
+	}
+	s.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		s.mu.Lock()
+		s.collections = snapshot
+		s.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+/*
+ * Implement Storer interface
+ */
+func (s *MemStore) Posts() store.IPostRepository {
+	if s.postRepository != nil {
+		return s.postRepository
+	}
+
+	s.postRepository = &PostRepository{
+		store:          s,
+		collectionName: "posts",
+	}
+
+	return s.postRepository
+}
+
+func (s *MemStore) Categories() store.ICategoryRepository {
+	if s.categoryRepository != nil {
+		return s.categoryRepository
+	}
+
+	s.categoryRepository = &CategoryRepository{
+		store:          s,
+		collectionName: "categories",
+	}
+
+	return s.categoryRepository
+}
+
+func (s *MemStore) Materials() store.IMaterialRepository {
+	if s.materialsRepository != nil {
+		return s.materialsRepository
+	}
+
+	s.materialsRepository = &MaterialRepository{
+		store:          s,
+		collectionName: "materials",
+	}
+
+	return s.materialsRepository
+}
+
+func (s *MemStore) MatCategories() store.IMatCategoryRepository {
+	if s.matCatRepository != nil {
+		return s.matCatRepository
+	}
+
+	s.matCatRepository = &MatCatRepository{
+		store:          s,
+		collectionName: "matcategories",
+	}
+
+	return s.matCatRepository
+}
+
+func (s *MemStore) Users() store.IUserRepository {
+	if s.userRepository != nil {
+		return s.userRepository
+	}
+
+	s.userRepository = &UserRepository{
+		store:          s,
+		collectionName: "users",
+	}
+
+	return s.userRepository
+}
+
+func (s *MemStore) Services() store.IServiceRepository {
+	if s.serviceRepository != nil {
+		return s.serviceRepository
+	}
+
+	s.serviceRepository = &ServiceRepository{
+		store:          s,
+		collectionName: "services",
+	}
+
+	return s.serviceRepository
+}
+
+func (s *MemStore) Pages() store.IPageRepository {
+	if s.pageRepository != nil {
+		return s.pageRepository
+	}
+
+	s.pageRepository = &PageRepository{
+		store:          s,
+		collectionName: "pages",
+	}
+
+	return s.pageRepository
+}
+
+func (s *MemStore) Revisions() store.IRevisionRepository {
+	if s.revisionRepository != nil {
+		return s.revisionRepository
+	}
+
+	s.revisionRepository = &RevisionRepository{
+		store:          s,
+		collectionName: "revisions",
+	}
+
+	return s.revisionRepository
+}
+
+func (s *MemStore) Redirects() store.IRedirectRepository {
+	if s.redirectRepository != nil {
+		return s.redirectRepository
+	}
+
+	s.redirectRepository = &RedirectRepository{
+		store:          s,
+		collectionName: "redirects",
+	}
+
+	return s.redirectRepository
+}
+
+func (s *MemStore) Tags() store.ITagRepository {
+	if s.tagRepository != nil {
+		return s.tagRepository
+	}
+
+	s.tagRepository = &TagRepository{
+		store:          s,
+		collectionName: "tags",
+	}
+
+	return s.tagRepository
+}
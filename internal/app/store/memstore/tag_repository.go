@@ -0,0 +1,127 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TagRepository implements ITagRepository
+type TagRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new tag
+func (t TagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+
+	ftag, _ := t.FindBySlug(ctx, tag.Slug)
+	if ftag != nil {
+		return helpers.ErrTagAlreadyExist
+	}
+
+	return t.store.insertOne(ctx, t.collectionName, tag)
+}
+
+func (t TagRepository) findOne(ctx context.Context, filter bson.M) (*models.Tag, error) {
+	tag := &models.Tag{}
+
+	if err := t.store.findOne(ctx, t.collectionName, filter, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// FindBySlug lookup tag by it slug
+func (t TagRepository) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	return t.findOne(ctx, bson.M{"slug": slug, "deleted": false})
+}
+
+// FindByID lookup tag by it ID
+func (t TagRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Tag, error) {
+	return t.findOne(ctx, bson.M{"_id": ID, "deleted": false})
+}
+
+// List return tags selected by query
+func (t TagRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, 0)
+
+	err := t.store.find(ctx, t.collectionName, mongoquery.Filter(mongoquery.Tags, q), &tags, mongoquery.FindOptions(mongoquery.Tags, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// ListByIDs return live tags with given IDs sorted by title
+func (t TagRepository) ListByIDs(ctx context.Context, IDs []primitive.ObjectID) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, 0, len(IDs))
+	if len(IDs) == 0 {
+		return tags, nil
+	}
+
+	filter, opts := mongoquery.TagsByIDs(IDs)
+	if err := t.store.find(ctx, t.collectionName, filter, &tags, opts); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Update validate tag and try to save it
+func (t TagRepository) Update(ctx context.Context, updatedTag *models.Tag) error {
+	if err := updatedTag.Validate(); err != nil {
+		return err
+	}
+
+	return t.store.updateVersion(ctx, t.collectionName, updatedTag.ID, &updatedTag.Version, updatedTag)
+}
+
+// Patch validate tag and save only its given fields
+func (t TagRepository) Patch(ctx context.Context, updatedTag *models.Tag, fields []string) error {
+	if err := updatedTag.Validate(); err != nil {
+		return err
+	}
+
+	return t.store.patchVersion(ctx, t.collectionName, updatedTag.ID, &updatedTag.Version, updatedTag, fields)
+}
+
+// Delete moves tag to trash
+func (t TagRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return t.store.moveToTrash(ctx, t.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings tag back from trash unless its slug is taken by another tag
+func (t TagRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	tag, err := t.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if ftag, _ := t.FindBySlug(ctx, tag.Slug); ftag != nil {
+		return helpers.ErrTagAlreadyExist
+	}
+
+	return t.store.restore(ctx, t.collectionName, ID)
+}
+
+// Purge permanently removes tag from trash
+func (t TagRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return t.store.purge(ctx, t.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes tags deleted before given time
+func (t TagRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return t.store.purgeDeletedBefore(ctx, t.collectionName, before)
+}
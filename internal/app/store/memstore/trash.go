@@ -0,0 +1,52 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// moveToTrash marks live document of collection as deleted
+func (s *MemStore) moveToTrash(ctx context.Context, name string, ID primitive.ObjectID, deletedBy string) error {
+	filter, update := mongoquery.MoveToTrash(ID, deletedBy, time.Now())
+
+	return s.updateOne(ctx, name, filter, update)
+}
+
+// restore brings deleted document of collection back
+func (s *MemStore) restore(ctx context.Context, name string, ID primitive.ObjectID) error {
+	filter, update := mongoquery.RestoreFromTrash(ID)
+
+	matched, err := s.update(ctx, name, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if matched == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// purge permanently removes deleted document of collection
+func (s *MemStore) purge(ctx context.Context, name string, ID primitive.ObjectID) error {
+	deleted, err := s.deleteMany(ctx, name, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// purgeDeletedBefore permanently removes documents of collection deleted before given time
+func (s *MemStore) purgeDeletedBefore(ctx context.Context, name string, before time.Time) (int64, error) {
+	return s.deleteMany(ctx, name, mongoquery.DeletedBefore(before))
+}
@@ -0,0 +1,110 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserRepository implements IUserRepository
+type UserRepository struct {
+	store          *MemStore
+	collectionName string
+}
+
+// Create save new user
+func (u UserRepository) Create(ctx context.Context, usr *models.User) error {
+	if err := usr.Validate(); err != nil {
+		return err
+	}
+
+	// If username already taken
+	fusr, _ := u.FindByUsername(ctx, usr.Username)
+	if fusr != nil {
+		return helpers.ErrUserAlreadyExist
+	}
+
+	// If email already taken
+	fusr, _ = u.FindByEmail(ctx, usr.Email)
+	if fusr != nil {
+		return helpers.ErrEmailAlreadyExist
+	}
+
+	if err := usr.BeforeSave(); err != nil {
+		return err
+	}
+
+	return u.store.insertOne(ctx, u.collectionName, usr)
+}
+
+func (u UserRepository) findOne(ctx context.Context, filter bson.M) (*models.User, error) {
+	user := &models.User{}
+
+	if err := u.store.findOne(ctx, u.collectionName, filter, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// FindByUsername look up user by his username
+func (u UserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return u.findOne(ctx, bson.M{"username": username, "deleted": false})
+}
+
+// FindByEmail look up user by his email
+func (u UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return u.findOne(ctx, bson.M{"email": email, "deleted": false})
+}
+
+// FindByAuthorSlug look up user by slug of his author profile
+func (u UserRepository) FindByAuthorSlug(ctx context.Context, slug string) (*models.User, error) {
+	return u.findOne(ctx, bson.M{"author.slug": slug, "deleted": false})
+}
+
+// UpdateAuthor replaces author profile of user
+func (u UserRepository) UpdateAuthor(ctx context.Context, username string, author *models.Author) error {
+	if err := author.Validate(); err != nil {
+		return err
+	}
+
+	// Slug of another author is taken
+	fusr, _ := u.FindByAuthorSlug(ctx, author.Slug)
+	if fusr != nil && fusr.Username != username {
+		return helpers.ErrAuthorAlreadyExist
+	}
+
+	n, err := u.store.update(ctx, u.collectionName, bson.M{"username": username, "deleted": false}, bson.M{"$set": bson.M{"author": author}})
+	if err == nil && n == 0 {
+		return store.ErrNotFound
+	}
+
+	return err
+}
+
+// Delete marks user as deleted
+func (u UserRepository) Delete(ctx context.Context, deletedID primitive.ObjectID) error {
+	return u.store.updateOne(ctx, u.collectionName, bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+}
+
+// Login checks user credentials and returns new token
+func (u UserRepository) Login(ctx context.Context, username, password, secret string) (string, time.Time, error) {
+	fusr, err := u.FindByUsername(ctx, username)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	fusr.Password = password
+
+	token, expTime, err := fusr.DoLogin(secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expTime, nil
+}
@@ -0,0 +1,64 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// updateVersion replaces fields of document with doc only if document still has given version
+// Version is incremented before update, so doc holding it is saved with new version, and restored on failure
+func (s *MemStore) updateVersion(ctx context.Context, name string, ID primitive.ObjectID, version *int64, doc interface{}) error {
+	expected := *version
+	*version = expected + 1
+
+	matched, err := s.update(ctx, name, mongoquery.Versioned(ID, expected), bson.M{"$set": doc})
+	if err == nil && matched == 0 {
+		err = s.versionConflict(ctx, name, ID)
+	}
+
+	if err != nil {
+		*version = expected
+	}
+
+	return err
+}
+
+// versionConflict tells whether update matched nothing because of missing document or stale version
+func (s *MemStore) versionConflict(ctx context.Context, name string, ID primitive.ObjectID) error {
+	n, err := s.count(ctx, name, bson.M{"_id": ID})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	return store.ErrVersionConflict
+}
+
+// patchVersion writes only given fields of doc only if document still has given version
+// On success version is incremented like by updateVersion
+func (s *MemStore) patchVersion(ctx context.Context, name string, ID primitive.ObjectID, version *int64, doc interface{}, fields []string) error {
+	update, err := mongoquery.SetFields(doc, fields)
+	if err != nil {
+		return err
+	}
+
+	matched, err := s.update(ctx, name, mongoquery.Versioned(ID, *version), update)
+	if err != nil {
+		return err
+	}
+
+	if matched == 0 {
+		return s.versionConflict(ctx, name, ID)
+	}
+
+	*version++
+
+	return nil
+}
@@ -0,0 +1,338 @@
+// Package mongoquery translates store queries into MongoDB filters and pipelines
+// It is shared by mongostore and memstore, so both evaluate the very same documents
+package mongoquery
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Schema describes which ListQuery filters are applicable to collection
+type Schema struct {
+	CategoryField string // Field with parent category ID, empty if there is no parent
+	TagField      string // Array field with tag IDs, empty if documents have no tags
+	Authored      bool   // Documents have author field
+	Timed         bool   // Documents have time field
+	Publishable   bool   // Documents have publication status
+	Scheduled     bool   // Documents have publish_at and unpublish_at fields
+	SoftDelete    bool   // Documents have deleted mark
+}
+
+var (
+	Posts         = Schema{CategoryField: "category_id", TagField: "tag_ids", Authored: true, Timed: true, Publishable: true, Scheduled: true, SoftDelete: true}
+	Materials     = Schema{CategoryField: "matcategory_id", TagField: "tag_ids", Timed: true, SoftDelete: true}
+	Categories    = Schema{SoftDelete: true}
+	MatCategories = Schema{SoftDelete: true}
+	Services      = Schema{SoftDelete: true}
+	Pages         = Schema{Publishable: true, SoftDelete: true}
+	Tags          = Schema{SoftDelete: true}
+	Revisions     = Schema{Timed: true}
+)
+
+// Filter returns find filter for query
+func Filter(s Schema, q store.ListQuery) bson.M {
+	filter := bson.M{}
+
+	if s.SoftDelete {
+		switch q.Deleted {
+		case store.NotDeleted:
+			filter["deleted"] = false
+		case store.OnlyDeleted:
+			filter["deleted"] = true
+		}
+	}
+
+	if s.CategoryField != "" && !q.CategoryID.IsZero() {
+		filter[s.CategoryField] = q.CategoryID
+	}
+
+	// Equality matches array which contains the value
+	if s.TagField != "" && !q.TagID.IsZero() {
+		filter[s.TagField] = q.TagID
+	}
+
+	if s.Authored && q.Author != "" {
+		filter["author"] = q.Author
+	}
+
+	if s.Publishable && q.Status != "" {
+		filter["status"] = string(q.Status)
+	}
+
+	// Missing field is not matched by comparison, so posts without unpublish_at are skipped
+	if s.Scheduled && !q.UnpublishBefore.IsZero() {
+		filter["unpublish_at"] = bson.M{"$lt": q.UnpublishBefore}
+	}
+
+	if s.Timed && (!q.From.IsZero() || !q.To.IsZero()) {
+		period := bson.M{}
+		if !q.From.IsZero() {
+			period["$gte"] = q.From
+		}
+		if !q.To.IsZero() {
+			period["$lt"] = q.To
+		}
+
+		filter["time"] = period
+	}
+
+	if q.Text != "" {
+		filter["title"] = bson.M{"$regex": regexp.QuoteMeta(q.Text), "$options": "i"}
+	}
+
+	return filter
+}
+
+// Sort returns sort document for query or nil when natural order is used
+func Sort(s Schema, q store.ListQuery) bson.D {
+	switch {
+	case q.Sort == store.SortTitle:
+		return bson.D{{Key: "title", Value: 1}}
+	case !s.Timed:
+		return nil
+	case q.Sort == store.SortOldest:
+		return bson.D{{Key: "time", Value: 1}}
+	default:
+		return bson.D{{Key: "time", Value: -1}}
+	}
+}
+
+// RevisionsOfDocument returns filter and options which select revisions of document without snapshots
+func RevisionsOfDocument(docID primitive.ObjectID, q store.ListQuery) (bson.M, *options.FindOptions) {
+	q.Text = ""
+
+	filter := Filter(Revisions, q)
+	filter["doc_id"] = docID
+
+	return filter, FindOptions(Revisions, q).SetProjection(bson.M{"post": 0, "page": 0})
+}
+
+// TagsByIDs returns filter and options which select live tags with given IDs sorted by title
+func TagsByIDs(IDs []primitive.ObjectID) (bson.M, *options.FindOptions) {
+	return bson.M{"_id": bson.M{"$in": IDs}, "deleted": false}, FindOptions(Tags, store.ListQuery{Sort: store.SortTitle})
+}
+
+// LatestRedirect returns filter and options which select the latest redirect from slug of document type
+func LatestRedirect(docType, slug string) (bson.M, *options.FindOptions) {
+	return bson.M{"doc_type": docType, "slug": slug},
+		options.Find().SetSort(bson.D{{Key: "time", Value: -1}}).SetLimit(1)
+}
+
+// MoveToTrash returns filter and update which mark live document as deleted by editor at given time
+func MoveToTrash(ID primitive.ObjectID, deletedBy string, at time.Time) (bson.M, bson.M) {
+	return bson.M{"_id": ID, "deleted": false},
+		bson.M{"$set": bson.M{"deleted": true, "deleted_at": at, "deleted_by": deletedBy}}
+}
+
+// RestoreFromTrash returns filter and update which bring deleted document back
+func RestoreFromTrash(ID primitive.ObjectID) (bson.M, bson.M) {
+	return InTrash(ID),
+		bson.M{"$set": bson.M{"deleted": false}, "$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+}
+
+// Versioned returns filter of document with given ID and version
+func Versioned(ID primitive.ObjectID, version int64) bson.M {
+	return bson.M{"_id": ID, "version": version}
+}
+
+// SetFields returns update which writes only given fields of doc and increments version
+// Fields absent in doc are unset, because they were emptied
+func SetFields(doc interface{}, fields []string) (bson.M, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	m := bson.M{}
+	if err = bson.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	set, unset := bson.M{}, bson.M{}
+	for _, f := range fields {
+		if v, ok := m[f]; ok {
+			set[f] = v
+		} else {
+			unset[f] = ""
+		}
+	}
+
+	update := bson.M{"$inc": bson.M{"version": 1}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return update, nil
+}
+
+// ReassignChildren returns filter and update which move live children of category to another one
+func ReassignChildren(s Schema, from, to primitive.ObjectID) (bson.M, bson.M) {
+	return Filter(s, store.ListQuery{CategoryID: from}),
+		bson.M{"$set": bson.M{s.CategoryField: to}, "$inc": bson.M{"version": 1}}
+}
+
+// ChildrenToTrash returns filter and update which mark live children of category as deleted by editor at given time
+func ChildrenToTrash(s Schema, categoryID primitive.ObjectID, deletedBy string, at time.Time) (bson.M, bson.M) {
+	return Filter(s, store.ListQuery{CategoryID: categoryID}),
+		bson.M{"$set": bson.M{"deleted": true, "deleted_at": at, "deleted_by": deletedBy}}
+}
+
+// InTrash returns filter of deleted document with given ID
+func InTrash(ID primitive.ObjectID) bson.M {
+	return bson.M{"_id": ID, "deleted": true}
+}
+
+// DeletedBefore returns filter of documents deleted before given time
+func DeletedBefore(t time.Time) bson.M {
+	return bson.M{"deleted": true, "deleted_at": bson.M{"$lt": t}}
+}
+
+// FindOptions returns sort, skip and limit options for query
+func FindOptions(s Schema, q store.ListQuery) *options.FindOptions {
+	opts := options.Find()
+
+	if sort := Sort(s, q); sort != nil {
+		opts.SetSort(sort)
+	}
+
+	if q.Offset > 0 {
+		opts.SetSkip(q.Offset)
+	}
+
+	if q.Limit > 0 {
+		opts.SetLimit(q.Limit)
+	}
+
+	return opts
+}
+
+// paginate returns $match, $sort, $skip and $limit stages for query
+func paginate(s Schema, q store.ListQuery) mongo.Pipeline {
+	return append(mongo.Pipeline{{{Key: "$match", Value: Filter(s, q)}}}, window(s, q)...)
+}
+
+// window returns $sort, $skip and $limit stages for query
+func window(s Schema, q store.ListQuery) mongo.Pipeline {
+	pipeline := mongo.Pipeline{}
+
+	if sort := Sort(s, q); sort != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+
+	if q.Offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: q.Offset}})
+	}
+
+	if q.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: q.Limit}})
+	}
+
+	return pipeline
+}
+
+// withCategory returns stages which join live category to posts
+// Posts of missing or trashed categories are dropped by $unwind, they have no public URL
+// Join precedes pagination, so pages are full and total counts only listed posts
+func withCategory() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "categories"},
+			{Key: "let", Value: bson.D{{Key: "category_id", Value: "$category_id"}}},
+			{Key: "pipeline", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{
+					{Key: "deleted", Value: false},
+					{Key: "$expr", Value: bson.D{
+						{Key: "$eq", Value: bson.A{"$_id", "$$category_id"}},
+					}},
+				}}},
+				bson.D{{Key: "$project", Value: bson.D{
+					{Key: "slug", Value: 1},
+					{Key: "title", Value: 1}}}},
+			}},
+			{Key: "as", Value: "category"}}}},
+		{{Key: "$unwind", Value: "$category"}},
+	}
+}
+
+// shortPost returns stage which keeps short form of posts with slug and title of joined category
+func shortPost() bson.D {
+	return bson.D{{Key: "$project", Value: bson.D{
+		{Key: "title", Value: 1},
+		{Key: "snippet", Value: 1},
+		{Key: "postimg", Value: 1},
+		{Key: "time", Value: 1},
+		{Key: "slug", Value: 1},
+		{Key: "category_slug", Value: "$category.slug"},
+		{Key: "category_title", Value: "$category.title"}}}}
+}
+
+// PostsWithCategory returns pipeline which selects posts for listing and joins slug of their categories
+func PostsWithCategory(q store.ListQuery) mongo.Pipeline {
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: Filter(Posts, q)}}}, withCategory()...)
+	pipeline = append(pipeline, window(Posts, q)...)
+
+	return append(pipeline, shortPost())
+}
+
+// PostsWithTotal returns pipeline which produces single document with page of posts
+// joined with their categories and number of all posts matching query, see store.PostListing
+// Posts are matched and joined once before $facet, so total agrees with pages
+func PostsWithTotal(q store.ListQuery) mongo.Pipeline {
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: Filter(Posts, q)}}}, withCategory()...)
+
+	return append(pipeline,
+		bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "posts", Value: append(window(Posts, q), shortPost())},
+			{Key: "total", Value: mongo.Pipeline{{{Key: "$count", Value: "count"}}}}}}},
+		// $count yields nothing for empty input, so total is missing then and decoded as zero
+		bson.D{{Key: "$unwind", Value: bson.D{
+			{Key: "path", Value: "$total"},
+			{Key: "preserveNullAndEmptyArrays", Value: true}}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "posts", Value: 1},
+			{Key: "total", Value: "$total.count"}}}},
+	)
+}
+
+// MatCategoriesWithMaterials returns pipeline which selects material categories
+// and joins up to perCategory newest live materials to each of them
+// Zero perCategory means all materials
+func MatCategoriesWithMaterials(q store.ListQuery, perCategory int64) mongo.Pipeline {
+	materials := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "deleted", Value: false},
+			{Key: "$expr", Value: bson.D{
+				{Key: "$eq", Value: bson.A{"$matcategory_id", "$$matcat_id"}},
+			}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "time", Value: -1}}}},
+	}
+
+	if perCategory > 0 {
+		materials = append(materials, bson.D{{Key: "$limit", Value: perCategory}})
+	}
+
+	return append(paginate(MatCategories, q),
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "materials"},
+			{Key: "let", Value: bson.D{{Key: "matcat_id", Value: "$_id"}}},
+			{Key: "pipeline", Value: materials},
+			{Key: "as", Value: "materials"}}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 1},
+			{Key: "title", Value: 1},
+			{Key: "slug", Value: 1},
+			{Key: "desc", Value: 1},
+			{Key: "materials", Value: 1}}}},
+	)
+}
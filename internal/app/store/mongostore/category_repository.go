@@ -6,6 +6,8 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,31 +20,29 @@ type CategoryRepository struct {
 }
 
 // Create new category
-func (c *CategoryRepository) Create(cat *models.Category) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (c *CategoryRepository) Create(ctx context.Context, cat *models.Category) error {
 	if err := cat.Validate(); err != nil {
 		return err
 	}
 
-	fcat, _ := c.FindBySlug(cat.Slug)
+	fcat, _ := c.FindBySlug(ctx, cat.Slug)
 	if fcat != nil {
 		return helpers.ErrCategoryAlreadyExist
 	}
 
+	ctx, cancel := c.store.writeContext(ctx)
+	defer cancel()
+
 	db := c.store.db.Database(dbName)
 	col := db.Collection(c.collectionName)
 
-	if _, err := col.InsertOne(ctx, cat); err != nil {
-		return err
-	}
+	_, err := col.InsertOne(ctx, cat)
 
-	return nil
+	return duplicateErr(err, helpers.ErrCategoryAlreadyExist)
 }
 
-func (c *CategoryRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.Category, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (c *CategoryRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.Category, error) {
+	ctx, cancel := c.store.readContext(ctx)
 	defer cancel()
 
 	db := c.store.db.Database(dbName)
@@ -51,33 +51,32 @@ func (c *CategoryRepository) findOne(filter bson.M, opts ...*options.FindOneOpti
 
 	cat := &models.Category{}
 
-	err := res.Decode(cat)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(cat); err != nil {
+		return nil, notFound(err)
 	}
 
 	return cat, nil
 }
 
 // Find category by it ID
-func (c *CategoryRepository) FindByID(ID primitive.ObjectID) (*models.Category, error) {
-	return c.findOne(bson.M{"_id": ID, "deleted": false})
+func (c *CategoryRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Category, error) {
+	return c.findOne(ctx, bson.M{"_id": ID, "deleted": false})
 }
 
 // FindBySlug finds category by it slug
-func (c *CategoryRepository) FindBySlug(slug string) (*models.Category, error) {
-	return c.findOne(bson.M{"slug": slug, "deleted": false})
+func (c *CategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	return c.findOne(ctx, bson.M{"slug": slug, "deleted": false})
 }
 
-// FindAll return all categories
-func (c *CategoryRepository) FindAll(filter bson.M) ([]*models.Category, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// find return all categories with passed filter and find options
+func (c *CategoryRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Category, error) {
+	ctx, cancel := c.store.readContext(ctx)
 	defer cancel()
 
 	db := c.store.db.Database(dbName)
 	col := db.Collection(c.collectionName)
 
-	res, err := col.Find(ctx, filter)
+	res, err := col.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,42 +91,54 @@ func (c *CategoryRepository) FindAll(filter bson.M) ([]*models.Category, error)
 	return cats, nil
 }
 
-// Delete just marks category as deleted
-func (c *CategoryRepository) Delete(deletedID primitive.ObjectID) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// List return categories selected by query
+func (c *CategoryRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Category, error) {
+	return c.find(ctx, mongoquery.Filter(mongoquery.Categories, q), mongoquery.FindOptions(mongoquery.Categories, q))
+}
 
-	db := c.store.db.Database(dbName)
-	col := db.Collection(c.collectionName)
+// Delete moves category to trash
+func (c *CategoryRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return c.store.moveToTrash(ctx, c.collectionName, deletedID, deletedBy)
+}
 
-	_, err := col.UpdateByID(ctx, deletedID, bson.M{"$set": bson.M{"deleted": true}})
+// Restore brings category back from trash unless its slug is taken by another category
+func (c *CategoryRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	category, err := c.findOne(ctx, mongoquery.InTrash(ID))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if fcategory, _ := c.FindBySlug(ctx, category.Slug); fcategory != nil {
+		return helpers.ErrCategoryAlreadyExist
+	}
+
+	return duplicateErr(c.store.restore(ctx, c.collectionName, ID), helpers.ErrCategoryAlreadyExist)
 }
 
-func (c *CategoryRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// Purge permanently removes category from trash
+func (c *CategoryRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return c.store.purge(ctx, c.collectionName, ID)
+}
 
-	db := c.store.db.Database(dbName)
-	col := db.Collection(c.collectionName)
+// PurgeDeletedBefore permanently removes categories deleted before given time
+func (c *CategoryRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return c.store.purgeDeletedBefore(ctx, c.collectionName, before)
+}
 
-	_, err := col.UpdateOne(ctx, filter, update, opts...)
-	if err != nil {
+// Update validate category and try to save it
+func (c *CategoryRepository) Update(ctx context.Context, updatedCategory *models.Category) error {
+	if err := updatedCategory.Validate(); err != nil {
 		return err
 	}
 
-	return nil
+	return duplicateErr(c.store.updateVersion(ctx, c.collectionName, updatedCategory.ID, &updatedCategory.Version, updatedCategory), helpers.ErrCategoryAlreadyExist)
 }
 
-// Update validate category and try to save it
-func (c *CategoryRepository) Update(updatedCategory *models.Category) error {
+// Patch validate category and save only its given fields
+func (c *CategoryRepository) Patch(ctx context.Context, updatedCategory *models.Category, fields []string) error {
 	if err := updatedCategory.Validate(); err != nil {
 		return err
 	}
 
-	return c.updateOne(bson.M{"_id": updatedCategory.ID}, bson.M{"$set": updatedCategory})
+	return duplicateErr(c.store.patchVersion(ctx, c.collectionName, updatedCategory.ID, &updatedCategory.Version, updatedCategory, fields), helpers.ErrCategoryAlreadyExist)
 }
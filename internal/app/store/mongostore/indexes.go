@@ -0,0 +1,147 @@
+package mongostore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notDeleted limits unique indexes to live documents, so slug of deleted document may be reused
+var notDeleted = bson.D{{Key: "deleted", Value: false}}
+
+// uniqueIndex returns unique index on single field for documents matching partial filter
+func uniqueIndex(field string, partial bson.D) mongo.IndexModel {
+	opts := options.Index().SetName(field + "_unique").SetUnique(true)
+	if partial != nil {
+		opts.SetPartialFilterExpression(partial)
+	}
+
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: opts,
+	}
+}
+
+// listingIndex returns compound index used by listings sorted from newest to oldest
+func listingIndex(name string, fields ...string) mongo.IndexModel {
+	keys := bson.D{}
+	for _, f := range fields {
+		keys = append(keys, bson.E{Key: f, Value: 1})
+	}
+	keys = append(keys, bson.E{Key: "time", Value: -1})
+
+	return mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName(name),
+	}
+}
+
+// trashIndex returns index used by listing and purging of deleted documents
+func trashIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetName("deleted_at").
+			SetPartialFilterExpression(bson.D{{Key: "deleted", Value: true}}),
+	}
+}
+
+// unpublishIndex returns index used by scheduler to find posts due to be unpublished
+func unpublishIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "unpublish_at", Value: 1}},
+		Options: options.Index().SetName("status_unpublish_at").
+			SetPartialFilterExpression(bson.D{{Key: "unpublish_at", Value: bson.D{{Key: "$exists", Value: true}}}}),
+	}
+}
+
+// indexes describes all indexes required by repositories queries
+var indexes = map[string][]mongo.IndexModel{
+	"posts": {
+		uniqueIndex("slug", notDeleted),
+		listingIndex("deleted_time", "deleted"),
+		listingIndex("deleted_category_time", "deleted", "category_id"),
+		listingIndex("deleted_tag_time", "deleted", "tag_ids"),
+		listingIndex("deleted_status_time", "deleted", "status"),
+		listingIndex("deleted_status_category_time", "deleted", "status", "category_id"),
+		listingIndex("deleted_status_tag_time", "deleted", "status", "tag_ids"),
+		listingIndex("deleted_status_author_time", "deleted", "status", "author"),
+		unpublishIndex(),
+		trashIndex(),
+	},
+	"categories": {
+		uniqueIndex("slug", notDeleted),
+		trashIndex(),
+	},
+	"materials": {
+		uniqueIndex("slug", notDeleted),
+		listingIndex("deleted_time", "deleted"),
+		listingIndex("deleted_matcategory_time", "deleted", "matcategory_id"),
+		listingIndex("deleted_tag_time", "deleted", "tag_ids"),
+		trashIndex(),
+	},
+	"matcategories": {
+		uniqueIndex("slug", notDeleted),
+		trashIndex(),
+	},
+	"services": {
+		uniqueIndex("slug", notDeleted),
+		trashIndex(),
+	},
+	"pages": {
+		uniqueIndex("url", notDeleted),
+		trashIndex(),
+	},
+	"tags": {
+		uniqueIndex("slug", notDeleted),
+		trashIndex(),
+	},
+	"revisions": {
+		listingIndex("doc_time", "doc_id"),
+	},
+	"redirects": {
+		listingIndex("type_slug_time", "doc_type", "slug"),
+	},
+	"users": {
+		uniqueIndex("username", notDeleted),
+		uniqueIndex("email", bson.D{
+			{Key: "email", Value: bson.D{{Key: "$exists", Value: true}}},
+			{Key: "deleted", Value: false},
+		}),
+		uniqueIndex("author.slug", bson.D{
+			{Key: "author.slug", Value: bson.D{{Key: "$exists", Value: true}}},
+			{Key: "deleted", Value: false},
+		}),
+	},
+}
+
+// EnsureIndexes creates missing indexes, existing indexes with same spec are left untouched
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	db := s.db.Database(dbName)
+
+	for colName, models := range indexes {
+		if _, err := db.Collection(colName).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("create indexes on %s: %w", colName, err)
+		}
+	}
+
+	return nil
+}
+
+// duplicateErr replaces duplicate key error of unique index with friendly error
+// Indexes guard against concurrent inserts that passed check in Create
+func duplicateErr(err error, existErr error) error {
+	if err != nil && mongo.IsDuplicateKeyError(err) {
+		return existErr
+	}
+
+	return err
+}
+
+// duplicateField returns true when err is violation of unique index on field
+func duplicateField(err error, field string) bool {
+	return mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), field+"_unique")
+}
@@ -0,0 +1,37 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// updateMany applies update to every document of collection that match filter and returns number of matched documents
+func (s *MongoStore) updateMany(ctx context.Context, collectionName string, filter, update bson.M) (int64, error) {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	res, err := s.db.Database(dbName).Collection(collectionName).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+// reassignChildren moves live children of category to another one
+func (s *MongoStore) reassignChildren(ctx context.Context, collectionName string, schema mongoquery.Schema, from, to primitive.ObjectID) (int64, error) {
+	filter, update := mongoquery.ReassignChildren(schema, from, to)
+
+	return s.updateMany(ctx, collectionName, filter, update)
+}
+
+// childrenToTrash marks live children of category as deleted
+func (s *MongoStore) childrenToTrash(ctx context.Context, collectionName string, schema mongoquery.Schema, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	filter, update := mongoquery.ChildrenToTrash(schema, categoryID, deletedBy, time.Now())
+
+	return s.updateMany(ctx, collectionName, filter, update)
+}
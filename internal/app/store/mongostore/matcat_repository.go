@@ -6,9 +6,10 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -19,31 +20,29 @@ type MatCatRepository struct {
 }
 
 // Create new material category
-func (m MatCatRepository) Create(matcat *models.MatCategory) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (m MatCatRepository) Create(ctx context.Context, matcat *models.MatCategory) error {
 	if err := matcat.Validate(); err != nil {
 		return err
 	}
 
-	fcat, _ := m.FindBySlug(matcat.Slug)
+	fcat, _ := m.FindBySlug(ctx, matcat.Slug)
 	if fcat != nil {
 		return helpers.ErrMatCategoryAlreadyExist
 	}
 
+	ctx, cancel := m.store.writeContext(ctx)
+	defer cancel()
+
 	db := m.store.db.Database(dbName)
 	col := db.Collection(m.collectionName)
 
-	if _, err := col.InsertOne(ctx, matcat); err != nil {
-		return err
-	}
+	_, err := col.InsertOne(ctx, matcat)
 
-	return nil
+	return duplicateErr(err, helpers.ErrMatCategoryAlreadyExist)
 }
 
-func (m *MatCatRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.MatCategory, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MatCatRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.MatCategory, error) {
+	ctx, cancel := m.store.readContext(ctx)
 	defer cancel()
 
 	db := m.store.db.Database(dbName)
@@ -52,33 +51,32 @@ func (m *MatCatRepository) findOne(filter bson.M, opts ...*options.FindOneOption
 
 	matcat := &models.MatCategory{}
 
-	err := res.Decode(matcat)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(matcat); err != nil {
+		return nil, notFound(err)
 	}
 
 	return matcat, nil
 }
 
 // FindBySlug material category by slug
-func (m MatCatRepository) FindBySlug(slug string) (*models.MatCategory, error) {
-	return m.findOne(bson.M{"slug": slug, "deleted": false})
+func (m MatCatRepository) FindBySlug(ctx context.Context, slug string) (*models.MatCategory, error) {
+	return m.findOne(ctx, bson.M{"slug": slug, "deleted": false})
 }
 
 // FindByID material category by it ID
-func (m MatCatRepository) FindByID(ID primitive.ObjectID) (*models.MatCategory, error) {
-	return m.findOne(bson.M{"_id": ID, "deleted": false})
+func (m MatCatRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.MatCategory, error) {
+	return m.findOne(ctx, bson.M{"_id": ID, "deleted": false})
 }
 
-// FindAll return all material repositories with specified filter
-func (m MatCatRepository) FindAll(filter bson.M) ([]*models.MatCategory, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// find return all material categories with passed filter and find options
+func (m MatCatRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.MatCategory, error) {
+	ctx, cancel := m.store.readContext(ctx)
 	defer cancel()
 
 	db := m.store.db.Database(dbName)
 	col := db.Collection(m.collectionName)
 
-	res, err := col.Find(ctx, filter)
+	res, err := col.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -93,15 +91,20 @@ func (m MatCatRepository) FindAll(filter bson.M) ([]*models.MatCategory, error)
 	return matcats, nil
 }
 
-// Aggregate used to find and join materials and materials' categories for rendering in browser
-func (m MatCatRepository) Aggregate(pipeline mongo.Pipeline, opts ...*options.AggregateOptions) ([]*models.MaterialShow, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// List return material categories selected by query
+func (m MatCatRepository) List(ctx context.Context, q store.ListQuery) ([]*models.MatCategory, error) {
+	return m.find(ctx, mongoquery.Filter(mongoquery.MatCategories, q), mongoquery.FindOptions(mongoquery.MatCategories, q))
+}
+
+// ListWithMaterials used to find and join materials and materials' categories for rendering in browser
+func (m MatCatRepository) ListWithMaterials(ctx context.Context, q store.ListQuery, perCategory int64) ([]*models.MaterialShow, error) {
+	ctx, cancel := m.store.aggregateContext(ctx)
 	defer cancel()
 
 	db := m.store.db.Database(dbName)
 	col := db.Collection(m.collectionName)
 
-	res, err := col.Aggregate(ctx, pipeline, opts...)
+	res, err := col.Aggregate(ctx, mongoquery.MatCategoriesWithMaterials(q, perCategory))
 	if err != nil {
 		return nil, err
 	}
@@ -116,31 +119,49 @@ func (m MatCatRepository) Aggregate(pipeline mongo.Pipeline, opts ...*options.Ag
 	return mats, nil
 }
 
-func (m MatCatRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// Update validate matcategory and try to save it
+func (m MatCatRepository) Update(ctx context.Context, updatedMatCategory *models.MatCategory) error {
+	if err := updatedMatCategory.Validate(); err != nil {
+		return err
+	}
 
-	db := m.store.db.Database(dbName)
-	col := db.Collection(m.collectionName)
+	return duplicateErr(m.store.updateVersion(ctx, m.collectionName, updatedMatCategory.ID, &updatedMatCategory.Version, updatedMatCategory), helpers.ErrMatCategoryAlreadyExist)
+}
 
-	_, err := col.UpdateOne(ctx, filter, update, opts...)
-	if err != nil {
+// Patch validate matcategory and save only its given fields
+func (m MatCatRepository) Patch(ctx context.Context, updatedMatCategory *models.MatCategory, fields []string) error {
+	if err := updatedMatCategory.Validate(); err != nil {
 		return err
 	}
 
-	return nil
+	return duplicateErr(m.store.patchVersion(ctx, m.collectionName, updatedMatCategory.ID, &updatedMatCategory.Version, updatedMatCategory, fields), helpers.ErrMatCategoryAlreadyExist)
 }
 
-// Update validate matcategory and try to save it
-func (m MatCatRepository) Update(updatedMatCategory *models.MatCategory) error {
-	if err := updatedMatCategory.Validate(); err != nil {
+// Delete moves material category to trash
+func (m MatCatRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return m.store.moveToTrash(ctx, m.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings material category back from trash unless its slug is taken by another material category
+func (m MatCatRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	matcat, err := m.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
 		return err
 	}
 
-	return m.updateOne(bson.M{"_id": updatedMatCategory.ID}, bson.M{"$set": updatedMatCategory})
+	if fmatcat, _ := m.FindBySlug(ctx, matcat.Slug); fmatcat != nil {
+		return helpers.ErrMatCategoryAlreadyExist
+	}
+
+	return duplicateErr(m.store.restore(ctx, m.collectionName, ID), helpers.ErrMatCategoryAlreadyExist)
+}
+
+// Purge permanently removes material category from trash
+func (m MatCatRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return m.store.purge(ctx, m.collectionName, ID)
 }
 
-// Delete marks material category as deleted
-func (m MatCatRepository) Delete(deletedID primitive.ObjectID) error {
-	return m.updateOne(bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+// PurgeDeletedBefore permanently removes material categories deleted before given time
+func (m MatCatRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.store.purgeDeletedBefore(ctx, m.collectionName, before)
 }
@@ -6,6 +6,8 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,31 +20,29 @@ type MaterialRepository struct {
 }
 
 // Create save new material
-func (m MaterialRepository) Create(material *models.Material) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (m MaterialRepository) Create(ctx context.Context, material *models.Material) error {
 	if err := material.Validate(); err != nil {
 		return err
 	}
 
-	fmaterial, _ := m.FindBySlug(material.Slug)
+	fmaterial, _ := m.FindBySlug(ctx, material.Slug)
 	if fmaterial != nil {
 		return helpers.ErrMaterialAlreadyExist
 	}
 
+	ctx, cancel := m.store.writeContext(ctx)
+	defer cancel()
+
 	db := m.store.db.Database(dbName)
 	col := db.Collection(m.collectionName)
 
-	if _, err := col.InsertOne(ctx, material); err != nil {
-		return err
-	}
+	_, err := col.InsertOne(ctx, material)
 
-	return nil
+	return duplicateErr(err, helpers.ErrMaterialAlreadyExist)
 }
 
-func (m *MaterialRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.Material, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (m *MaterialRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.Material, error) {
+	ctx, cancel := m.store.readContext(ctx)
 	defer cancel()
 
 	db := m.store.db.Database(dbName)
@@ -51,9 +51,8 @@ func (m *MaterialRepository) findOne(filter bson.M, opts ...*options.FindOneOpti
 
 	material := &models.Material{}
 
-	err := res.Decode(material)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(material); err != nil {
+		return nil, notFound(err)
 	}
 
 	return material, nil
@@ -61,24 +60,24 @@ func (m *MaterialRepository) findOne(filter bson.M, opts ...*options.FindOneOpti
 }
 
 // FindBySlug lookup material by it slug
-func (m MaterialRepository) FindBySlug(slug string) (*models.Material, error) {
-	return m.findOne(bson.M{"slug": slug, "deleted": false})
+func (m MaterialRepository) FindBySlug(ctx context.Context, slug string) (*models.Material, error) {
+	return m.findOne(ctx, bson.M{"slug": slug, "deleted": false})
 }
 
 // FindByID lookup material by ID
-func (m MaterialRepository) FindByID(ID primitive.ObjectID) (*models.Material, error) {
-	return m.findOne(bson.M{"_id": ID, "deleted": false})
+func (m MaterialRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Material, error) {
+	return m.findOne(ctx, bson.M{"_id": ID, "deleted": false})
 }
 
-// FindAll return all materials by filter parameter
-func (m MaterialRepository) FindAll(filter bson.M) ([]*models.Material, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// find return all materials with passed filter and find options
+func (m MaterialRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Material, error) {
+	ctx, cancel := m.store.readContext(ctx)
 	defer cancel()
 
 	db := m.store.db.Database(dbName)
 	col := db.Collection(m.collectionName)
 
-	res, err := col.Find(ctx, filter)
+	res, err := col.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -93,65 +92,75 @@ func (m MaterialRepository) FindAll(filter bson.M) ([]*models.Material, error) {
 	return materials, nil
 }
 
-// Find return slice of material with filter and find options
-func (m MaterialRepository) Find(filter bson.M, opts ...*options.FindOptions) ([]*models.Material, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	db := m.store.db.Database(dbName)
-	col := db.Collection(m.collectionName)
+// List return materials selected by query
+func (m MaterialRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Material, error) {
+	return m.find(ctx, mongoquery.Filter(mongoquery.Materials, q), mongoquery.FindOptions(mongoquery.Materials, q))
+}
 
-	res, err := col.Find(ctx, filter, opts...)
-	if err != nil {
-		return nil, err
+// Update recieve material, validate it and try to update it
+func (m MaterialRepository) Update(ctx context.Context, updatedMaterial *models.Material) error {
+	if err := updatedMaterial.Validate(); err != nil {
+		return err
 	}
 
-	materials := make([]*models.Material, 0)
+	return duplicateErr(m.store.updateVersion(ctx, m.collectionName, updatedMaterial.ID, &updatedMaterial.Version, updatedMaterial), helpers.ErrMaterialAlreadyExist)
+}
 
-	err = res.All(ctx, &materials)
-	if err != nil {
-		return nil, err
+// Patch validate material and save only its given fields
+func (m MaterialRepository) Patch(ctx context.Context, updatedMaterial *models.Material, fields []string) error {
+	if err := updatedMaterial.Validate(); err != nil {
+		return err
 	}
 
-	return materials, nil
+	return duplicateErr(m.store.patchVersion(ctx, m.collectionName, updatedMaterial.ID, &updatedMaterial.Version, updatedMaterial, fields), helpers.ErrMaterialAlreadyExist)
 }
 
-func (m MaterialRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	db := m.store.db.Database(dbName)
-	col := db.Collection(m.collectionName)
+// Delete moves material to trash
+func (m MaterialRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return m.store.moveToTrash(ctx, m.collectionName, deletedID, deletedBy)
+}
 
-	_, err := col.UpdateOne(ctx, filter, update, opts...)
+// Restore brings material back from trash unless its slug is taken by another material
+func (m MaterialRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	material, err := m.findOne(ctx, mongoquery.InTrash(ID))
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-// Update recieve material, validate it and try to update it
-func (m MaterialRepository) Update(updatedMaterial *models.Material) error {
-	if err := updatedMaterial.Validate(); err != nil {
-		return err
+	if fmaterial, _ := m.FindBySlug(ctx, material.Slug); fmaterial != nil {
+		return helpers.ErrMaterialAlreadyExist
 	}
 
-	return m.updateOne(bson.M{"_id": updatedMaterial.ID}, bson.M{"$set": updatedMaterial})
+	return duplicateErr(m.store.restore(ctx, m.collectionName, ID), helpers.ErrMaterialAlreadyExist)
 }
 
-// Delete marks post as deleted
-func (m MaterialRepository) Delete(deletedID primitive.ObjectID) error {
-	return m.updateOne(bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+// Purge permanently removes material from trash
+func (m MaterialRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return m.store.purge(ctx, m.collectionName, ID)
 }
 
-// Count return number of materials that match filter with opts
-func (m MaterialRepository) Count(filter interface{}, opts ...*options.CountOptions) (int64, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// PurgeDeletedBefore permanently removes materials deleted before given time
+func (m MaterialRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.store.purgeDeletedBefore(ctx, m.collectionName, before)
+}
+
+// Count return number of materials selected by query
+func (m MaterialRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	ctx, cancel := m.store.readContext(ctx)
 	defer cancel()
 
 	db := m.store.db.Database(dbName)
 	col := db.Collection(m.collectionName)
 
-	return col.CountDocuments(ctx, filter, opts...)
+	return col.CountDocuments(ctx, mongoquery.Filter(mongoquery.Materials, q))
+}
+
+// ReassignCategory moves live materials of category to another one
+func (m MaterialRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	return m.store.reassignChildren(ctx, m.collectionName, mongoquery.Materials, from, to)
+}
+
+// DeleteByCategory moves live materials of category to trash
+func (m MaterialRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	return m.store.childrenToTrash(ctx, m.collectionName, mongoquery.Materials, categoryID, deletedBy)
 }
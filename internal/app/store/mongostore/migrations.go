@@ -0,0 +1,180 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationsCollection keeps records about applied migrations
+const migrationsCollection = "migrations"
+
+// Migration describes single versioned change of stored documents
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// appliedMigration represents record of migrationsCollection
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// migrations must be ordered by version, applied migration must never be changed
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "set deleted flag where it is missing",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			for _, colName := range []string{"posts", "categories", "materials", "matcategories", "services", "users"} {
+				_, err := db.Collection(colName).UpdateMany(ctx,
+					bson.M{"deleted": bson.M{"$exists": false}},
+					bson.M{"$set": bson.M{"deleted": false}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     2,
+		Description: "track deletion time and soft delete pages",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("pages").UpdateMany(ctx,
+				bson.M{"deleted": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"deleted": false}},
+			)
+			if err != nil {
+				return err
+			}
+
+			// Unique url is kept only among live pages, index is recreated by EnsureIndexes
+			_, err = db.Collection("pages").Indexes().DropOne(ctx, "url_unique")
+			if err != nil && !isMissingIndex(err) {
+				return err
+			}
+
+			// Retention period of already deleted documents starts now
+			for _, colName := range []string{"posts", "categories", "materials", "matcategories", "services", "pages"} {
+				_, err = db.Collection(colName).UpdateMany(ctx,
+					bson.M{"deleted": true, "deleted_at": bson.M{"$exists": false}},
+					bson.M{"$set": bson.M{"deleted_at": time.Now()}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     3,
+		Description: "set version where it is missing",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			for _, colName := range []string{"posts", "categories", "materials", "matcategories", "services", "pages"} {
+				_, err := db.Collection(colName).UpdateMany(ctx,
+					bson.M{"version": bson.M{"$exists": false}},
+					bson.M{"$set": bson.M{"version": int64(0)}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     4,
+		Description: "set publication status of existing posts",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Existing posts were visible, so they are published
+			_, err := db.Collection("posts").UpdateMany(ctx,
+				bson.M{"status": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"status": "published"}},
+			)
+
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "set publication status of existing pages",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Existing pages were visible, so they are published
+			_, err := db.Collection("pages").UpdateMany(ctx,
+				bson.M{"status": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"status": "published"}},
+			)
+
+			return err
+		},
+	},
+}
+
+// isMissingIndex returns true when err reports absent index or collection
+func isMissingIndex(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == 26 || cmdErr.Code == 27 // NamespaceNotFound, IndexNotFound
+	}
+
+	return false
+}
+
+// Migrate applies pending migrations in order of versions and returns descriptions of applied ones
+func (s *MongoStore) Migrate(ctx context.Context) ([]string, error) {
+	db := s.db.Database(dbName)
+	col := db.Collection(migrationsCollection)
+
+	cur, err := col.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var done []appliedMigration
+	if err = cur.All(ctx, &done); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(done))
+	for _, m := range done {
+		applied[m.Version] = true
+	}
+
+	var res []string
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		if err = m.Up(ctx, db); err != nil {
+			return res, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+
+		_, err = col.InsertOne(ctx, appliedMigration{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return res, err
+		}
+
+		res = append(res, fmt.Sprintf("%d: %s", m.Version, m.Description))
+	}
+
+	return res, nil
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,31 +20,29 @@ type PageRepository struct {
 }
 
 // Create save new page
-func (p PageRepository) Create(page *models.Page) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (p PageRepository) Create(ctx context.Context, page *models.Page) error {
 	if err := page.Validate(); err != nil {
 		return err
 	}
 
-	fpage, _ := p.FindByURL(page.URL)
+	fpage, _ := p.FindByURL(ctx, page.URL)
 	if fpage != nil {
 		return helpers.ErrPageAlreadyExist
 	}
 
+	ctx, cancel := p.store.writeContext(ctx)
+	defer cancel()
+
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	if _, err := col.InsertOne(ctx, page); err != nil {
-		return err
-	}
+	_, err := col.InsertOne(ctx, page)
 
-	return nil
+	return duplicateErr(err, helpers.ErrPageAlreadyExist)
 }
 
-func (p *PageRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.Page, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (p *PageRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.Page, error) {
+	ctx, cancel := p.store.readContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
@@ -52,9 +52,8 @@ func (p *PageRepository) findOne(filter bson.M, opts ...*options.FindOneOptions)
 
 	page := &models.Page{}
 
-	err := res.Decode(page)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(page); err != nil {
+		return nil, notFound(err)
 	}
 
 	return page, nil
@@ -62,29 +61,29 @@ func (p *PageRepository) findOne(filter bson.M, opts ...*options.FindOneOptions)
 }
 
 // FindBySlug lookup page by it slug
-func (p PageRepository) FindBySlug(slug string) (*models.Page, error) {
-	return p.findOne(bson.M{"slug": slug})
+func (p PageRepository) FindBySlug(ctx context.Context, slug string) (*models.Page, error) {
+	return p.findOne(ctx, bson.M{"slug": slug})
 }
 
 // FindByURL return page by it URL
-func (p PageRepository) FindByURL(URL string) (*models.Page, error) {
-	return p.findOne(bson.M{"url": URL})
+func (p PageRepository) FindByURL(ctx context.Context, URL string) (*models.Page, error) {
+	return p.findOne(ctx, bson.M{"url": URL, "deleted": false})
 }
 
 // FindByID return page by it ID
-func (p PageRepository) FindByID(ID primitive.ObjectID) (*models.Page, error) {
-	return p.findOne(bson.M{"_id": ID})
+func (p PageRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Page, error) {
+	return p.findOne(ctx, bson.M{"_id": ID, "deleted": false})
 }
 
-// FindAll return all pages with specified filter
-func (p PageRepository) FindAll(filter bson.M) ([]*models.Page, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// find return all pages with passed filter and find options
+func (p PageRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Page, error) {
+	ctx, cancel := p.store.readContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	res, err := col.Find(ctx, filter)
+	res, err := col.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -99,31 +98,54 @@ func (p PageRepository) FindAll(filter bson.M) ([]*models.Page, error) {
 	return pages, nil
 }
 
-func (p PageRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	db := p.store.db.Database(dbName)
-	col := db.Collection(p.collectionName)
+// List return pages selected by query
+func (p PageRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Page, error) {
+	return p.find(ctx, mongoquery.Filter(mongoquery.Pages, q), mongoquery.FindOptions(mongoquery.Pages, q))
+}
 
-	_, err := col.UpdateOne(ctx, filter, update, opts...)
-	if err != nil {
+// Update validate update page model and try to update it in db
+func (p PageRepository) Update(ctx context.Context, updatedPage *models.Page) error {
+	if err := updatedPage.Validate(); err != nil {
 		return err
 	}
 
-	return nil
+	return duplicateErr(p.store.updateVersion(ctx, p.collectionName, updatedPage.ID, &updatedPage.Version, updatedPage), helpers.ErrPageAlreadyExist)
 }
 
-// Update validate update page model and try to update it in db
-func (p PageRepository) Update(updatedPage *models.Page) error {
+// Patch validate page and save only its given fields
+func (p PageRepository) Patch(ctx context.Context, updatedPage *models.Page, fields []string) error {
 	if err := updatedPage.Validate(); err != nil {
 		return err
 	}
 
-	return p.updateOne(bson.M{"_id": updatedPage.ID}, bson.M{"$set": updatedPage})
+	return duplicateErr(p.store.patchVersion(ctx, p.collectionName, updatedPage.ID, &updatedPage.Version, updatedPage, fields), helpers.ErrPageAlreadyExist)
+}
+
+// Delete moves page to trash
+func (p PageRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return p.store.moveToTrash(ctx, p.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings page back from trash unless its url is taken by another page
+func (p PageRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	page, err := p.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fpage, _ := p.FindByURL(ctx, page.URL); fpage != nil {
+		return helpers.ErrPageAlreadyExist
+	}
+
+	return duplicateErr(p.store.restore(ctx, p.collectionName, ID), helpers.ErrPageAlreadyExist)
+}
+
+// Purge permanently removes page from trash
+func (p PageRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return p.store.purge(ctx, p.collectionName, ID)
 }
 
-// Delete marks page as deleted
-func (p PageRepository) Delete(deletedID primitive.ObjectID) error {
-	return p.updateOne(bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+// PurgeDeletedBefore permanently removes pages deleted before given time
+func (p PageRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.store.purgeDeletedBefore(ctx, p.collectionName, before)
 }
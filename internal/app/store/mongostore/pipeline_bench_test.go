@@ -0,0 +1,119 @@
+package mongostore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	benchCategories       = 20
+	benchPostsPerCategory = 250
+	benchPerPage          = 15
+)
+
+// seedPosts fills empty database with categories and published posts spread over time
+func seedPosts(b *testing.B, dbURL string) (*MongoStore, []*models.Category) {
+	ctx := context.Background()
+	s := testStore(b, dbURL)
+	categories := make([]*models.Category, benchCategories)
+	posts := make([]interface{}, 0, benchCategories*benchPostsPerCategory)
+	base := time.Now()
+
+	for i := range categories {
+		categories[i] = storetest.Category(fmt.Sprintf("Категория номер %d", i))
+		if err := s.Categories().Create(ctx, categories[i]); err != nil {
+			b.Fatal(err)
+		}
+
+		for j := 0; j < benchPostsPerCategory; j++ {
+			post := storetest.Post(fmt.Sprintf("Запись %d-%d", i, j), categories[i].ID)
+			post.Time = base.Add(-time.Duration(j*benchCategories+i) * time.Minute)
+			posts = append(posts, post)
+		}
+	}
+
+	// Posts are inserted at once, seeding through repository would take longer than benchmark
+	if _, err := s.db.Database(dbName).Collection("posts").InsertMany(ctx, posts); err != nil {
+		b.Fatal(err)
+	}
+
+	return s, categories
+}
+
+// lookupFirst is pipeline of listing which joins categories to every post before $match and $skip
+// Listings used it together with separate count before PostsWithTotal
+func lookupFirst(q store.ListQuery) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "categories"},
+			{Key: "localField", Value: "category_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "category_slug"}}}},
+		{{Key: "$match", Value: mongoquery.Filter(mongoquery.Posts, q)}},
+		{{Key: "$sort", Value: mongoquery.Sort(mongoquery.Posts, q)}},
+		{{Key: "$skip", Value: q.Offset}},
+		{{Key: "$limit", Value: q.Limit}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "title", Value: 1},
+			{Key: "snippet", Value: 1},
+			{Key: "postimg", Value: 1},
+			{Key: "time", Value: 1},
+			{Key: "slug", Value: 1},
+			{Key: "category_slug", Value: "$category_slug.slug"}}}},
+		{{Key: "$unwind", Value: "$category_slug"}},
+	}
+}
+
+// BenchmarkPostListing compares count with lookup-first aggregation, which listings used before,
+// and single PostsWithTotal aggregation for the whole listing and for one category
+func BenchmarkPostListing(b *testing.B) {
+	dbURL := os.Getenv(testURLEnv)
+	if dbURL == "" {
+		b.Skipf("%s is not set", testURLEnv)
+	}
+
+	ctx := context.Background()
+	s, categories := seedPosts(b, dbURL)
+	posts := s.Posts().(*PostRepository)
+
+	queries := map[string]store.ListQuery{
+		"All":      {Offset: 5 * benchPerPage, Limit: benchPerPage},
+		"Category": {CategoryID: categories[0].ID, Offset: 5 * benchPerPage, Limit: benchPerPage},
+	}
+
+	for name, q := range queries {
+		q := q
+		q.Deleted = store.NotDeleted
+		q.Status = models.StatusPublished
+
+		b.Run(name+"/CountAndLookupFirst", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := posts.Count(ctx, q); err != nil {
+					b.Fatal(err)
+				}
+
+				if _, err := posts.aggregate(ctx, lookupFirst(q)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(name+"/WithTotal", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := posts.ListPublishedWithTotal(ctx, q); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -19,31 +21,29 @@ type PostRepository struct {
 }
 
 // Create save new post
-func (p PostRepository) Create(post *models.Post) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (p PostRepository) Create(ctx context.Context, post *models.Post) error {
 	if err := post.Validate(); err != nil {
 		return err
 	}
 
-	fpost, _ := p.FindBySlug(post.Slug)
+	fpost, _ := p.FindBySlug(ctx, post.Slug)
 	if fpost != nil {
 		return helpers.ErrPostAlreadyExist
 	}
 
+	ctx, cancel := p.store.writeContext(ctx)
+	defer cancel()
+
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	if _, err := col.InsertOne(ctx, post); err != nil {
-		return err
-	}
+	_, err := col.InsertOne(ctx, post)
 
-	return nil
+	return duplicateErr(err, helpers.ErrPostAlreadyExist)
 }
 
-func (p *PostRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.Post, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (p *PostRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.Post, error) {
+	ctx, cancel := p.store.readContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
@@ -53,9 +53,8 @@ func (p *PostRepository) findOne(filter bson.M, opts ...*options.FindOneOptions)
 
 	post := &models.Post{}
 
-	err := res.Decode(post)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(post); err != nil {
+		return nil, notFound(err)
 	}
 
 	return post, nil
@@ -63,24 +62,24 @@ func (p *PostRepository) findOne(filter bson.M, opts ...*options.FindOneOptions)
 }
 
 // FindBySlug lookup post by it slug
-func (p PostRepository) FindBySlug(slug string) (*models.Post, error) {
-	return p.findOne(bson.M{"slug": slug, "deleted": false})
+func (p PostRepository) FindBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return p.findOne(ctx, bson.M{"slug": slug, "deleted": false})
 }
 
 // FindByID lookup post by it ID
-func (p PostRepository) FindByID(ID primitive.ObjectID) (*models.Post, error) {
-	return p.findOne(bson.M{"_id": ID, "deleted": false})
+func (p PostRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Post, error) {
+	return p.findOne(ctx, bson.M{"_id": ID, "deleted": false})
 }
 
-// FindAll return all posts with specified filter
-func (p PostRepository) FindAll(filter bson.M) ([]*models.Post, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// find return all posts with passed filter and find options
+func (p PostRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Post, error) {
+	ctx, cancel := p.store.readContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	res, err := col.Find(ctx, filter)
+	res, err := col.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -95,16 +94,20 @@ func (p PostRepository) FindAll(filter bson.M) ([]*models.Post, error) {
 	return posts, nil
 }
 
-// Find return all posts with passed filter and find options
-// This method is real projection to db find method
-func (p PostRepository) Find(filter bson.M, opts ...*options.FindOptions) ([]*models.Post, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// List return posts selected by query
+func (p PostRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	return p.find(ctx, mongoquery.Filter(mongoquery.Posts, q), mongoquery.FindOptions(mongoquery.Posts, q))
+}
+
+// aggregate gives opportunity to create more complex queries including 'joins' and etc
+func (p PostRepository) aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) ([]*models.Post, error) {
+	ctx, cancel := p.store.aggregateContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	res, err := col.Find(ctx, filter, opts...)
+	res, err := col.Aggregate(ctx, pipeline, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -119,65 +122,102 @@ func (p PostRepository) Find(filter bson.M, opts ...*options.FindOptions) ([]*mo
 	return posts, nil
 }
 
-// Aggregate gives opportunity to create more complex queries including 'joins' and etc
-func (p PostRepository) Aggregate(pipeline mongo.Pipeline, opts ...*options.AggregateOptions) ([]*models.Post, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// ListPublishedWithCategory return published posts selected by query with joined category slug
+func (p PostRepository) ListPublishedWithCategory(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	q.Deleted = store.NotDeleted
+	q.Status = models.StatusPublished
+
+	return p.aggregate(ctx, mongoquery.PostsWithCategory(q))
+}
+
+// ListPublishedWithTotal return page of published posts with joined category and number of all posts selected by query
+func (p PostRepository) ListPublishedWithTotal(ctx context.Context, q store.ListQuery) (*store.PostListing, error) {
+	q.Deleted = store.NotDeleted
+	q.Status = models.StatusPublished
+
+	ctx, cancel := p.store.aggregateContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	res, err := col.Aggregate(ctx, pipeline, opts...)
+	res, err := col.Aggregate(ctx, mongoquery.PostsWithTotal(q))
 	if err != nil {
 		return nil, err
 	}
 
-	posts := make([]*models.Post, 0)
-
-	err = res.All(ctx, &posts)
-	if err != nil {
+	listings := make([]*store.PostListing, 0, 1)
+	if err = res.All(ctx, &listings); err != nil {
 		return nil, err
 	}
 
-	return posts, nil
+	return store.FirstListing(listings), nil
 }
 
-// Count return number of posts that match filter with opts
-func (p PostRepository) Count(filter interface{}, opts ...*options.CountOptions) (int64, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// Count return number of posts selected by query
+func (p PostRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	ctx, cancel := p.store.readContext(ctx)
 	defer cancel()
 
 	db := p.store.db.Database(dbName)
 	col := db.Collection(p.collectionName)
 
-	return col.CountDocuments(ctx, filter, opts...)
+	return col.CountDocuments(ctx, mongoquery.Filter(mongoquery.Posts, q))
 }
 
-func (p PostRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// Update recieve post, validate it and try to update it
+func (p PostRepository) Update(ctx context.Context, updatedPost *models.Post) error {
+	if err := updatedPost.Validate(); err != nil {
+		return err
+	}
 
-	db := p.store.db.Database(dbName)
-	col := db.Collection(p.collectionName)
+	return duplicateErr(p.store.updateVersion(ctx, p.collectionName, updatedPost.ID, &updatedPost.Version, updatedPost), helpers.ErrPostAlreadyExist)
+}
 
-	_, err := col.UpdateOne(ctx, filter, update, opts...)
-	if err != nil {
+// Patch validate post and save only its given fields
+func (p PostRepository) Patch(ctx context.Context, updatedPost *models.Post, fields []string) error {
+	if err := updatedPost.Validate(); err != nil {
 		return err
 	}
 
-	return nil
+	return duplicateErr(p.store.patchVersion(ctx, p.collectionName, updatedPost.ID, &updatedPost.Version, updatedPost, fields), helpers.ErrPostAlreadyExist)
 }
 
-// Update recieve post, validate it and try to update it
-func (p PostRepository) Update(updatedPost *models.Post) error {
-	if err := updatedPost.Validate(); err != nil {
+// Delete moves post to trash
+func (p PostRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return p.store.moveToTrash(ctx, p.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings post back from trash unless its slug is taken by another post
+func (p PostRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	post, err := p.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
 		return err
 	}
 
-	return p.updateOne(bson.M{"_id": updatedPost.ID}, bson.M{"$set": updatedPost})
+	if fpost, _ := p.FindBySlug(ctx, post.Slug); fpost != nil {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	return duplicateErr(p.store.restore(ctx, p.collectionName, ID), helpers.ErrPostAlreadyExist)
+}
+
+// Purge permanently removes post from trash
+func (p PostRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return p.store.purge(ctx, p.collectionName, ID)
+}
+
+// PurgeDeletedBefore permanently removes posts deleted before given time
+func (p PostRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.store.purgeDeletedBefore(ctx, p.collectionName, before)
+}
+
+// ReassignCategory moves live posts of category to another one
+func (p PostRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	return p.store.reassignChildren(ctx, p.collectionName, mongoquery.Posts, from, to)
 }
 
-// Delete marks post as deleted
-func (p PostRepository) Delete(deletedID primitive.ObjectID) error {
-	return p.updateOne(bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+// DeleteByCategory moves live posts of category to trash
+func (p PostRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	return p.store.childrenToTrash(ctx, p.collectionName, mongoquery.Posts, categoryID, deletedBy)
 }
@@ -0,0 +1,53 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+)
+
+// RedirectRepository implements IRedirectRepository
+type RedirectRepository struct {
+	store          *MongoStore
+	collectionName string
+}
+
+// Create save new redirect
+func (r RedirectRepository) Create(ctx context.Context, redirect *models.Redirect) error {
+	if err := redirect.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.store.writeContext(ctx)
+	defer cancel()
+
+	_, err := r.store.db.Database(dbName).Collection(r.collectionName).InsertOne(ctx, redirect)
+
+	return err
+}
+
+// FindBySlug lookup the latest redirect from slug of document type
+func (r RedirectRepository) FindBySlug(ctx context.Context, docType, slug string) (*models.Redirect, error) {
+	ctx, cancel := r.store.readContext(ctx)
+	defer cancel()
+
+	filter, opts := mongoquery.LatestRedirect(docType, slug)
+
+	res, err := r.store.db.Database(dbName).Collection(r.collectionName).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	redirects := make([]*models.Redirect, 0, 1)
+	if err = res.All(ctx, &redirects); err != nil {
+		return nil, err
+	}
+
+	if len(redirects) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	return redirects[0], nil
+}
@@ -0,0 +1,67 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevisionRepository implements IRevisionRepository
+type RevisionRepository struct {
+	store          *MongoStore
+	collectionName string
+}
+
+// Create save new revision
+func (r RevisionRepository) Create(ctx context.Context, rev *models.Revision) error {
+	if err := rev.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := r.store.writeContext(ctx)
+	defer cancel()
+
+	_, err := r.store.db.Database(dbName).Collection(r.collectionName).InsertOne(ctx, rev)
+
+	return err
+}
+
+// FindByID lookup revision by it ID
+func (r RevisionRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Revision, error) {
+	ctx, cancel := r.store.readContext(ctx)
+	defer cancel()
+
+	rev := &models.Revision{}
+
+	res := r.store.db.Database(dbName).Collection(r.collectionName).FindOne(ctx, bson.M{"_id": ID})
+	if err := res.Decode(rev); err != nil {
+		return nil, notFound(err)
+	}
+
+	return rev, nil
+}
+
+// ListByDocument return revisions of document selected by query
+func (r RevisionRepository) ListByDocument(ctx context.Context, docID primitive.ObjectID, q store.ListQuery) ([]*models.Revision, error) {
+	ctx, cancel := r.store.readContext(ctx)
+	defer cancel()
+
+	filter, opts := mongoquery.RevisionsOfDocument(docID, q)
+
+	res, err := r.store.db.Database(dbName).Collection(r.collectionName).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	revs := make([]*models.Revision, 0)
+
+	if err = res.All(ctx, &revs); err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
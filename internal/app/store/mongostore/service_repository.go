@@ -6,6 +6,8 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,31 +20,29 @@ type ServiceRepository struct {
 }
 
 // Create save new service
-func (s ServiceRepository) Create(service *models.Service) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (s ServiceRepository) Create(ctx context.Context, service *models.Service) error {
 	if err := service.Validate(); err != nil {
 		return err
 	}
 
-	fservice, _ := s.FindBySlug(service.Slug)
+	fservice, _ := s.FindBySlug(ctx, service.Slug)
 	if fservice != nil {
 		return helpers.ErrServiceAlreadyExist
 	}
 
+	ctx, cancel := s.store.writeContext(ctx)
+	defer cancel()
+
 	db := s.store.db.Database(dbName)
 	col := db.Collection(s.collectionName)
 
-	if _, err := col.InsertOne(ctx, service); err != nil {
-		return err
-	}
+	_, err := col.InsertOne(ctx, service)
 
-	return nil
+	return duplicateErr(err, helpers.ErrServiceAlreadyExist)
 }
 
-func (s *ServiceRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.Service, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (s *ServiceRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.Service, error) {
+	ctx, cancel := s.store.readContext(ctx)
 	defer cancel()
 
 	db := s.store.db.Database(dbName)
@@ -52,9 +52,8 @@ func (s *ServiceRepository) findOne(filter bson.M, opts ...*options.FindOneOptio
 
 	service := &models.Service{}
 
-	err := res.Decode(service)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(service); err != nil {
+		return nil, notFound(err)
 	}
 
 	return service, nil
@@ -62,24 +61,24 @@ func (s *ServiceRepository) findOne(filter bson.M, opts ...*options.FindOneOptio
 }
 
 // FindBySlug lookup service by it slug
-func (s ServiceRepository) FindBySlug(slug string) (*models.Service, error) {
-	return s.findOne(bson.M{"slug": slug, "deleted": false})
+func (s ServiceRepository) FindBySlug(ctx context.Context, slug string) (*models.Service, error) {
+	return s.findOne(ctx, bson.M{"slug": slug, "deleted": false})
 }
 
 // FindByID lookup service by it id
-func (s ServiceRepository) FindByID(ID primitive.ObjectID) (*models.Service, error) {
-	return s.findOne(bson.M{"_id": ID, "deleted": false})
+func (s ServiceRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Service, error) {
+	return s.findOne(ctx, bson.M{"_id": ID, "deleted": false})
 }
 
-// FindAll return all services with specified filter
-func (s ServiceRepository) FindAll(filter bson.M) ([]*models.Service, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+// find return all services with passed filter and find options
+func (s ServiceRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*models.Service, error) {
+	ctx, cancel := s.store.readContext(ctx)
 	defer cancel()
 
 	db := s.store.db.Database(dbName)
 	col := db.Collection(s.collectionName)
 
-	res, err := col.Find(ctx, filter)
+	res, err := col.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -94,31 +93,54 @@ func (s ServiceRepository) FindAll(filter bson.M) ([]*models.Service, error) {
 	return services, nil
 }
 
-func (s ServiceRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	db := s.store.db.Database(dbName)
-	col := db.Collection(s.collectionName)
+// List return services selected by query
+func (s ServiceRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Service, error) {
+	return s.find(ctx, mongoquery.Filter(mongoquery.Services, q), mongoquery.FindOptions(mongoquery.Services, q))
+}
 
-	_, err := col.UpdateOne(ctx, filter, update, opts...)
-	if err != nil {
+// Update validate updated service and try to update it in db
+func (s ServiceRepository) Update(ctx context.Context, updatedService *models.Service) error {
+	if err := updatedService.Validate(); err != nil {
 		return err
 	}
 
-	return nil
+	return duplicateErr(s.store.updateVersion(ctx, s.collectionName, updatedService.ID, &updatedService.Version, updatedService), helpers.ErrServiceAlreadyExist)
 }
 
-// Update validate updated service and try to update it in db
-func (s ServiceRepository) Update(updatedService *models.Service) error {
+// Patch validate service and save only its given fields
+func (s ServiceRepository) Patch(ctx context.Context, updatedService *models.Service, fields []string) error {
 	if err := updatedService.Validate(); err != nil {
 		return err
 	}
 
-	return s.updateOne(bson.M{"_id": updatedService.ID}, bson.M{"$set": updatedService})
+	return duplicateErr(s.store.patchVersion(ctx, s.collectionName, updatedService.ID, &updatedService.Version, updatedService, fields), helpers.ErrServiceAlreadyExist)
+}
+
+// Delete moves service to trash
+func (s ServiceRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return s.store.moveToTrash(ctx, s.collectionName, deletedID, deletedBy)
+}
+
+// Restore brings service back from trash unless its slug is taken by another service
+func (s ServiceRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	service, err := s.findOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if fservice, _ := s.FindBySlug(ctx, service.Slug); fservice != nil {
+		return helpers.ErrServiceAlreadyExist
+	}
+
+	return duplicateErr(s.store.restore(ctx, s.collectionName, ID), helpers.ErrServiceAlreadyExist)
+}
+
+// Purge permanently removes service from trash
+func (s ServiceRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return s.store.purge(ctx, s.collectionName, ID)
 }
 
-// Delete marks service as deleted
-func (s ServiceRepository) Delete(deletedID primitive.ObjectID) error {
-	return s.updateOne(bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+// PurgeDeletedBefore permanently removes services deleted before given time
+func (s ServiceRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return s.store.purgeDeletedBefore(ctx, s.collectionName, before)
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -15,6 +16,8 @@ const dbName = "acg_db"
 // MongoStore represents abstraction for MongoDB
 type MongoStore struct {
 	db                  *mongo.Client
+	timeouts            store.Timeouts
+	transactions        bool // Server is replica set member or mongos
 	postRepository      *PostRepository
 	categoryRepository  *CategoryRepository
 	materialsRepository *MaterialRepository
@@ -22,34 +25,100 @@ type MongoStore struct {
 	userRepository      *UserRepository
 	pageRepository      *PageRepository
 	serviceRepository   *ServiceRepository
+	revisionRepository  *RevisionRepository
+	redirectRepository  *RedirectRepository
+	tagRepository       *TagRepository
 }
 
-// context for mongo db with 15 seconds timeout
-var dbctx, _ = context.WithTimeout(context.Background(), 15*time.Second)
-
 // NewStore return new Store object or error
-func NewStore(dbURL string) (store.Storer, error) {
+// ctx limits only connection establishment, every query is limited by timeouts
+func NewStore(ctx context.Context, dbURL string, timeouts store.Timeouts) (*MongoStore, error) {
 	client, err := mongo.NewClient(options.Client().ApplyURI(dbURL))
 	if err != nil {
 		return nil, err
 	}
-	err = client.Connect(dbctx)
+	err = client.Connect(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = client.Ping(dbctx, nil); err != nil {
+	if err = client.Ping(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	// Standalone server doesn't support transactions
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	err = client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	if err != nil {
 		return nil, err
 	}
 
 	return &MongoStore{
-		db: client,
+		db:           client,
+		timeouts:     timeouts,
+		transactions: hello.SetName != "" || hello.Msg == "isdbgrid",
 	}, nil
 }
 
 // Close just aborts the connection
-func (s *MongoStore) Close() {
-	s.db.Disconnect(dbctx)
+func (s *MongoStore) Close(ctx context.Context) error {
+	return s.db.Disconnect(ctx)
+}
+
+// WithTransaction runs fn in transaction when server supports them, otherwise fn is run as is
+func (s *MongoStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !s.transactions || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := s.db.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	return err
+}
+
+// readContext limits single document lookups, finds and counts
+func (s *MongoStore) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.timeouts.Read)
+}
+
+// writeContext limits inserts and updates
+func (s *MongoStore) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.timeouts.Write)
+}
+
+// aggregateContext limits aggregation pipelines
+func (s *MongoStore) aggregateContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.timeouts.Aggregate)
+}
+
+// withTimeout wraps ctx with timeout, zero timeout means only parent deadline is used
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// notFound replaces driver's error about empty result with store.ErrNotFound
+func notFound(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return store.ErrNotFound
+	}
+
+	return err
 }
 
 /*
@@ -145,3 +214,42 @@ func (s *MongoStore) Pages() store.IPageRepository {
 
 	return s.pageRepository
 }
+
+func (s *MongoStore) Revisions() store.IRevisionRepository {
+	if s.revisionRepository != nil {
+		return s.revisionRepository
+	}
+
+	s.revisionRepository = &RevisionRepository{
+		store:          s,
+		collectionName: "revisions",
+	}
+
+	return s.revisionRepository
+}
+
+func (s *MongoStore) Redirects() store.IRedirectRepository {
+	if s.redirectRepository != nil {
+		return s.redirectRepository
+	}
+
+	s.redirectRepository = &RedirectRepository{
+		store:          s,
+		collectionName: "redirects",
+	}
+
+	return s.redirectRepository
+}
+
+func (s *MongoStore) Tags() store.ITagRepository {
+	if s.tagRepository != nil {
+		return s.tagRepository
+	}
+
+	s.tagRepository = &TagRepository{
+		store:          s,
+		collectionName: "tags",
+	}
+
+	return s.tagRepository
+}
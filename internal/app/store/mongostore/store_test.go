@@ -0,0 +1,64 @@
+package mongostore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+)
+
+// testURLEnv names variable with URL of MongoDB used by tests
+// Database of the store is dropped before and after every test, so the server must be disposable
+const testURLEnv = "ACG_TEST_MONGO_URL"
+
+// testStore returns store on top of empty database with applied migrations and indexes
+func testStore(t testing.TB, dbURL string) *MongoStore {
+	t.Helper()
+
+	ctx := context.Background()
+	s, err := NewStore(ctx, dbURL, store.Timeouts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drop := func() {
+		if err := s.db.Database(dbName).Drop(ctx); err != nil {
+			t.Error(err)
+		}
+	}
+
+	t.Cleanup(func() {
+		drop()
+		s.Close(ctx)
+	})
+
+	// Rollback is checked by the suite, standalone server can't pass it
+	if !s.transactions {
+		t.Skip("MongoDB server is not a replica set member")
+	}
+
+	drop()
+
+	if _, err = s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.EnsureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestMongoStore(t *testing.T) {
+	dbURL := os.Getenv(testURLEnv)
+	if dbURL == "" {
+		t.Skipf("%s is not set", testURLEnv)
+	}
+
+	storetest.Run(t, func(t *testing.T) store.Storer {
+		return testStore(t, dbURL)
+	})
+}
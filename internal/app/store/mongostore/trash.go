@@ -0,0 +1,70 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// moveToTrash marks live document of collection as deleted
+// Repeated deletion keeps original deleted_at and deleted_by
+func (s *MongoStore) moveToTrash(ctx context.Context, collectionName string, ID primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	filter, update := mongoquery.MoveToTrash(ID, deletedBy, time.Now())
+	_, err := s.db.Database(dbName).Collection(collectionName).UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// restore brings deleted document of collection back
+func (s *MongoStore) restore(ctx context.Context, collectionName string, ID primitive.ObjectID) error {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	filter, update := mongoquery.RestoreFromTrash(ID)
+	res, err := s.db.Database(dbName).Collection(collectionName).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// purge permanently removes deleted document of collection
+func (s *MongoStore) purge(ctx context.Context, collectionName string, ID primitive.ObjectID) error {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	res, err := s.db.Database(dbName).Collection(collectionName).DeleteOne(ctx, mongoquery.InTrash(ID))
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// purgeDeletedBefore permanently removes documents of collection deleted before given time
+func (s *MongoStore) purgeDeletedBefore(ctx context.Context, collectionName string, before time.Time) (int64, error) {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	res, err := s.db.Database(dbName).Collection(collectionName).DeleteMany(ctx, mongoquery.DeletedBefore(before))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
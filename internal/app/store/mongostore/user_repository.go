@@ -6,6 +6,7 @@ import (
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,22 +19,19 @@ type UserRepository struct {
 }
 
 // Create save new post
-func (u UserRepository) Create(usr *models.User) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (u UserRepository) Create(ctx context.Context, usr *models.User) error {
 	if err := usr.Validate(); err != nil {
 		return err
 	}
 
 	// If username already taken
-	fusr, _ := u.FindByUsername(usr.Username)
+	fusr, _ := u.FindByUsername(ctx, usr.Username)
 	if fusr != nil {
 		return helpers.ErrUserAlreadyExist
 	}
 
 	// If email already taken
-	fusr, _ = u.FindByEmail(usr.Email)
+	fusr, _ = u.FindByEmail(ctx, usr.Email)
 	if fusr != nil {
 		return helpers.ErrEmailAlreadyExist
 	}
@@ -42,18 +40,26 @@ func (u UserRepository) Create(usr *models.User) error {
 		return err
 	}
 
+	ctx, cancel := u.store.writeContext(ctx)
+	defer cancel()
+
 	db := u.store.db.Database(dbName)
 	col := db.Collection(u.collectionName)
 
-	if _, err := col.InsertOne(ctx, usr); err != nil {
-		return err
+	_, err := col.InsertOne(ctx, usr)
+
+	switch {
+	case duplicateField(err, "username"):
+		return helpers.ErrUserAlreadyExist
+	case duplicateField(err, "email"):
+		return helpers.ErrEmailAlreadyExist
 	}
 
-	return nil
+	return err
 }
 
-func (u UserRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.User, error) {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (u UserRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.User, error) {
+	ctx, cancel := u.store.readContext(ctx)
 	defer cancel()
 
 	db := u.store.db.Database(dbName)
@@ -63,9 +69,8 @@ func (u UserRepository) findOne(filter bson.M, opts ...*options.FindOneOptions)
 
 	user := &models.User{}
 
-	err := res.Decode(user)
-	if err != nil {
-		return nil, err
+	if err := res.Decode(user); err != nil {
+		return nil, notFound(err)
 	}
 
 	return user, nil
@@ -73,17 +78,51 @@ func (u UserRepository) findOne(filter bson.M, opts ...*options.FindOneOptions)
 }
 
 // FindByUsername look up user by his username
-func (u UserRepository) FindByUsername(username string) (*models.User, error) {
-	return u.findOne(bson.M{"username": username, "deleted": false})
+func (u UserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return u.findOne(ctx, bson.M{"username": username, "deleted": false})
 }
 
 // FindByEmail look up user by his email
-func (u UserRepository) FindByEmail(email string) (*models.User, error) {
-	return u.findOne(bson.M{"email": email, "deleted": false})
+func (u UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return u.findOne(ctx, bson.M{"email": email, "deleted": false})
+}
+
+// FindByAuthorSlug look up user by slug of his author profile
+func (u UserRepository) FindByAuthorSlug(ctx context.Context, slug string) (*models.User, error) {
+	return u.findOne(ctx, bson.M{"author.slug": slug, "deleted": false})
+}
+
+// UpdateAuthor replaces author profile of user
+func (u UserRepository) UpdateAuthor(ctx context.Context, username string, author *models.Author) error {
+	if err := author.Validate(); err != nil {
+		return err
+	}
+
+	// Slug of another author is taken
+	fusr, _ := u.FindByAuthorSlug(ctx, author.Slug)
+	if fusr != nil && fusr.Username != username {
+		return helpers.ErrAuthorAlreadyExist
+	}
+
+	ctx, cancel := u.store.writeContext(ctx)
+	defer cancel()
+
+	col := u.store.db.Database(dbName).Collection(u.collectionName)
+
+	res, err := col.UpdateOne(ctx, bson.M{"username": username, "deleted": false}, bson.M{"$set": bson.M{"author": author}})
+	if err != nil {
+		return duplicateErr(err, helpers.ErrAuthorAlreadyExist)
+	}
+
+	if res.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
 }
 
-func (u UserRepository) updateOne(filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
-	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func (u UserRepository) updateOne(ctx context.Context, filter bson.M, update bson.M, opts ...*options.UpdateOptions) error {
+	ctx, cancel := u.store.writeContext(ctx)
 	defer cancel()
 
 	db := u.store.db.Database(dbName)
@@ -98,12 +137,12 @@ func (u UserRepository) updateOne(filter bson.M, update bson.M, opts ...*options
 }
 
 // Delete marks user as deleted
-func (u UserRepository) Delete(deletedID primitive.ObjectID) error {
-	return u.updateOne(bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
+func (u UserRepository) Delete(ctx context.Context, deletedID primitive.ObjectID) error {
+	return u.updateOne(ctx, bson.M{"_id": deletedID}, bson.M{"$set": bson.M{"deleted": true}})
 }
 
-func (u UserRepository) Login(username, password, secret string) (string, time.Time, error) {
-	fusr, err := u.FindByUsername(username)
+func (u UserRepository) Login(ctx context.Context, username, password, secret string) (string, time.Time, error) {
+	fusr, err := u.FindByUsername(ctx, username)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -0,0 +1,71 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/mongoquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// updateVersion replaces fields of document with doc only if document still has given version
+// Version is incremented before update, so doc holding it is saved with new version, and restored on failure
+func (s *MongoStore) updateVersion(ctx context.Context, collectionName string, ID primitive.ObjectID, version *int64, doc interface{}) error {
+	expected := *version
+	*version = expected + 1
+
+	err := s.updateOneVersioned(ctx, collectionName, mongoquery.Versioned(ID, expected), bson.M{"$set": doc})
+	if err != nil {
+		*version = expected
+	}
+
+	return err
+}
+
+func (s *MongoStore) updateOneVersioned(ctx context.Context, collectionName string, filter, update bson.M) error {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	res, err := s.db.Database(dbName).Collection(collectionName).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return s.versionConflict(ctx, collectionName, filter["_id"])
+	}
+
+	return nil
+}
+
+// versionConflict tells whether update matched nothing because of missing document or stale version
+func (s *MongoStore) versionConflict(ctx context.Context, collectionName string, ID interface{}) error {
+	n, err := s.db.Database(dbName).Collection(collectionName).CountDocuments(ctx, bson.M{"_id": ID})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	return store.ErrVersionConflict
+}
+
+// patchVersion writes only given fields of doc only if document still has given version
+// On success version is incremented like by updateVersion
+func (s *MongoStore) patchVersion(ctx context.Context, collectionName string, ID primitive.ObjectID, version *int64, doc interface{}, fields []string) error {
+	update, err := mongoquery.SetFields(doc, fields)
+	if err != nil {
+		return err
+	}
+
+	if err = s.updateOneVersioned(ctx, collectionName, mongoquery.Versioned(ID, *version), update); err != nil {
+		return err
+	}
+
+	*version++
+
+	return nil
+}
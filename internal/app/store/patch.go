@@ -0,0 +1,64 @@
+package store
+
+import (
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// protectedFields are never written by Patch, they are changed only by dedicated operations
+var protectedFields = map[string]bool{
+	"_id":            true,
+	"version":        true,
+	"deleted":        true,
+	"deleted_at":     true,
+	"deleted_by":     true,
+	"category_slug":  true,
+	"category_title": true,
+}
+
+// ChangedFields returns sorted BSON names of fields which differ between old and updated documents
+// Field missing in updated document is reported too, because it was emptied
+func ChangedFields(old, updated interface{}) ([]string, error) {
+	o, err := toMap(old)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := toMap(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0)
+	for k, v := range u {
+		if !protectedFields[k] && !reflect.DeepEqual(o[k], v) {
+			fields = append(fields, k)
+		}
+	}
+
+	for k := range o {
+		if _, ok := u[k]; !ok && !protectedFields[k] {
+			fields = append(fields, k)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields, nil
+}
+
+func toMap(doc interface{}) (bson.M, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	m := bson.M{}
+	if err = bson.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
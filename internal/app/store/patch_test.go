@@ -0,0 +1,58 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChangedFields(t *testing.T) {
+	post := &models.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "Первая запись",
+		Snippet:  "Анонс",
+		MetaDesc: "Описание",
+	}
+
+	testCases := []struct {
+		name   string
+		change func(p *models.Post)
+		want   []string
+	}{
+		{
+			name:   "Nothing changed",
+			change: func(p *models.Post) {},
+			want:   []string{},
+		},
+		{
+			name:   "Changed and emptied fields",
+			change: func(p *models.Post) { p.Title, p.Snippet = "Новый заголовок", "" },
+			want:   []string{"snippet", "title"},
+		},
+		{
+			name:   "Added field",
+			change: func(p *models.Post) { p.PostImg = "/uploads/images/post.jpg" },
+			want:   []string{"postimg"},
+		},
+		{
+			name: "Protected fields are ignored",
+			change: func(p *models.Post) {
+				p.ID, p.Version, p.Deleted, p.DeletedBy = primitive.NewObjectID(), 5, true, "admin"
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changed := *post
+			tc.change(&changed)
+
+			fields, err := ChangedFields(post, &changed)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, fields)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrNotFound is returned by repositories when requested document does not exist
+	ErrNotFound = errors.New("Document not found")
+	// ErrVersionConflict is returned by Update when document was changed since its version was read
+	ErrVersionConflict = errors.New("Document was changed by someone else")
+)
+
+// DeletedState selects documents by soft delete mark
+type DeletedState int
+
+const (
+	NotDeleted  DeletedState = iota // Only live documents, default
+	OnlyDeleted                     // Only documents marked as deleted
+	AnyDeleted                      // Both live and deleted documents
+)
+
+// SortOrder defines order of documents in listings
+type SortOrder int
+
+const (
+	SortNewest SortOrder = iota // By time from newest to oldest, default
+	SortOldest                  // By time from oldest to newest
+	SortTitle                   // By title alphabetically
+)
+
+// ListQuery describes listing of documents independently of store backend
+// Zero value selects all live documents from newest to oldest
+// Filters which are not applicable to repository are ignored by it,
+// e.g. categories and services have neither time nor parent category
+type ListQuery struct {
+	CategoryID      primitive.ObjectID // Category of posts or material category of materials
+	TagID           primitive.ObjectID // One of tags of posts and materials
+	Author          string             // Username of author of posts
+	Deleted         DeletedState
+	Status          models.Status // Publication status of posts, empty means any
+	From            time.Time     // Inclusive lower bound of document time
+	To              time.Time     // Exclusive upper bound of document time
+	UnpublishBefore time.Time     // Exclusive upper bound of unpublish_at of posts, posts without it are skipped
+	Text            string        // Case insensitive part of title
+	Sort            SortOrder
+	Limit           int64 // Zero means no limit
+	Offset          int64
+}
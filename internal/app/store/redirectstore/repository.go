@@ -0,0 +1,124 @@
+package redirectstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repositories embed wrapped ones, so only methods which change URLs are overridden
+
+// postRepository records redirects of store.IPostRepository
+type postRepository struct {
+	store.IPostRepository
+	store *Store
+}
+
+// postLocation includes category, because it is part of post URL
+func postLocation(post *models.Post) string {
+	return post.CategoryID.Hex() + "/" + post.Slug
+}
+
+func (p postRepository) locate(ctx context.Context, ID primitive.ObjectID) (string, string, error) {
+	post, err := p.IPostRepository.FindByID(ctx, ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return postLocation(post), post.Slug, nil
+}
+
+func (p postRepository) Update(ctx context.Context, post *models.Post) error {
+	return p.store.update(ctx, models.RedirectPost, post.ID, postLocation(post), p.locate, func(ctx context.Context) error {
+		return p.IPostRepository.Update(ctx, post)
+	})
+}
+
+func (p postRepository) Patch(ctx context.Context, post *models.Post, fields []string) error {
+	return p.store.update(ctx, models.RedirectPost, post.ID, postLocation(post), p.locate, func(ctx context.Context) error {
+		return p.IPostRepository.Patch(ctx, post, fields)
+	})
+}
+
+// categoryRepository records redirects of store.ICategoryRepository
+type categoryRepository struct {
+	store.ICategoryRepository
+	store *Store
+}
+
+func (c categoryRepository) locate(ctx context.Context, ID primitive.ObjectID) (string, string, error) {
+	category, err := c.ICategoryRepository.FindByID(ctx, ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return category.Slug, category.Slug, nil
+}
+
+func (c categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return c.store.update(ctx, models.RedirectCategory, category.ID, category.Slug, c.locate, func(ctx context.Context) error {
+		return c.ICategoryRepository.Update(ctx, category)
+	})
+}
+
+func (c categoryRepository) Patch(ctx context.Context, category *models.Category, fields []string) error {
+	return c.store.update(ctx, models.RedirectCategory, category.ID, category.Slug, c.locate, func(ctx context.Context) error {
+		return c.ICategoryRepository.Patch(ctx, category, fields)
+	})
+}
+
+// matCategoryRepository records redirects of store.IMatCategoryRepository
+type matCategoryRepository struct {
+	store.IMatCategoryRepository
+	store *Store
+}
+
+func (m matCategoryRepository) locate(ctx context.Context, ID primitive.ObjectID) (string, string, error) {
+	matcat, err := m.IMatCategoryRepository.FindByID(ctx, ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return matcat.Slug, matcat.Slug, nil
+}
+
+func (m matCategoryRepository) Update(ctx context.Context, matcat *models.MatCategory) error {
+	return m.store.update(ctx, models.RedirectMatCategory, matcat.ID, matcat.Slug, m.locate, func(ctx context.Context) error {
+		return m.IMatCategoryRepository.Update(ctx, matcat)
+	})
+}
+
+func (m matCategoryRepository) Patch(ctx context.Context, matcat *models.MatCategory, fields []string) error {
+	return m.store.update(ctx, models.RedirectMatCategory, matcat.ID, matcat.Slug, m.locate, func(ctx context.Context) error {
+		return m.IMatCategoryRepository.Patch(ctx, matcat, fields)
+	})
+}
+
+// pageRepository records redirects of store.IPageRepository, slug of page is its URL
+type pageRepository struct {
+	store.IPageRepository
+	store *Store
+}
+
+func (p pageRepository) locate(ctx context.Context, ID primitive.ObjectID) (string, string, error) {
+	page, err := p.IPageRepository.FindByID(ctx, ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return page.URL, page.URL, nil
+}
+
+func (p pageRepository) Update(ctx context.Context, page *models.Page) error {
+	return p.store.update(ctx, models.RedirectPage, page.ID, page.URL, p.locate, func(ctx context.Context) error {
+		return p.IPageRepository.Update(ctx, page)
+	})
+}
+
+func (p pageRepository) Patch(ctx context.Context, page *models.Page, fields []string) error {
+	return p.store.update(ctx, models.RedirectPage, page.ID, page.URL, p.locate, func(ctx context.Context) error {
+		return p.IPageRepository.Patch(ctx, page, fields)
+	})
+}
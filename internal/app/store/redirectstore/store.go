@@ -0,0 +1,67 @@
+// Package redirectstore records old slugs of documents when their URLs change,
+// so old URLs can be redirected to current ones
+// Posts moved by ReassignCategory are not recorded, they keep their slugs and are found by them
+package redirectstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store implements store.Storer on top of another store and records redirects on updates
+// Update and its redirect are written in one transaction
+type Store struct {
+	store.Storer
+}
+
+// NewStore returns store which records redirects of documents updated in s
+func NewStore(s store.Storer) *Store {
+	return &Store{Storer: s}
+}
+
+// locator returns location of document with given ID and slug which it is found by
+// Location changes together with URL of document
+type locator func(ctx context.Context, ID primitive.ObjectID) (location, slug string, err error)
+
+// update runs write in transaction and records redirect from old slug when location of document changes
+// Missing document is left to write, which reports it in its own way
+func (s *Store) update(ctx context.Context, docType string, ID primitive.ObjectID, location string, locate locator, write func(ctx context.Context) error) error {
+	return s.Storer.WithTransaction(ctx, func(ctx context.Context) error {
+		oldLocation, oldSlug, err := locate(ctx, ID)
+		if err != nil && err != store.ErrNotFound {
+			return err
+		}
+
+		if err = write(ctx); err != nil {
+			return err
+		}
+
+		if oldSlug == "" || oldLocation == location {
+			return nil
+		}
+
+		return s.Storer.Redirects().Create(ctx, models.NewRedirect(docType, ID, oldSlug))
+	})
+}
+
+/*
+ * Implement Storer interface
+ */
+func (s *Store) Posts() store.IPostRepository {
+	return postRepository{IPostRepository: s.Storer.Posts(), store: s}
+}
+
+func (s *Store) Categories() store.ICategoryRepository {
+	return categoryRepository{ICategoryRepository: s.Storer.Categories(), store: s}
+}
+
+func (s *Store) MatCategories() store.IMatCategoryRepository {
+	return matCategoryRepository{IMatCategoryRepository: s.Storer.MatCategories(), store: s}
+}
+
+func (s *Store) Pages() store.IPageRepository {
+	return pageRepository{IPageRepository: s.Storer.Pages(), store: s}
+}
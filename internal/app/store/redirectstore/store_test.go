@@ -0,0 +1,108 @@
+package redirectstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testCategory(slug string) *models.Category {
+	return &models.Category{
+		ID:       primitive.NewObjectID(),
+		Title:    "Налоги и отчетность",
+		Subtitle: "Подзаголовок категории достаточной длины",
+		Slug:     slug,
+		MetaDesc: "Описание категории для поисковых систем достаточной длины",
+	}
+}
+
+func testPost(slug string, catID primitive.ObjectID) *models.Post {
+	return &models.Post{
+		ID:         primitive.NewObjectID(),
+		Title:      "Первая запись",
+		Snippet:    "Короткое описание записи, которое показывается в карточке",
+		Slug:       slug,
+		CategoryID: catID,
+		Time:       time.Now(),
+		MetaDesc:   "Описание записи для поисковых систем достаточной длины",
+		PostImg:    "/uploads/images/post.jpg",
+		Status:     models.StatusPublished,
+	}
+}
+
+func TestStore_PostRenames(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore())
+
+	first, second := testCategory("nalogi"), testCategory("otchetnost")
+	assert.NoError(t, s.Categories().Create(ctx, first))
+	assert.NoError(t, s.Categories().Create(ctx, second))
+
+	post := testPost("pervaya_zapis", first.ID)
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	// Update without change of URL is not recorded
+	post.Title = "Первая запись года"
+	assert.NoError(t, s.Posts().Update(ctx, post))
+
+	_, err := s.Redirects().FindBySlug(ctx, models.RedirectPost, "pervaya_zapis")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	// Chain of renames points to the same post
+	post.Slug = "vtoraya_zapis"
+	assert.NoError(t, s.Posts().Update(ctx, post))
+
+	post.Slug = "tretya_zapis"
+	assert.NoError(t, s.Posts().Patch(ctx, post, []string{"slug"}))
+
+	for _, slug := range []string{"pervaya_zapis", "vtoraya_zapis"} {
+		redirect, err := s.Redirects().FindBySlug(ctx, models.RedirectPost, slug)
+		if assert.NoError(t, err) {
+			assert.Equal(t, post.ID, redirect.DocID)
+		}
+	}
+
+	// Move to another category changes URL too
+	post.CategoryID = second.ID
+	assert.NoError(t, s.Posts().Update(ctx, post))
+
+	redirect, err := s.Redirects().FindBySlug(ctx, models.RedirectPost, "tretya_zapis")
+	if assert.NoError(t, err) {
+		assert.Equal(t, post.ID, redirect.DocID)
+	}
+
+	// Failed update is not recorded
+	post.Slug = "chetvertaya_zapis"
+	post.Version--
+	assert.Equal(t, store.ErrVersionConflict, s.Posts().Update(ctx, post))
+
+	_, err = s.Redirects().FindBySlug(ctx, models.RedirectPost, "tretya_zapis")
+	assert.NoError(t, err)
+	_, err = s.Posts().FindBySlug(ctx, "chetvertaya_zapis")
+	assert.Equal(t, store.ErrNotFound, err)
+}
+
+func TestStore_CategoryRename(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(memstore.NewStore())
+
+	category := testCategory("nalogi")
+	assert.NoError(t, s.Categories().Create(ctx, category))
+
+	category.Slug = "nalogi_i_otchetnost"
+	assert.NoError(t, s.Categories().Update(ctx, category))
+
+	redirect, err := s.Redirects().FindBySlug(ctx, models.RedirectCategory, "nalogi")
+	if assert.NoError(t, err) {
+		assert.Equal(t, category.ID, redirect.DocID)
+	}
+
+	// Update of missing document reports its own error
+	assert.Equal(t, store.ErrNotFound, s.Categories().Update(ctx, testCategory("novaya")))
+}
@@ -1,85 +1,205 @@
 package store
 
 import (
+	"context"
 	"time"
 
 	"github.com/the-NZA/acg-nikolaev/internal/app/models"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ITrashRepository defines trash operations shared by repositories of soft deleted content
+// Restore and Purge return ErrNotFound when item is not in trash
+type ITrashRepository interface {
+	Restore(context.Context, primitive.ObjectID) error
+	Purge(context.Context, primitive.ObjectID) error
+	// PurgeDeletedBefore permanently removes items deleted before given time and returns their number
+	PurgeDeletedBefore(context.Context, time.Time) (int64, error)
+}
+
+// PostListing is page of posts with number of all posts matching query
+type PostListing struct {
+	Posts []*models.Post `bson:"posts"`
+	Total int64          `bson:"total"`
+}
+
+// FirstListing returns the only listing produced by $facet pipeline
+// Posts are never nil, so listing is encoded to JSON as empty array
+func FirstListing(listings []*PostListing) *PostListing {
+	l := &PostListing{}
+	if len(listings) > 0 {
+		l = listings[0]
+	}
+
+	if l.Posts == nil {
+		l.Posts = make([]*models.Post, 0)
+	}
+
+	return l
+}
+
 // IPostRepository defines interface for post repository
 type IPostRepository interface {
-	Create(*models.Post) error
-	Find(bson.M, ...*options.FindOptions) ([]*models.Post, error)
-	FindBySlug(string) (*models.Post, error)
-	FindByID(primitive.ObjectID) (*models.Post, error)
-	FindAll(bson.M) ([]*models.Post, error)
-	Aggregate(mongo.Pipeline, ...*options.AggregateOptions) ([]*models.Post, error)
-	Count(interface{}, ...*options.CountOptions) (int64, error)
-	Update(*models.Post) error
-	Delete(primitive.ObjectID) error
+	ITrashRepository
+
+	Create(context.Context, *models.Post) error
+	FindBySlug(context.Context, string) (*models.Post, error)
+	FindByID(context.Context, primitive.ObjectID) (*models.Post, error)
+	List(context.Context, ListQuery) ([]*models.Post, error)
+	// ListPublishedWithCategory returns short form of live published posts with filled CategorySlug for public listings
+	// Posts of missing or trashed categories have no public URL and are skipped
+	ListPublishedWithCategory(context.Context, ListQuery) ([]*models.Post, error)
+	// ListPublishedWithTotal returns page of posts like ListPublishedWithCategory
+	// together with number of all posts it would list for query in one round trip
+	ListPublishedWithTotal(context.Context, ListQuery) (*PostListing, error)
+	// Count ignores Limit and Offset of query
+	Count(context.Context, ListQuery) (int64, error)
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.Post) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.Post, fields []string) error
+	// Delete moves item to trash, deletedBy is username of editor
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
+	// ReassignCategory moves live posts of category to another one and returns their number
+	ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error)
+	// DeleteByCategory moves live posts of category to trash and returns their number
+	DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error)
 }
 
 // ICategoryRepository defines interface for category repository
 type ICategoryRepository interface {
-	Create(*models.Category) error
-	FindByID(primitive.ObjectID) (*models.Category, error)
-	FindBySlug(string) (*models.Category, error)
-	FindAll(bson.M) ([]*models.Category, error)
-	Update(*models.Category) error
-	Delete(primitive.ObjectID) error
+	ITrashRepository
+
+	Create(context.Context, *models.Category) error
+	FindByID(context.Context, primitive.ObjectID) (*models.Category, error)
+	FindBySlug(context.Context, string) (*models.Category, error)
+	List(context.Context, ListQuery) ([]*models.Category, error)
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.Category) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.Category, fields []string) error
+	// Delete moves item to trash, deletedBy is username of editor
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
 }
 
 // IMaterialRepository defines interface for material repository
 type IMaterialRepository interface {
-	Create(*models.Material) error
-	Find(bson.M, ...*options.FindOptions) ([]*models.Material, error)
-	FindByID(primitive.ObjectID) (*models.Material, error)
-	FindBySlug(string) (*models.Material, error)
-	FindAll(bson.M) ([]*models.Material, error)
-	Update(*models.Material) error
-	Count(interface{}, ...*options.CountOptions) (int64, error)
-	Delete(primitive.ObjectID) error
+	ITrashRepository
+
+	Create(context.Context, *models.Material) error
+	FindByID(context.Context, primitive.ObjectID) (*models.Material, error)
+	FindBySlug(context.Context, string) (*models.Material, error)
+	List(context.Context, ListQuery) ([]*models.Material, error)
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.Material) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.Material, fields []string) error
+	// Count ignores Limit and Offset of query
+	Count(context.Context, ListQuery) (int64, error)
+	// Delete moves item to trash, deletedBy is username of editor
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
+	// ReassignCategory moves live materials of material category to another one and returns their number
+	ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error)
+	// DeleteByCategory moves live materials of material category to trash and returns their number
+	DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error)
 }
 
 // IMatCategoryRepository defines interface for material category repository
 type IMatCategoryRepository interface {
-	Create(*models.MatCategory) error
-	FindByID(primitive.ObjectID) (*models.MatCategory, error)
-	FindBySlug(string) (*models.MatCategory, error)
-	FindAll(bson.M) ([]*models.MatCategory, error)
-	Aggregate(mongo.Pipeline, ...*options.AggregateOptions) ([]*models.MaterialShow, error)
-	Update(*models.MatCategory) error
-	Delete(primitive.ObjectID) error
+	ITrashRepository
+
+	Create(context.Context, *models.MatCategory) error
+	FindByID(context.Context, primitive.ObjectID) (*models.MatCategory, error)
+	FindBySlug(context.Context, string) (*models.MatCategory, error)
+	List(context.Context, ListQuery) ([]*models.MatCategory, error)
+	// ListWithMaterials returns categories with up to perCategory newest live materials, zero means all
+	ListWithMaterials(ctx context.Context, q ListQuery, perCategory int64) ([]*models.MaterialShow, error)
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.MatCategory) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.MatCategory, fields []string) error
+	// Delete moves item to trash, deletedBy is username of editor
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
 }
 
 // IUserRepository defines interface for user repository
 type IUserRepository interface {
-	Create(*models.User) error
+	Create(context.Context, *models.User) error
 	// Find(string) (*models.User, error)
-	Delete(primitive.ObjectID) error
-	Login(string, string, string) (string, time.Time, error)
+	FindByUsername(context.Context, string) (*models.User, error)
+	// FindByAuthorSlug returns live user by slug of author profile
+	FindByAuthorSlug(context.Context, string) (*models.User, error)
+	// UpdateAuthor replaces author profile of live user, ErrNotFound means there is no such user
+	UpdateAuthor(ctx context.Context, username string, author *models.Author) error
+	Delete(context.Context, primitive.ObjectID) error
+	Login(context.Context, string, string, string) (string, time.Time, error)
 }
 
 // IServiceRepository defines interface for service repository
 type IServiceRepository interface {
-	Create(*models.Service) error
-	Update(*models.Service) error
-	FindByID(primitive.ObjectID) (*models.Service, error)
-	FindBySlug(string) (*models.Service, error)
-	Delete(primitive.ObjectID) error
-	FindAll(filter bson.M) ([]*models.Service, error)
+	ITrashRepository
+
+	Create(context.Context, *models.Service) error
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.Service) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.Service, fields []string) error
+	FindByID(context.Context, primitive.ObjectID) (*models.Service, error)
+	FindBySlug(context.Context, string) (*models.Service, error)
+	// Delete moves item to trash, deletedBy is username of editor
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
+	List(context.Context, ListQuery) ([]*models.Service, error)
 }
 
 // IPageRepository defines interface for page repository
 type IPageRepository interface {
-	Create(*models.Page) error
-	FindByURL(string) (*models.Page, error)
-	FindByID(primitive.ObjectID) (*models.Page, error)
-	Update(*models.Page) error
-	Delete(primitive.ObjectID) error
-	FindAll(filter bson.M) ([]*models.Page, error)
+	ITrashRepository
+
+	Create(context.Context, *models.Page) error
+	FindByURL(context.Context, string) (*models.Page, error)
+	FindByID(context.Context, primitive.ObjectID) (*models.Page, error)
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.Page) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.Page, fields []string) error
+	// Delete moves item to trash, deletedBy is username of editor
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
+	List(context.Context, ListQuery) ([]*models.Page, error)
+}
+
+// ITagRepository defines interface for tag repository
+type ITagRepository interface {
+	ITrashRepository
+
+	Create(context.Context, *models.Tag) error
+	FindByID(context.Context, primitive.ObjectID) (*models.Tag, error)
+	FindBySlug(context.Context, string) (*models.Tag, error)
+	List(context.Context, ListQuery) ([]*models.Tag, error)
+	// ListByIDs returns live tags with given IDs sorted by title, unknown and deleted IDs are skipped
+	ListByIDs(context.Context, []primitive.ObjectID) ([]*models.Tag, error)
+	// Update returns ErrVersionConflict when Version of item is stale, on success Version is incremented
+	Update(context.Context, *models.Tag) error
+	// Patch writes only fields with given BSON names, Version is checked and incremented like by Update
+	Patch(ctx context.Context, updated *models.Tag, fields []string) error
+	// Delete moves item to trash, deletedBy is username of editor
+	// Posts and materials keep ID of deleted tag, so restored tag is back on them
+	Delete(ctx context.Context, ID primitive.ObjectID, deletedBy string) error
+}
+
+// IRevisionRepository defines interface for revision repository
+// Revisions are immutable, so there are no Update and Delete
+type IRevisionRepository interface {
+	Create(context.Context, *models.Revision) error
+	FindByID(context.Context, primitive.ObjectID) (*models.Revision, error)
+	// ListByDocument returns revisions of document from newest to oldest without snapshots
+	// Only time range, Limit and Offset of query are applied
+	ListByDocument(ctx context.Context, docID primitive.ObjectID, q ListQuery) ([]*models.Revision, error)
+}
+
+// IRedirectRepository defines interface for history of old slugs
+// Like revisions redirects are immutable
+type IRedirectRepository interface {
+	Create(context.Context, *models.Redirect) error
+	// FindBySlug returns the latest redirect from old slug of document type
+	FindBySlug(ctx context.Context, docType, slug string) (*models.Redirect, error)
 }
@@ -0,0 +1,144 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryRepository implements ICategoryRepository
+type CategoryRepository struct {
+	store *SQLStore
+}
+
+func categoryArgs(cat *models.Category) []interface{} {
+	return []interface{}{objectID{&cat.ID}, cat.Title, cat.Subtitle, cat.Slug, cat.MetaDesc, cat.Deleted}
+}
+
+func scanCategory(sc scanner) (*models.Category, error) {
+	cat := &models.Category{}
+
+	if err := sc.Scan(objectID{&cat.ID}, &cat.Title, &cat.Subtitle, &cat.Slug, &cat.MetaDesc, &cat.Deleted, nullTime{&cat.DeletedAt}, &cat.DeletedBy, &cat.Version); err != nil {
+		return nil, err
+	}
+
+	return cat, nil
+}
+
+// Create new category
+func (c *CategoryRepository) Create(ctx context.Context, cat *models.Category) error {
+	if err := cat.Validate(); err != nil {
+		return err
+	}
+
+	fcat, _ := c.FindBySlug(ctx, cat.Slug)
+	if fcat != nil {
+		return helpers.ErrCategoryAlreadyExist
+	}
+
+	_, err := c.store.exec(ctx, categoriesTable.insert(), categoryArgs(cat)...)
+
+	return c.store.duplicateErr(err, "slug", helpers.ErrCategoryAlreadyExist)
+}
+
+func (c *CategoryRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.Category, error) {
+	var cat *models.Category
+
+	err := c.store.queryRow(ctx, "SELECT "+categoriesTable.selectColumns("")+" FROM categories WHERE "+where, args, func(sc scanner) error {
+		var err error
+		cat, err = scanCategory(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cat, nil
+}
+
+// FindByID category by it ID
+func (c *CategoryRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Category, error) {
+	return c.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// FindBySlug finds category by it slug
+func (c *CategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	return c.findOne(ctx, "slug = ? AND deleted = ?", slug, false)
+}
+
+// List return categories selected by query
+func (c *CategoryRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Category, error) {
+	where, args := categoriesTable.where(c.store.dialect, q, "")
+	cats := make([]*models.Category, 0)
+
+	err := c.store.query(ctx, "SELECT "+categoriesTable.selectColumns("")+" FROM categories"+where+categoriesTable.orderLimit(c.store.dialect, q, ""), args, func(sc scanner) error {
+		cat, err := scanCategory(sc)
+		if err != nil {
+			return err
+		}
+
+		cats = append(cats, cat)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cats, nil
+}
+
+// Delete moves category to trash
+func (c *CategoryRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return c.store.moveToTrash(ctx, categoriesTable, deletedID, deletedBy)
+}
+
+// Restore brings category back from trash unless its slug is taken by another category
+func (c *CategoryRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	cat, err := c.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if fcat, _ := c.FindBySlug(ctx, cat.Slug); fcat != nil {
+		return helpers.ErrCategoryAlreadyExist
+	}
+
+	return c.store.duplicateErr(c.store.restore(ctx, categoriesTable, ID), "slug", helpers.ErrCategoryAlreadyExist)
+}
+
+// Purge permanently removes category from trash
+func (c *CategoryRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return c.store.purge(ctx, categoriesTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes categories deleted before given time
+func (c *CategoryRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return c.store.purgeDeletedBefore(ctx, categoriesTable, before)
+}
+
+// Update validate category and try to save it
+func (c *CategoryRepository) Update(ctx context.Context, updatedCategory *models.Category) error {
+	if err := updatedCategory.Validate(); err != nil {
+		return err
+	}
+
+	args := append(categoryArgs(updatedCategory)[1:], updatedCategory.ID.Hex())
+	err := c.store.updateVersion(ctx, categoriesTable, categoriesTable.update(), args, updatedCategory.ID, &updatedCategory.Version)
+
+	return c.store.duplicateErr(err, "slug", helpers.ErrCategoryAlreadyExist)
+}
+
+// Patch validate category and save only its given fields
+func (c *CategoryRepository) Patch(ctx context.Context, updatedCategory *models.Category, fields []string) error {
+	if err := updatedCategory.Validate(); err != nil {
+		return err
+	}
+
+	err := c.store.patchVersion(ctx, categoriesTable, categoryArgs(updatedCategory), fields, updatedCategory.ID, &updatedCategory.Version)
+
+	return c.store.duplicateErr(err, "slug", helpers.ErrCategoryAlreadyExist)
+}
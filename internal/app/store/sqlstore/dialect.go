@@ -0,0 +1,110 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is SQLite driver with unicode aware lower function
+// Builtin one changes only ASCII letters, so search by Cyrillic title would be case sensitive
+const sqliteDriver = "sqlite3_acg"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("lower", strings.ToLower, true)
+		},
+	})
+}
+
+// dialect contains differences between supported databases
+type dialect struct {
+	driver       string
+	numbered     bool   // Placeholders are $1, $2, ... instead of ?
+	singleConn   bool   // Pool must be limited to single connection
+	timeType     string // Column type for time values
+	jsonType     string // Column type for JSON documents
+	noLimit      string // LIMIT value which means all rows
+	jsonHas      string // Condition that JSON array in column %s contains string argument
+	uniqueErrors []string
+}
+
+var (
+	sqliteDialect = &dialect{
+		driver:       sqliteDriver,
+		singleConn:   true,
+		timeType:     "TIMESTAMP",
+		jsonType:     "TEXT",
+		noLimit:      "-1",
+		jsonHas:      "EXISTS (SELECT 1 FROM json_each(%s) WHERE value = ?)",
+		uniqueErrors: []string{"UNIQUE constraint failed"},
+	}
+
+	postgresDialect = &dialect{
+		driver:       "postgres",
+		numbered:     true,
+		timeType:     "TIMESTAMPTZ",
+		jsonType:     "JSONB",
+		noLimit:      "ALL",
+		jsonHas:      "%s @> jsonb_build_array(?::text)",
+		uniqueErrors: []string{"duplicate key value violates unique constraint"},
+	}
+)
+
+// dialectFor returns dialect by driver name from config
+func dialectFor(driver string) (*dialect, error) {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return sqliteDialect, nil
+	case "postgres", "postgresql":
+		return postgresDialect, nil
+	}
+
+	return nil, fmt.Errorf("sqlstore: unsupported driver %q", driver)
+}
+
+// rebind replaces "?" placeholders with numbered ones if database requires it
+func (d *dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var (
+		b strings.Builder
+		n int
+	)
+
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// isUniqueViolation returns true when err is violation of unique index
+// Index names contain column name, so field narrows check to exact index
+func (d *dialect) isUniqueViolation(err error, field string) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	for _, e := range d.uniqueErrors {
+		if strings.Contains(msg, e) && strings.Contains(msg, field) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reassignChildren moves live children of category to another one
+func (s *SQLStore) reassignChildren(ctx context.Context, t table, from, to primitive.ObjectID) (int64, error) {
+	res, err := s.exec(ctx, "UPDATE "+t.name+" SET "+t.categoryColumn+" = ?, version = version + 1 WHERE "+t.categoryColumn+" = ? AND deleted = ?",
+		to.Hex(), from.Hex(), false)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// childrenToTrash marks live children of category as deleted
+func (s *SQLStore) childrenToTrash(ctx context.Context, t table, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	res, err := s.exec(ctx, "UPDATE "+t.name+" SET deleted = ?, deleted_at = ?, deleted_by = ? WHERE "+t.categoryColumn+" = ? AND deleted = ?",
+		true, time.Now().UTC(), deletedBy, categoryID.Hex(), false)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
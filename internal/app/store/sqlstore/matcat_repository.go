@@ -0,0 +1,204 @@
+package sqlstore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MatCatRepository implements IMatCatRepository
+type MatCatRepository struct {
+	store *SQLStore
+}
+
+func matCategoryArgs(matcat *models.MatCategory) []interface{} {
+	return []interface{}{objectID{&matcat.ID}, matcat.Title, matcat.Slug, matcat.Desc, matcat.Deleted}
+}
+
+func scanMatCategory(sc scanner) (*models.MatCategory, error) {
+	matcat := &models.MatCategory{}
+
+	if err := sc.Scan(objectID{&matcat.ID}, &matcat.Title, &matcat.Slug, &matcat.Desc, &matcat.Deleted, nullTime{&matcat.DeletedAt}, &matcat.DeletedBy, &matcat.Version); err != nil {
+		return nil, err
+	}
+
+	return matcat, nil
+}
+
+// Create new material category
+func (m MatCatRepository) Create(ctx context.Context, matcat *models.MatCategory) error {
+	if err := matcat.Validate(); err != nil {
+		return err
+	}
+
+	fcat, _ := m.FindBySlug(ctx, matcat.Slug)
+	if fcat != nil {
+		return helpers.ErrMatCategoryAlreadyExist
+	}
+
+	_, err := m.store.exec(ctx, matCategoriesTable.insert(), matCategoryArgs(matcat)...)
+
+	return m.store.duplicateErr(err, "slug", helpers.ErrMatCategoryAlreadyExist)
+}
+
+func (m MatCatRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.MatCategory, error) {
+	var matcat *models.MatCategory
+
+	err := m.store.queryRow(ctx, "SELECT "+matCategoriesTable.selectColumns("")+" FROM matcategories WHERE "+where, args, func(sc scanner) error {
+		var err error
+		matcat, err = scanMatCategory(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matcat, nil
+}
+
+// FindBySlug material category by slug
+func (m MatCatRepository) FindBySlug(ctx context.Context, slug string) (*models.MatCategory, error) {
+	return m.findOne(ctx, "slug = ? AND deleted = ?", slug, false)
+}
+
+// FindByID material category by it ID
+func (m MatCatRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.MatCategory, error) {
+	return m.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// List return material categories selected by query
+func (m MatCatRepository) List(ctx context.Context, q store.ListQuery) ([]*models.MatCategory, error) {
+	where, args := matCategoriesTable.where(m.store.dialect, q, "")
+	matcats := make([]*models.MatCategory, 0)
+
+	err := m.store.query(ctx, "SELECT "+matCategoriesTable.selectColumns("")+" FROM matcategories"+where+matCategoriesTable.orderLimit(m.store.dialect, q, ""), args, func(sc scanner) error {
+		matcat, err := scanMatCategory(sc)
+		if err != nil {
+			return err
+		}
+
+		matcats = append(matcats, matcat)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matcats, nil
+}
+
+// ListWithMaterials used to find and join materials and materials' categories for rendering in browser
+// Materials of all selected categories are fetched by single query with window function
+func (m MatCatRepository) ListWithMaterials(ctx context.Context, q store.ListQuery, perCategory int64) ([]*models.MaterialShow, error) {
+	matcats, err := m.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	mats := make([]*models.MaterialShow, 0, len(matcats))
+	if len(matcats) == 0 {
+		return mats, nil
+	}
+
+	byID := make(map[primitive.ObjectID]*models.MaterialShow, len(matcats))
+	ids := make([]interface{}, 0, len(matcats))
+
+	for _, mc := range matcats {
+		show := &models.MaterialShow{
+			ID:        mc.ID,
+			Title:     mc.Title,
+			Slug:      mc.Slug,
+			Desc:      mc.Desc,
+			Materials: make([]*models.Material, 0),
+		}
+
+		mats = append(mats, show)
+		byID[mc.ID] = show
+		ids = append(ids, mc.ID.Hex())
+	}
+
+	limit := ""
+	if perCategory > 0 {
+		limit = " WHERE rn <= " + strconv.FormatInt(perCategory, 10)
+	}
+
+	query := "SELECT " + materialsTable.selectColumns("") + ` FROM (
+		SELECT *, ROW_NUMBER() OVER (PARTITION BY matcategory_id ORDER BY time DESC) AS rn
+		FROM materials
+		WHERE deleted = ? AND matcategory_id IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + `)
+	) ranked` + limit + " ORDER BY time DESC"
+
+	err = m.store.aggregate(ctx, query, append([]interface{}{false}, ids...), func(sc scanner) error {
+		material, err := scanMaterial(sc)
+		if err != nil {
+			return err
+		}
+
+		show := byID[material.MatCategoryID]
+		show.Materials = append(show.Materials, material)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mats, nil
+}
+
+// Update validate matcategory and try to save it
+func (m MatCatRepository) Update(ctx context.Context, updatedMatCategory *models.MatCategory) error {
+	if err := updatedMatCategory.Validate(); err != nil {
+		return err
+	}
+
+	args := append(matCategoryArgs(updatedMatCategory)[1:], updatedMatCategory.ID.Hex())
+	err := m.store.updateVersion(ctx, matCategoriesTable, matCategoriesTable.update(), args, updatedMatCategory.ID, &updatedMatCategory.Version)
+
+	return m.store.duplicateErr(err, "slug", helpers.ErrMatCategoryAlreadyExist)
+}
+
+// Patch validate matcategory and save only its given fields
+func (m MatCatRepository) Patch(ctx context.Context, updatedMatCategory *models.MatCategory, fields []string) error {
+	if err := updatedMatCategory.Validate(); err != nil {
+		return err
+	}
+
+	err := m.store.patchVersion(ctx, matCategoriesTable, matCategoryArgs(updatedMatCategory), fields, updatedMatCategory.ID, &updatedMatCategory.Version)
+
+	return m.store.duplicateErr(err, "slug", helpers.ErrMatCategoryAlreadyExist)
+}
+
+// Delete moves material category to trash
+func (m MatCatRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return m.store.moveToTrash(ctx, matCategoriesTable, deletedID, deletedBy)
+}
+
+// Restore brings material category back from trash unless its slug is taken by another material category
+func (m MatCatRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	matcat, err := m.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if fmatcat, _ := m.FindBySlug(ctx, matcat.Slug); fmatcat != nil {
+		return helpers.ErrMatCategoryAlreadyExist
+	}
+
+	return m.store.duplicateErr(m.store.restore(ctx, matCategoriesTable, ID), "slug", helpers.ErrMatCategoryAlreadyExist)
+}
+
+// Purge permanently removes material category from trash
+func (m MatCatRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return m.store.purge(ctx, matCategoriesTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes material categories deleted before given time
+func (m MatCatRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.store.purgeDeletedBefore(ctx, matCategoriesTable, before)
+}
@@ -0,0 +1,175 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaterialRepository implements IMaterialRepository
+type MaterialRepository struct {
+	store *SQLStore
+}
+
+func materialArgs(material *models.Material) []interface{} {
+	return []interface{}{
+		objectID{&material.ID}, material.Title, objectID{&material.MatCategoryID}, material.Slug,
+		material.Desc, material.Time.UTC(), material.FileLink, jsonColumn{material.TagIDs}, material.Deleted,
+	}
+}
+
+func scanMaterial(sc scanner) (*models.Material, error) {
+	material := &models.Material{}
+
+	err := sc.Scan(
+		objectID{&material.ID}, &material.Title, objectID{&material.MatCategoryID}, &material.Slug,
+		&material.Desc, &material.Time, &material.FileLink, jsonColumn{&material.TagIDs},
+		&material.Deleted, nullTime{&material.DeletedAt}, &material.DeletedBy, &material.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return material, nil
+}
+
+// Create save new material
+func (m MaterialRepository) Create(ctx context.Context, material *models.Material) error {
+	if err := material.Validate(); err != nil {
+		return err
+	}
+
+	fmaterial, _ := m.FindBySlug(ctx, material.Slug)
+	if fmaterial != nil {
+		return helpers.ErrMaterialAlreadyExist
+	}
+
+	_, err := m.store.exec(ctx, materialsTable.insert(), materialArgs(material)...)
+
+	return m.store.duplicateErr(err, "slug", helpers.ErrMaterialAlreadyExist)
+}
+
+func (m MaterialRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.Material, error) {
+	var material *models.Material
+
+	err := m.store.queryRow(ctx, "SELECT "+materialsTable.selectColumns("")+" FROM materials WHERE "+where, args, func(sc scanner) error {
+		var err error
+		material, err = scanMaterial(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return material, nil
+}
+
+// FindBySlug lookup material by it slug
+func (m MaterialRepository) FindBySlug(ctx context.Context, slug string) (*models.Material, error) {
+	return m.findOne(ctx, "slug = ? AND deleted = ?", slug, false)
+}
+
+// FindByID lookup material by ID
+func (m MaterialRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Material, error) {
+	return m.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// List return materials selected by query
+func (m MaterialRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Material, error) {
+	where, args := materialsTable.where(m.store.dialect, q, "")
+	materials := make([]*models.Material, 0)
+
+	err := m.store.query(ctx, "SELECT "+materialsTable.selectColumns("")+" FROM materials"+where+materialsTable.orderLimit(m.store.dialect, q, ""), args, func(sc scanner) error {
+		material, err := scanMaterial(sc)
+		if err != nil {
+			return err
+		}
+
+		materials = append(materials, material)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+// Update recieve material, validate it and try to update it
+func (m MaterialRepository) Update(ctx context.Context, updatedMaterial *models.Material) error {
+	if err := updatedMaterial.Validate(); err != nil {
+		return err
+	}
+
+	args := append(materialArgs(updatedMaterial)[1:], updatedMaterial.ID.Hex())
+	err := m.store.updateVersion(ctx, materialsTable, materialsTable.update(), args, updatedMaterial.ID, &updatedMaterial.Version)
+
+	return m.store.duplicateErr(err, "slug", helpers.ErrMaterialAlreadyExist)
+}
+
+// Patch validate material and save only its given fields
+func (m MaterialRepository) Patch(ctx context.Context, updatedMaterial *models.Material, fields []string) error {
+	if err := updatedMaterial.Validate(); err != nil {
+		return err
+	}
+
+	err := m.store.patchVersion(ctx, materialsTable, materialArgs(updatedMaterial), fields, updatedMaterial.ID, &updatedMaterial.Version)
+
+	return m.store.duplicateErr(err, "slug", helpers.ErrMaterialAlreadyExist)
+}
+
+// Delete moves material to trash
+func (m MaterialRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return m.store.moveToTrash(ctx, materialsTable, deletedID, deletedBy)
+}
+
+// Restore brings material back from trash unless its slug is taken by another material
+func (m MaterialRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	material, err := m.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if fmaterial, _ := m.FindBySlug(ctx, material.Slug); fmaterial != nil {
+		return helpers.ErrMaterialAlreadyExist
+	}
+
+	return m.store.duplicateErr(m.store.restore(ctx, materialsTable, ID), "slug", helpers.ErrMaterialAlreadyExist)
+}
+
+// Purge permanently removes material from trash
+func (m MaterialRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return m.store.purge(ctx, materialsTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes materials deleted before given time
+func (m MaterialRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.store.purgeDeletedBefore(ctx, materialsTable, before)
+}
+
+// Count return number of materials selected by query
+func (m MaterialRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	var count int64
+
+	where, args := materialsTable.where(m.store.dialect, q, "")
+
+	err := m.store.queryRow(ctx, "SELECT COUNT(*) FROM materials"+where, args, func(sc scanner) error {
+		return sc.Scan(&count)
+	})
+
+	return count, err
+}
+
+// ReassignCategory moves live materials of category to another one
+func (m MaterialRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	return m.store.reassignChildren(ctx, materialsTable, from, to)
+}
+
+// DeleteByCategory moves live materials of category to trash
+func (m MaterialRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	return m.store.childrenToTrash(ctx, materialsTable, categoryID, deletedBy)
+}
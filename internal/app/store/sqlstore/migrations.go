@@ -0,0 +1,410 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	postsTable = table{
+		name:           "posts",
+		columns:        []string{"id", "title", "snippet", "slug", "category_id", "time", "metadesc", "postimg", "pagedata", "tag_ids", "author", "word_count", "reading_time", "status", "publish_at", "unpublish_at", "deleted"},
+		categoryColumn: "category_id",
+		tagColumn:      "tag_ids",
+		authored:       true,
+		timed:          true,
+		publishable:    true,
+		scheduled:      true,
+		softDelete:     true,
+		trash:          true,
+		versioned:      true,
+	}
+
+	categoriesTable = table{
+		name:       "categories",
+		columns:    []string{"id", "title", "subtitle", "slug", "metadesc", "deleted"},
+		softDelete: true,
+		trash:      true,
+		versioned:  true,
+	}
+
+	materialsTable = table{
+		name:           "materials",
+		columns:        []string{"id", "title", "matcategory_id", "slug", "descr", "time", "filelink", "tag_ids", "deleted"},
+		categoryColumn: "matcategory_id",
+		tagColumn:      "tag_ids",
+		timed:          true,
+		softDelete:     true,
+		trash:          true,
+		versioned:      true,
+		renamed:        map[string]string{"desc": "descr"},
+	}
+
+	matCategoriesTable = table{
+		name:       "matcategories",
+		columns:    []string{"id", "title", "slug", "descr", "deleted"},
+		softDelete: true,
+		trash:      true,
+		versioned:  true,
+		renamed:    map[string]string{"desc": "descr"},
+	}
+
+	servicesTable = table{
+		name:       "services",
+		columns:    []string{"id", "img", "title", "subtitle", "descr", "slug", "deleted"},
+		softDelete: true,
+		trash:      true,
+		versioned:  true,
+		renamed:    map[string]string{"desc": "descr"},
+	}
+
+	pagesTable = table{
+		name:        "pages",
+		columns:     []string{"id", "title", "subtitle", "metadesc", "url", "pagedata", "status", "deleted"},
+		publishable: true,
+		softDelete:  true,
+		trash:       true,
+		versioned:   true,
+		renamed:     map[string]string{"desc": "metadesc"},
+	}
+
+	tagsTable = table{
+		name:       "tags",
+		columns:    []string{"id", "title", "slug", "deleted"},
+		softDelete: true,
+		trash:      true,
+		versioned:  true,
+	}
+
+	// Snapshot column is read only by FindByID, so it is not listed here
+	revisionsTable = table{
+		name:    "revisions",
+		columns: []string{"id", "doc_id", "doc_type", "author", "time", "comment", "transition"},
+		timed:   true,
+	}
+
+	usersTable = table{
+		name:       "users",
+		columns:    []string{"id", "username", "pswd", "email", "author_slug", "author", "deleted"},
+		softDelete: true,
+	}
+)
+
+// migration describes single versioned change of schema
+type migration struct {
+	version     int
+	description string
+	statements  func(d *dialect) []string
+}
+
+// migrations must be ordered by version, applied migration must never be changed
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create tables and indexes",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE posts (
+					id CHAR(24) PRIMARY KEY,
+					title TEXT NOT NULL DEFAULT '',
+					snippet TEXT NOT NULL DEFAULT '',
+					slug TEXT NOT NULL DEFAULT '',
+					category_id CHAR(24) NOT NULL,
+					time ` + d.timeType + ` NOT NULL,
+					metadesc TEXT NOT NULL DEFAULT '',
+					postimg TEXT NOT NULL DEFAULT '',
+					pagedata ` + d.jsonType + `,
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX posts_slug_unique ON posts (slug) WHERE NOT deleted`,
+				`CREATE INDEX posts_deleted_time ON posts (deleted, time DESC)`,
+				`CREATE INDEX posts_deleted_category_time ON posts (deleted, category_id, time DESC)`,
+
+				`CREATE TABLE categories (
+					id CHAR(24) PRIMARY KEY,
+					title TEXT NOT NULL DEFAULT '',
+					subtitle TEXT NOT NULL DEFAULT '',
+					slug TEXT NOT NULL DEFAULT '',
+					metadesc TEXT NOT NULL DEFAULT '',
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX categories_slug_unique ON categories (slug) WHERE NOT deleted`,
+
+				`CREATE TABLE materials (
+					id CHAR(24) PRIMARY KEY,
+					title TEXT NOT NULL DEFAULT '',
+					matcategory_id CHAR(24) NOT NULL,
+					slug TEXT NOT NULL DEFAULT '',
+					descr TEXT NOT NULL DEFAULT '',
+					time ` + d.timeType + ` NOT NULL,
+					filelink TEXT NOT NULL DEFAULT '',
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX materials_slug_unique ON materials (slug) WHERE NOT deleted`,
+				`CREATE INDEX materials_deleted_time ON materials (deleted, time DESC)`,
+				`CREATE INDEX materials_deleted_matcategory_time ON materials (deleted, matcategory_id, time DESC)`,
+
+				`CREATE TABLE matcategories (
+					id CHAR(24) PRIMARY KEY,
+					title TEXT NOT NULL DEFAULT '',
+					slug TEXT NOT NULL DEFAULT '',
+					descr TEXT NOT NULL DEFAULT '',
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX matcategories_slug_unique ON matcategories (slug) WHERE NOT deleted`,
+
+				`CREATE TABLE services (
+					id CHAR(24) PRIMARY KEY,
+					img ` + d.jsonType + `,
+					title TEXT NOT NULL DEFAULT '',
+					subtitle TEXT NOT NULL DEFAULT '',
+					descr TEXT NOT NULL DEFAULT '',
+					slug TEXT NOT NULL DEFAULT '',
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX services_slug_unique ON services (slug) WHERE NOT deleted`,
+
+				`CREATE TABLE pages (
+					id CHAR(24) PRIMARY KEY,
+					title TEXT NOT NULL DEFAULT '',
+					subtitle TEXT NOT NULL DEFAULT '',
+					metadesc TEXT NOT NULL DEFAULT '',
+					url TEXT NOT NULL DEFAULT '',
+					pagedata ` + d.jsonType + `,
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX pages_url_unique ON pages (url)`,
+
+				`CREATE TABLE users (
+					id CHAR(24) PRIMARY KEY,
+					username TEXT NOT NULL,
+					pswd TEXT NOT NULL,
+					email TEXT NOT NULL DEFAULT '',
+					deleted BOOLEAN NOT NULL DEFAULT FALSE
+				)`,
+				`CREATE UNIQUE INDEX users_username_unique ON users (username) WHERE NOT deleted`,
+				`CREATE UNIQUE INDEX users_email_unique ON users (email) WHERE email <> '' AND NOT deleted`,
+			}
+		},
+	},
+	{
+		version:     2,
+		description: "track deletion time and soft delete pages",
+		statements: func(d *dialect) []string {
+			stmts := []string{
+				`DROP INDEX pages_url_unique`,
+				`CREATE UNIQUE INDEX pages_url_unique ON pages (url) WHERE NOT deleted`,
+			}
+
+			for _, t := range []string{"posts", "categories", "materials", "matcategories", "services", "pages"} {
+				stmts = append(stmts,
+					`ALTER TABLE `+t+` ADD COLUMN deleted_at `+d.timeType,
+					`ALTER TABLE `+t+` ADD COLUMN deleted_by TEXT NOT NULL DEFAULT ''`,
+					// Retention period of already deleted rows starts now
+					`UPDATE `+t+` SET deleted_at = CURRENT_TIMESTAMP WHERE deleted`,
+					`CREATE INDEX `+t+`_deleted_at ON `+t+` (deleted_at) WHERE deleted`,
+				)
+			}
+
+			return stmts
+		},
+	},
+	{
+		version:     3,
+		description: "create revisions table",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE revisions (
+					id CHAR(24) PRIMARY KEY,
+					doc_id CHAR(24) NOT NULL,
+					doc_type TEXT NOT NULL,
+					author TEXT NOT NULL DEFAULT '',
+					time ` + d.timeType + ` NOT NULL,
+					comment TEXT NOT NULL DEFAULT '',
+					snapshot ` + d.jsonType + `
+				)`,
+				`CREATE INDEX revisions_doc_time ON revisions (doc_id, time DESC)`,
+			}
+		},
+	}, {
+		version:     4,
+		description: "add version column",
+		statements: func(d *dialect) []string {
+			var stmts []string
+			for _, t := range []string{"posts", "categories", "materials", "matcategories", "services", "pages"} {
+				stmts = append(stmts, `ALTER TABLE `+t+` ADD COLUMN version BIGINT NOT NULL DEFAULT 0`)
+			}
+
+			return stmts
+		},
+	}, {
+		version:     5,
+		description: "create redirects table",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE redirects (
+					id CHAR(24) PRIMARY KEY,
+					doc_id CHAR(24) NOT NULL,
+					doc_type TEXT NOT NULL,
+					slug TEXT NOT NULL,
+					time ` + d.timeType + ` NOT NULL
+				)`,
+				`CREATE INDEX redirects_type_slug_time ON redirects (doc_type, slug, time DESC)`,
+			}
+		},
+	}, {
+		version:     6,
+		description: "create tags table and tag columns",
+		statements: func(d *dialect) []string {
+			return []string{
+				`CREATE TABLE tags (
+					id CHAR(24) PRIMARY KEY,
+					title TEXT NOT NULL DEFAULT '',
+					slug TEXT NOT NULL DEFAULT '',
+					deleted BOOLEAN NOT NULL DEFAULT FALSE,
+					deleted_at ` + d.timeType + `,
+					deleted_by TEXT NOT NULL DEFAULT '',
+					version BIGINT NOT NULL DEFAULT 0
+				)`,
+				`CREATE UNIQUE INDEX tags_slug_unique ON tags (slug) WHERE NOT deleted`,
+				`CREATE INDEX tags_deleted_at ON tags (deleted_at) WHERE deleted`,
+				// Tag IDs are JSON arrays like blocks of pagedata, posts of tag are selected by time index and filtered
+				`ALTER TABLE posts ADD COLUMN tag_ids ` + d.jsonType,
+				`ALTER TABLE materials ADD COLUMN tag_ids ` + d.jsonType,
+			}
+		},
+	}, {
+		version:     7,
+		description: "add publication status and schedule of posts",
+		statements: func(d *dialect) []string {
+			return []string{
+				// Existing posts were visible, so they are published
+				`ALTER TABLE posts ADD COLUMN status TEXT NOT NULL DEFAULT 'published'`,
+				`ALTER TABLE posts ADD COLUMN publish_at ` + d.timeType,
+				`ALTER TABLE posts ADD COLUMN unpublish_at ` + d.timeType,
+				`CREATE INDEX posts_deleted_status_time ON posts (deleted, status, time DESC)`,
+				`CREATE INDEX posts_status_unpublish_at ON posts (status, unpublish_at) WHERE unpublish_at IS NOT NULL`,
+			}
+		},
+	}, {
+		version:     8,
+		description: "add publication status of pages and transitions of revisions",
+		statements: func(d *dialect) []string {
+			return []string{
+				// Existing pages were visible, so they are published
+				`ALTER TABLE pages ADD COLUMN status TEXT NOT NULL DEFAULT 'published'`,
+				`ALTER TABLE revisions ADD COLUMN transition ` + d.jsonType,
+			}
+		},
+	}, {
+		version:     9,
+		description: "add authors of posts and author profiles of users",
+		statements: func(d *dialect) []string {
+			return []string{
+				`ALTER TABLE posts ADD COLUMN author TEXT NOT NULL DEFAULT ''`,
+				`CREATE INDEX posts_deleted_status_author_time ON posts (deleted, status, author, time DESC)`,
+				// Slug is copied out of profile, so author page is found by unique index
+				`ALTER TABLE users ADD COLUMN author_slug TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE users ADD COLUMN author ` + d.jsonType,
+				`CREATE UNIQUE INDEX users_author_slug_unique ON users (author_slug) WHERE author_slug <> '' AND NOT deleted`,
+			}
+		},
+	}, {
+		version:     10,
+		description: "add word count and reading time of posts",
+		statements: func(d *dialect) []string {
+			return []string{
+				// Existing posts get them on next save, until then they are counted on display
+				`ALTER TABLE posts ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0`,
+				`ALTER TABLE posts ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0`,
+			}
+		},
+	},
+}
+
+// Migrate applies pending migrations in order of versions and returns descriptions of applied ones
+// Every migration is applied in its own transaction
+func (s *SQLStore) Migrate(ctx context.Context) ([]string, error) {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at `+s.dialect.timeType+` NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		if err = s.applyMigration(ctx, m); err != nil {
+			return res, fmt.Errorf("migration %d (%s): %w", m.version, m.description, err)
+		}
+
+		res = append(res, fmt.Sprintf("%d: %s", m.version, m.description))
+	}
+
+	return res, nil
+}
+
+// appliedMigrations returns set of applied versions
+// Rows are closed before return, so single connection pool is free for migration transaction
+func (s *SQLStore) appliedMigrations(ctx context.Context) (map[int]bool, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT version FROM migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var v int
+		if err = rows.Scan(&v); err != nil {
+			return nil, err
+		}
+
+		applied[v] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// applyMigration runs statements of migration and records it
+func (s *SQLStore) applyMigration(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.statements(s.dialect) {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.dialect.rebind("INSERT INTO migrations (version, description, applied_at) VALUES (?, ?, ?)"),
+		m.version, m.description, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EnsureIndexes applies pending migrations
+// Unlike MongoDB tables and their indexes are part of schema, so database is unusable without them
+func (s *SQLStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Migrate(ctx)
+
+	return err
+}
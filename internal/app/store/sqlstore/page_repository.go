@@ -0,0 +1,152 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PageRepository implements IPageRepository
+type PageRepository struct {
+	store *SQLStore
+}
+
+func pageArgs(page *models.Page) []interface{} {
+	return []interface{}{
+		objectID{&page.ID}, page.Title, page.Subtitle, page.MetaDesc, page.URL, jsonColumn{page.PageData}, string(page.Status), page.Deleted,
+	}
+}
+
+func scanPage(sc scanner) (*models.Page, error) {
+	page := &models.Page{}
+
+	err := sc.Scan(
+		objectID{&page.ID}, &page.Title, &page.Subtitle, &page.MetaDesc, &page.URL, jsonColumn{&page.PageData},
+		&page.Status, &page.Deleted, nullTime{&page.DeletedAt}, &page.DeletedBy, &page.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// Create save new page
+func (p PageRepository) Create(ctx context.Context, page *models.Page) error {
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	fpage, _ := p.FindByURL(ctx, page.URL)
+	if fpage != nil {
+		return helpers.ErrPageAlreadyExist
+	}
+
+	_, err := p.store.exec(ctx, pagesTable.insert(), pageArgs(page)...)
+
+	return p.store.duplicateErr(err, "url", helpers.ErrPageAlreadyExist)
+}
+
+func (p PageRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.Page, error) {
+	var page *models.Page
+
+	err := p.store.queryRow(ctx, "SELECT "+pagesTable.selectColumns("")+" FROM pages WHERE "+where, args, func(sc scanner) error {
+		var err error
+		page, err = scanPage(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// FindByURL return page by it URL
+func (p PageRepository) FindByURL(ctx context.Context, URL string) (*models.Page, error) {
+	return p.findOne(ctx, "url = ? AND deleted = ?", URL, false)
+}
+
+// FindByID return page by it ID
+func (p PageRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Page, error) {
+	return p.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// List return pages selected by query
+func (p PageRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Page, error) {
+	where, args := pagesTable.where(p.store.dialect, q, "")
+	pages := make([]*models.Page, 0)
+
+	err := p.store.query(ctx, "SELECT "+pagesTable.selectColumns("")+" FROM pages"+where+pagesTable.orderLimit(p.store.dialect, q, ""), args, func(sc scanner) error {
+		page, err := scanPage(sc)
+		if err != nil {
+			return err
+		}
+
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Update validate update page model and try to update it in db
+// Deleted mark is kept as is, page is moved to trash only by Delete
+func (p PageRepository) Update(ctx context.Context, updatedPage *models.Page) error {
+	if err := updatedPage.Validate(); err != nil {
+		return err
+	}
+
+	err := p.store.updateVersion(ctx, pagesTable, "UPDATE pages SET title = ?, subtitle = ?, metadesc = ?, url = ?, pagedata = ?, version = version + 1 WHERE id = ? AND version = ?",
+		[]interface{}{updatedPage.Title, updatedPage.Subtitle, updatedPage.MetaDesc, updatedPage.URL, jsonColumn{updatedPage.PageData}, updatedPage.ID.Hex()},
+		updatedPage.ID, &updatedPage.Version)
+
+	return p.store.duplicateErr(err, "url", helpers.ErrPageAlreadyExist)
+}
+
+// Patch validate page and save only its given fields
+func (p PageRepository) Patch(ctx context.Context, updatedPage *models.Page, fields []string) error {
+	if err := updatedPage.Validate(); err != nil {
+		return err
+	}
+
+	err := p.store.patchVersion(ctx, pagesTable, pageArgs(updatedPage), fields, updatedPage.ID, &updatedPage.Version)
+
+	return p.store.duplicateErr(err, "url", helpers.ErrPageAlreadyExist)
+}
+
+// Delete moves page to trash
+func (p PageRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return p.store.moveToTrash(ctx, pagesTable, deletedID, deletedBy)
+}
+
+// Restore brings page back from trash unless its url is taken by another page
+func (p PageRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	page, err := p.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if fpage, _ := p.FindByURL(ctx, page.URL); fpage != nil {
+		return helpers.ErrPageAlreadyExist
+	}
+
+	return p.store.duplicateErr(p.store.restore(ctx, pagesTable, ID), "url", helpers.ErrPageAlreadyExist)
+}
+
+// Purge permanently removes page from trash
+func (p PageRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return p.store.purge(ctx, pagesTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes pages deleted before given time
+func (p PageRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.store.purgeDeletedBefore(ctx, pagesTable, before)
+}
@@ -0,0 +1,245 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostRepository implements IPostRepository
+type PostRepository struct {
+	store *SQLStore
+}
+
+func postArgs(post *models.Post) []interface{} {
+	return []interface{}{
+		objectID{&post.ID}, post.Title, post.Snippet, post.Slug, objectID{&post.CategoryID},
+		post.Time.UTC(), post.MetaDesc, post.PostImg, jsonColumn{post.PageData}, jsonColumn{post.TagIDs},
+		post.Author, post.WordCount, post.ReadingTime, string(post.Status), nullTime{&post.PublishAt}, nullTime{&post.UnpublishAt},
+		post.Deleted,
+	}
+}
+
+func scanPost(sc scanner) (*models.Post, error) {
+	post := &models.Post{}
+
+	err := sc.Scan(
+		objectID{&post.ID}, &post.Title, &post.Snippet, &post.Slug, objectID{&post.CategoryID},
+		&post.Time, &post.MetaDesc, &post.PostImg, jsonColumn{&post.PageData}, jsonColumn{&post.TagIDs},
+		&post.Author, &post.WordCount, &post.ReadingTime, &post.Status, nullTime{&post.PublishAt}, nullTime{&post.UnpublishAt},
+		&post.Deleted, nullTime{&post.DeletedAt}, &post.DeletedBy, &post.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// Create save new post
+func (p PostRepository) Create(ctx context.Context, post *models.Post) error {
+	if err := post.Validate(); err != nil {
+		return err
+	}
+
+	fpost, _ := p.FindBySlug(ctx, post.Slug)
+	if fpost != nil {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	_, err := p.store.exec(ctx, postsTable.insert(), postArgs(post)...)
+
+	return p.store.duplicateErr(err, "slug", helpers.ErrPostAlreadyExist)
+}
+
+func (p PostRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.Post, error) {
+	var post *models.Post
+
+	err := p.store.queryRow(ctx, "SELECT "+postsTable.selectColumns("")+" FROM posts WHERE "+where, args, func(sc scanner) error {
+		var err error
+		post, err = scanPost(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// FindBySlug lookup post by it slug
+func (p PostRepository) FindBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return p.findOne(ctx, "slug = ? AND deleted = ?", slug, false)
+}
+
+// FindByID lookup post by it ID
+func (p PostRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Post, error) {
+	return p.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// List return posts selected by query
+func (p PostRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	where, args := postsTable.where(p.store.dialect, q, "")
+	posts := make([]*models.Post, 0)
+
+	err := p.store.query(ctx, "SELECT "+postsTable.selectColumns("")+" FROM posts"+where+postsTable.orderLimit(p.store.dialect, q, ""), args, func(sc scanner) error {
+		post, err := scanPost(sc)
+		if err != nil {
+			return err
+		}
+
+		posts = append(posts, post)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// joinCategory joins live category to posts, like $unwind in mongostore posts of missing or trashed categories are skipped
+const joinCategory = ` FROM posts p JOIN categories c ON c.id = p.category_id AND NOT c.deleted`
+
+// ListPublishedWithCategory return published posts selected by query with joined category slug
+func (p PostRepository) ListPublishedWithCategory(ctx context.Context, q store.ListQuery) ([]*models.Post, error) {
+	q.Deleted = store.NotDeleted
+	q.Status = models.StatusPublished
+
+	where, args := postsTable.where(p.store.dialect, q, "p.")
+	posts := make([]*models.Post, 0)
+
+	err := p.store.aggregate(ctx, `SELECT p.id, p.title, p.snippet, p.postimg, p.time, p.slug, c.slug, c.title`+
+		joinCategory+where+postsTable.orderLimit(p.store.dialect, q, "p."), args, func(sc scanner) error {
+		post := &models.Post{}
+
+		if err := sc.Scan(objectID{&post.ID}, &post.Title, &post.Snippet, &post.PostImg, &post.Time, &post.Slug, &post.CategorySlug, &post.CategoryTitle); err != nil {
+			return err
+		}
+
+		posts = append(posts, post)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// ListPublishedWithTotal return page of published posts with joined category and number of all posts selected by query
+// Total is selected by subquery of the same statement, separate count is needed only for empty page
+// Total counts only posts with live category, so it agrees with pages
+func (p PostRepository) ListPublishedWithTotal(ctx context.Context, q store.ListQuery) (*store.PostListing, error) {
+	q.Deleted = store.NotDeleted
+	q.Status = models.StatusPublished
+
+	where, args := postsTable.where(p.store.dialect, q, "p.")
+	count := `SELECT COUNT(*)` + joinCategory + where
+	listing := &store.PostListing{Posts: make([]*models.Post, 0)}
+
+	err := p.store.aggregate(ctx, `SELECT p.id, p.title, p.snippet, p.postimg, p.time, p.slug, c.slug, c.title, (`+count+`)`+
+		joinCategory+where+postsTable.orderLimit(p.store.dialect, q, "p."), append(append([]interface{}{}, args...), args...), func(sc scanner) error {
+		post := &models.Post{}
+
+		if err := sc.Scan(objectID{&post.ID}, &post.Title, &post.Snippet, &post.PostImg, &post.Time, &post.Slug, &post.CategorySlug, &post.CategoryTitle, &listing.Total); err != nil {
+			return err
+		}
+
+		listing.Posts = append(listing.Posts, post)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(listing.Posts) == 0 {
+		err = p.store.queryRow(ctx, count, args, func(sc scanner) error {
+			return sc.Scan(&listing.Total)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return listing, nil
+}
+
+// Count return number of posts selected by query
+func (p PostRepository) Count(ctx context.Context, q store.ListQuery) (int64, error) {
+	var count int64
+
+	where, args := postsTable.where(p.store.dialect, q, "")
+
+	err := p.store.queryRow(ctx, "SELECT COUNT(*) FROM posts"+where, args, func(sc scanner) error {
+		return sc.Scan(&count)
+	})
+
+	return count, err
+}
+
+// Update recieve post, validate it and try to update it
+func (p PostRepository) Update(ctx context.Context, updatedPost *models.Post) error {
+	if err := updatedPost.Validate(); err != nil {
+		return err
+	}
+
+	args := append(postArgs(updatedPost)[1:], updatedPost.ID.Hex())
+	err := p.store.updateVersion(ctx, postsTable, postsTable.update(), args, updatedPost.ID, &updatedPost.Version)
+
+	return p.store.duplicateErr(err, "slug", helpers.ErrPostAlreadyExist)
+}
+
+// Patch validate post and save only its given fields
+func (p PostRepository) Patch(ctx context.Context, updatedPost *models.Post, fields []string) error {
+	if err := updatedPost.Validate(); err != nil {
+		return err
+	}
+
+	err := p.store.patchVersion(ctx, postsTable, postArgs(updatedPost), fields, updatedPost.ID, &updatedPost.Version)
+
+	return p.store.duplicateErr(err, "slug", helpers.ErrPostAlreadyExist)
+}
+
+// Delete moves post to trash
+func (p PostRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return p.store.moveToTrash(ctx, postsTable, deletedID, deletedBy)
+}
+
+// Restore brings post back from trash unless its slug is taken by another post
+func (p PostRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	post, err := p.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if fpost, _ := p.FindBySlug(ctx, post.Slug); fpost != nil {
+		return helpers.ErrPostAlreadyExist
+	}
+
+	return p.store.duplicateErr(p.store.restore(ctx, postsTable, ID), "slug", helpers.ErrPostAlreadyExist)
+}
+
+// Purge permanently removes post from trash
+func (p PostRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return p.store.purge(ctx, postsTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes posts deleted before given time
+func (p PostRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.store.purgeDeletedBefore(ctx, postsTable, before)
+}
+
+// ReassignCategory moves live posts of category to another one
+func (p PostRepository) ReassignCategory(ctx context.Context, from, to primitive.ObjectID) (int64, error) {
+	return p.store.reassignChildren(ctx, postsTable, from, to)
+}
+
+// DeleteByCategory moves live posts of category to trash
+func (p PostRepository) DeleteByCategory(ctx context.Context, categoryID primitive.ObjectID, deletedBy string) (int64, error) {
+	return p.store.childrenToTrash(ctx, postsTable, categoryID, deletedBy)
+}
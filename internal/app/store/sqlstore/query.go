@@ -0,0 +1,314 @@
+package sqlstore
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// table describes columns of table and which ListQuery filters are applicable to it
+type table struct {
+	name           string
+	columns        []string          // First column is always primary key "id"
+	categoryColumn string            // Column with parent category ID, empty if there is no parent
+	tagColumn      string            // JSON column with array of tag IDs, empty if rows have no tags
+	authored       bool              // Rows have author column
+	timed          bool              // Rows have time column
+	publishable    bool              // Rows have status column
+	scheduled      bool              // Rows have publish_at and unpublish_at columns
+	softDelete     bool              // Deleted rows are hidden from listings
+	trash          bool              // Rows have deleted_at and deleted_by columns, which are changed only by trash operations
+	versioned      bool              // Rows have version column, which is incremented by each update
+	renamed        map[string]string // Columns of document fields with different BSON name
+}
+
+// trashColumns are selected after columns of tables with trash
+var trashColumns = []string{"deleted_at", "deleted_by"}
+
+// selectColumns returns comma separated columns prefixed with alias
+func (t table) selectColumns(alias string) string {
+	cols := make([]string, 0, len(t.columns)+len(trashColumns))
+	for _, c := range t.columns {
+		cols = append(cols, alias+c)
+	}
+
+	if t.trash {
+		for _, c := range trashColumns {
+			cols = append(cols, alias+c)
+		}
+	}
+
+	if t.versioned {
+		cols = append(cols, alias+"version")
+	}
+
+	return strings.Join(cols, ", ")
+}
+
+// insert returns INSERT statement for all columns
+func (t table) insert() string {
+	return "INSERT INTO " + t.name + " (" + strings.Join(t.columns, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", ") + ")"
+}
+
+// update returns UPDATE statement for all columns except id, id is the last argument
+// Row of versioned table is updated only when its version equals the argument after id
+func (t table) update() string {
+	sets := make([]string, 0, len(t.columns))
+	for _, c := range t.columns[1:] {
+		sets = append(sets, c+" = ?")
+	}
+
+	if !t.versioned {
+		return "UPDATE " + t.name + " SET " + strings.Join(sets, ", ") + " WHERE id = ?"
+	}
+
+	sets = append(sets, "version = version + 1")
+
+	return "UPDATE " + t.name + " SET " + strings.Join(sets, ", ") + " WHERE id = ? AND version = ?"
+}
+
+// patch returns versioned UPDATE statement for columns of given BSON fields, id and version are the last arguments
+// Positions of columns in t.columns are returned too, so their values are picked from full row arguments
+func (t table) patch(fields []string) (string, []int, error) {
+	sets := make([]string, 0, len(fields)+1)
+	positions := make([]int, 0, len(fields))
+
+	for _, f := range fields {
+		column := f
+		if c, ok := t.renamed[f]; ok {
+			column = c
+		}
+
+		pos := -1
+		for i, c := range t.columns[1:] {
+			if c == column {
+				pos = i + 1
+				break
+			}
+		}
+
+		if pos == -1 {
+			return "", nil, fmt.Errorf("%s has no column for field %q", t.name, f)
+		}
+
+		sets = append(sets, column+" = ?")
+		positions = append(positions, pos)
+	}
+
+	sets = append(sets, "version = version + 1")
+
+	return "UPDATE " + t.name + " SET " + strings.Join(sets, ", ") + " WHERE id = ? AND version = ?", positions, nil
+}
+
+// where returns WHERE clause with arguments for query, alias prefixes column names
+func (t table) where(d *dialect, q store.ListQuery, alias string) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	switch {
+	case !t.softDelete:
+	case q.Deleted == store.NotDeleted:
+		conds = append(conds, alias+"deleted = ?")
+		args = append(args, false)
+	case q.Deleted == store.OnlyDeleted:
+		conds = append(conds, alias+"deleted = ?")
+		args = append(args, true)
+	}
+
+	if t.categoryColumn != "" && !q.CategoryID.IsZero() {
+		conds = append(conds, alias+t.categoryColumn+" = ?")
+		args = append(args, q.CategoryID.Hex())
+	}
+
+	if t.tagColumn != "" && !q.TagID.IsZero() {
+		conds = append(conds, fmt.Sprintf(d.jsonHas, alias+t.tagColumn))
+		args = append(args, q.TagID.Hex())
+	}
+
+	if t.authored && q.Author != "" {
+		conds = append(conds, alias+"author = ?")
+		args = append(args, q.Author)
+	}
+
+	if t.publishable && q.Status != "" {
+		conds = append(conds, alias+"status = ?")
+		args = append(args, string(q.Status))
+	}
+
+	// Comparison with NULL is never true, so rows without unpublish_at are skipped
+	if t.scheduled && !q.UnpublishBefore.IsZero() {
+		conds = append(conds, alias+"unpublish_at < ?")
+		args = append(args, q.UnpublishBefore.UTC())
+	}
+
+	if t.timed && !q.From.IsZero() {
+		conds = append(conds, alias+"time >= ?")
+		args = append(args, q.From.UTC())
+	}
+
+	if t.timed && !q.To.IsZero() {
+		conds = append(conds, alias+"time < ?")
+		args = append(args, q.To.UTC())
+	}
+
+	if q.Text != "" {
+		conds = append(conds, "LOWER("+alias+"title) LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(strings.ToLower(q.Text))+"%")
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// orderLimit returns ORDER BY, LIMIT and OFFSET clauses for query
+func (t table) orderLimit(d *dialect, q store.ListQuery, alias string) string {
+	var clause string
+
+	switch {
+	case q.Sort == store.SortTitle:
+		clause = " ORDER BY " + alias + "title"
+	case !t.timed:
+		// Natural order like in mongo
+	case q.Sort == store.SortOldest:
+		clause = " ORDER BY " + alias + "time"
+	default:
+		clause = " ORDER BY " + alias + "time DESC"
+	}
+
+	if q.Limit > 0 {
+		clause += " LIMIT " + strconv.FormatInt(q.Limit, 10)
+	} else if q.Offset > 0 {
+		clause += " LIMIT " + d.noLimit
+	}
+
+	if q.Offset > 0 {
+		clause += " OFFSET " + strconv.FormatInt(q.Offset, 10)
+	}
+
+	return clause
+}
+
+// escapeLike escapes wildcards of LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// objectID stores primitive.ObjectID as hex string
+type objectID struct {
+	id *primitive.ObjectID
+}
+
+// Value implements driver.Valuer
+func (o objectID) Value() (driver.Value, error) {
+	return o.id.Hex(), nil
+}
+
+// Scan implements sql.Scanner
+func (o objectID) Scan(src interface{}) error {
+	var hex string
+
+	switch v := src.(type) {
+	case nil:
+		*o.id = primitive.NilObjectID
+		return nil
+	case string:
+		hex = v
+	case []byte:
+		hex = string(v)
+	default:
+		return fmt.Errorf("sqlstore: can't scan %T into ObjectID", src)
+	}
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return err
+	}
+
+	*o.id = id
+
+	return nil
+}
+
+// nullTime stores zero time as NULL and reads NULL as zero time
+type nullTime struct {
+	t *time.Time
+}
+
+// Value implements driver.Valuer
+func (n nullTime) Value() (driver.Value, error) {
+	if n.t.IsZero() {
+		return nil, nil
+	}
+
+	return n.t.UTC(), nil
+}
+
+// Scan implements sql.Scanner
+func (n nullTime) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*n.t = time.Time{}
+		return nil
+	case time.Time:
+		*n.t = v
+		return nil
+	}
+
+	return fmt.Errorf("sqlstore: can't scan %T into time", src)
+}
+
+// jsonColumn stores nested documents as JSON
+type jsonColumn struct {
+	v interface{}
+}
+
+// Value implements driver.Valuer
+func (j jsonColumn) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (j jsonColumn) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), j.v)
+	case []byte:
+		return json.Unmarshal(v, j.v)
+	}
+
+	return fmt.Errorf("sqlstore: can't scan %T as JSON", src)
+}
+
+// duplicateErr replaces violation of unique index on field with friendly error
+// Indexes guard against concurrent inserts that passed check in Create
+func (s *SQLStore) duplicateErr(err error, field string, existErr error) error {
+	if s.dialect.isUniqueViolation(err, field) {
+		return existErr
+	}
+
+	return err
+}
@@ -0,0 +1,39 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+)
+
+// RedirectRepository implements IRedirectRepository
+type RedirectRepository struct {
+	store *SQLStore
+}
+
+// Create save new redirect
+func (r RedirectRepository) Create(ctx context.Context, redirect *models.Redirect) error {
+	if err := redirect.Validate(); err != nil {
+		return err
+	}
+
+	_, err := r.store.exec(ctx, "INSERT INTO redirects (id, doc_id, doc_type, slug, time) VALUES (?, ?, ?, ?, ?)",
+		objectID{&redirect.ID}, objectID{&redirect.DocID}, redirect.DocType, redirect.Slug, redirect.Time.UTC())
+
+	return err
+}
+
+// FindBySlug lookup the latest redirect from slug of document type
+func (r RedirectRepository) FindBySlug(ctx context.Context, docType, slug string) (*models.Redirect, error) {
+	redirect := &models.Redirect{}
+
+	err := r.store.queryRow(ctx, "SELECT id, doc_id, doc_type, slug, time FROM redirects WHERE doc_type = ? AND slug = ? ORDER BY time DESC LIMIT 1",
+		[]interface{}{docType, slug}, func(sc scanner) error {
+			return sc.Scan(objectID{&redirect.ID}, objectID{&redirect.DocID}, &redirect.DocType, &redirect.Slug, &redirect.Time)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return redirect, nil
+}
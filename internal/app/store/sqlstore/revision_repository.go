@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevisionRepository implements IRevisionRepository
+// Snapshot of post or page is stored as JSON in snapshot column
+type RevisionRepository struct {
+	store *SQLStore
+}
+
+func scanRevision(sc scanner, extra ...interface{}) (*models.Revision, error) {
+	rev := &models.Revision{}
+
+	dest := append([]interface{}{
+		objectID{&rev.ID}, objectID{&rev.DocID}, &rev.DocType, &rev.Author, &rev.Time, &rev.Comment, jsonColumn{&rev.Transition},
+	}, extra...)
+
+	if err := sc.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+// Create save new revision
+func (r RevisionRepository) Create(ctx context.Context, rev *models.Revision) error {
+	if err := rev.Validate(); err != nil {
+		return err
+	}
+
+	var snapshot interface{} = rev.Post
+	if rev.DocType == models.RevisionPage {
+		snapshot = rev.Page
+	}
+
+	_, err := r.store.exec(ctx, "INSERT INTO revisions ("+strings.Join(revisionsTable.columns, ", ")+", snapshot) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		objectID{&rev.ID}, objectID{&rev.DocID}, rev.DocType, rev.Author, rev.Time.UTC(), rev.Comment, jsonColumn{rev.Transition}, jsonColumn{snapshot})
+
+	return err
+}
+
+// FindByID lookup revision by it ID
+func (r RevisionRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Revision, error) {
+	var (
+		rev      *models.Revision
+		snapshot []byte
+	)
+
+	err := r.store.queryRow(ctx, "SELECT "+revisionsTable.selectColumns("")+", snapshot FROM revisions WHERE id = ?", []interface{}{ID.Hex()}, func(sc scanner) error {
+		var err error
+		rev, err = scanRevision(sc, &snapshot)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch rev.DocType {
+	case models.RevisionPost:
+		rev.Post = &models.Post{}
+		err = jsonColumn{rev.Post}.Scan(snapshot)
+	case models.RevisionPage:
+		rev.Page = &models.Page{}
+		err = jsonColumn{rev.Page}.Scan(snapshot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+// ListByDocument return revisions of document selected by query
+func (r RevisionRepository) ListByDocument(ctx context.Context, docID primitive.ObjectID, q store.ListQuery) ([]*models.Revision, error) {
+	q.Text = ""
+
+	where, args := revisionsTable.where(r.store.dialect, q, "")
+	if where == "" {
+		where = " WHERE doc_id = ?"
+	} else {
+		where += " AND doc_id = ?"
+	}
+	args = append(args, docID.Hex())
+
+	revs := make([]*models.Revision, 0)
+
+	err := r.store.query(ctx, "SELECT "+revisionsTable.selectColumns("")+" FROM revisions"+where+revisionsTable.orderLimit(r.store.dialect, q, ""), args, func(sc scanner) error {
+		rev, err := scanRevision(sc)
+		if err != nil {
+			return err
+		}
+
+		revs = append(revs, rev)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revs, nil
+}
@@ -0,0 +1,152 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServiceRepository implements IServiceRepository
+type ServiceRepository struct {
+	store *SQLStore
+}
+
+func serviceArgs(service *models.Service) []interface{} {
+	return []interface{}{
+		objectID{&service.ID}, jsonColumn{service.Img}, service.Title, service.Subtitle,
+		service.Desc, service.Slug, service.Deleted,
+	}
+}
+
+func scanService(sc scanner) (*models.Service, error) {
+	service := &models.Service{}
+
+	err := sc.Scan(
+		objectID{&service.ID}, jsonColumn{&service.Img}, &service.Title, &service.Subtitle,
+		&service.Desc, &service.Slug,
+		&service.Deleted, nullTime{&service.DeletedAt}, &service.DeletedBy, &service.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// Create save new service
+func (s ServiceRepository) Create(ctx context.Context, service *models.Service) error {
+	if err := service.Validate(); err != nil {
+		return err
+	}
+
+	fservice, _ := s.FindBySlug(ctx, service.Slug)
+	if fservice != nil {
+		return helpers.ErrServiceAlreadyExist
+	}
+
+	_, err := s.store.exec(ctx, servicesTable.insert(), serviceArgs(service)...)
+
+	return s.store.duplicateErr(err, "slug", helpers.ErrServiceAlreadyExist)
+}
+
+func (s ServiceRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.Service, error) {
+	var service *models.Service
+
+	err := s.store.queryRow(ctx, "SELECT "+servicesTable.selectColumns("")+" FROM services WHERE "+where, args, func(sc scanner) error {
+		var err error
+		service, err = scanService(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// FindBySlug lookup service by it slug
+func (s ServiceRepository) FindBySlug(ctx context.Context, slug string) (*models.Service, error) {
+	return s.findOne(ctx, "slug = ? AND deleted = ?", slug, false)
+}
+
+// FindByID lookup service by it id
+func (s ServiceRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Service, error) {
+	return s.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// List return services selected by query
+func (s ServiceRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Service, error) {
+	where, args := servicesTable.where(s.store.dialect, q, "")
+	services := make([]*models.Service, 0)
+
+	err := s.store.query(ctx, "SELECT "+servicesTable.selectColumns("")+" FROM services"+where+servicesTable.orderLimit(s.store.dialect, q, ""), args, func(sc scanner) error {
+		service, err := scanService(sc)
+		if err != nil {
+			return err
+		}
+
+		services = append(services, service)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// Update validate updated service and try to update it in db
+func (s ServiceRepository) Update(ctx context.Context, updatedService *models.Service) error {
+	if err := updatedService.Validate(); err != nil {
+		return err
+	}
+
+	args := append(serviceArgs(updatedService)[1:], updatedService.ID.Hex())
+	err := s.store.updateVersion(ctx, servicesTable, servicesTable.update(), args, updatedService.ID, &updatedService.Version)
+
+	return s.store.duplicateErr(err, "slug", helpers.ErrServiceAlreadyExist)
+}
+
+// Patch validate service and save only its given fields
+func (s ServiceRepository) Patch(ctx context.Context, updatedService *models.Service, fields []string) error {
+	if err := updatedService.Validate(); err != nil {
+		return err
+	}
+
+	err := s.store.patchVersion(ctx, servicesTable, serviceArgs(updatedService), fields, updatedService.ID, &updatedService.Version)
+
+	return s.store.duplicateErr(err, "slug", helpers.ErrServiceAlreadyExist)
+}
+
+// Delete moves service to trash
+func (s ServiceRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return s.store.moveToTrash(ctx, servicesTable, deletedID, deletedBy)
+}
+
+// Restore brings service back from trash unless its slug is taken by another service
+func (s ServiceRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	service, err := s.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if fservice, _ := s.FindBySlug(ctx, service.Slug); fservice != nil {
+		return helpers.ErrServiceAlreadyExist
+	}
+
+	return s.store.duplicateErr(s.store.restore(ctx, servicesTable, ID), "slug", helpers.ErrServiceAlreadyExist)
+}
+
+// Purge permanently removes service from trash
+func (s ServiceRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return s.store.purge(ctx, servicesTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes services deleted before given time
+func (s ServiceRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return s.store.purgeDeletedBefore(ctx, servicesTable, before)
+}
@@ -0,0 +1,300 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+)
+
+// SQLStore represents store on top of relational database
+type SQLStore struct {
+	db                  *sql.DB
+	dialect             *dialect
+	timeouts            store.Timeouts
+	postRepository      *PostRepository
+	categoryRepository  *CategoryRepository
+	materialsRepository *MaterialRepository
+	matCatRepository    *MatCatRepository
+	userRepository      *UserRepository
+	pageRepository      *PageRepository
+	serviceRepository   *ServiceRepository
+	revisionRepository  *RevisionRepository
+	redirectRepository  *RedirectRepository
+	tagRepository       *TagRepository
+}
+
+// NewStore return new Store object or error
+// driver is either "sqlite" or "postgres", dsn is passed to the database driver as is
+// ctx limits only connection establishment, every query is limited by timeouts
+func NewStore(ctx context.Context, driver, dsn string, timeouts store.Timeouts) (*SQLStore, error) {
+	d, err := dialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows only one writer, besides every connection to in-memory database is separate database
+	if d.singleConn {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLStore{
+		db:       db,
+		dialect:  d,
+		timeouts: timeouts,
+	}, nil
+}
+
+// Close closes all connections of the pool
+func (s *SQLStore) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+// readContext limits single row lookups, selects and counts
+func (s *SQLStore) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.timeouts.Read)
+}
+
+// writeContext limits inserts and updates
+func (s *SQLStore) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.timeouts.Write)
+}
+
+// aggregateContext limits queries with joins
+func (s *SQLStore) aggregateContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.timeouts.Aggregate)
+}
+
+// withTimeout wraps ctx with timeout, zero timeout means only parent deadline is used
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// txKey keeps transaction in context
+type txKey struct{}
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns transaction of ctx if there is one, otherwise connection pool
+func (s *SQLStore) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return s.db
+}
+
+// WithTransaction runs fn in transaction, every statement run with ctx passed to fn is part of it
+func (s *SQLStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// exec runs statement written with "?" placeholders
+func (s *SQLStore) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := s.writeContext(ctx)
+	defer cancel()
+
+	return s.conn(ctx).ExecContext(ctx, s.dialect.rebind(query), args...)
+}
+
+// queryRow runs query written with "?" placeholders and calls scan for the first row
+func (s *SQLStore) queryRow(ctx context.Context, query string, args []interface{}, scan func(scanner) error) error {
+	ctx, cancel := s.readContext(ctx)
+	defer cancel()
+
+	err := scan(s.conn(ctx).QueryRowContext(ctx, s.dialect.rebind(query), args...))
+	if err == sql.ErrNoRows {
+		return store.ErrNotFound
+	}
+
+	return err
+}
+
+// query runs query written with "?" placeholders and calls scan for every row
+func (s *SQLStore) query(ctx context.Context, query string, args []interface{}, scan func(scanner) error) error {
+	ctx, cancel := s.readContext(ctx)
+	defer cancel()
+
+	return s.scanRows(ctx, query, args, scan)
+}
+
+// aggregate is the same as query but limited by aggregate timeout, it is used for queries with joins
+func (s *SQLStore) aggregate(ctx context.Context, query string, args []interface{}, scan func(scanner) error) error {
+	ctx, cancel := s.aggregateContext(ctx)
+	defer cancel()
+
+	return s.scanRows(ctx, query, args, scan)
+}
+
+func (s *SQLStore) scanRows(ctx context.Context, query string, args []interface{}, scan func(scanner) error) error {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+/*
+ * Implement Storer interface
+ */
+func (s *SQLStore) Posts() store.IPostRepository {
+	if s.postRepository != nil {
+		return s.postRepository
+	}
+
+	s.postRepository = &PostRepository{
+		store: s,
+	}
+
+	return s.postRepository
+}
+
+func (s *SQLStore) Categories() store.ICategoryRepository {
+	if s.categoryRepository != nil {
+		return s.categoryRepository
+	}
+
+	s.categoryRepository = &CategoryRepository{
+		store: s,
+	}
+
+	return s.categoryRepository
+}
+
+func (s *SQLStore) Materials() store.IMaterialRepository {
+	if s.materialsRepository != nil {
+		return s.materialsRepository
+	}
+
+	s.materialsRepository = &MaterialRepository{
+		store: s,
+	}
+
+	return s.materialsRepository
+}
+
+func (s *SQLStore) MatCategories() store.IMatCategoryRepository {
+	if s.matCatRepository != nil {
+		return s.matCatRepository
+	}
+
+	s.matCatRepository = &MatCatRepository{
+		store: s,
+	}
+
+	return s.matCatRepository
+}
+
+func (s *SQLStore) Users() store.IUserRepository {
+	if s.userRepository != nil {
+		return s.userRepository
+	}
+
+	s.userRepository = &UserRepository{
+		store: s,
+	}
+
+	return s.userRepository
+}
+
+func (s *SQLStore) Services() store.IServiceRepository {
+	if s.serviceRepository != nil {
+		return s.serviceRepository
+	}
+
+	s.serviceRepository = &ServiceRepository{
+		store: s,
+	}
+
+	return s.serviceRepository
+}
+
+func (s *SQLStore) Pages() store.IPageRepository {
+	if s.pageRepository != nil {
+		return s.pageRepository
+	}
+
+	s.pageRepository = &PageRepository{
+		store: s,
+	}
+
+	return s.pageRepository
+}
+
+func (s *SQLStore) Revisions() store.IRevisionRepository {
+	if s.revisionRepository != nil {
+		return s.revisionRepository
+	}
+
+	s.revisionRepository = &RevisionRepository{
+		store: s,
+	}
+
+	return s.revisionRepository
+}
+
+func (s *SQLStore) Redirects() store.IRedirectRepository {
+	if s.redirectRepository != nil {
+		return s.redirectRepository
+	}
+
+	s.redirectRepository = &RedirectRepository{
+		store: s,
+	}
+
+	return s.redirectRepository
+}
+
+func (s *SQLStore) Tags() store.ITagRepository {
+	if s.tagRepository != nil {
+		return s.tagRepository
+	}
+
+	s.tagRepository = &TagRepository{
+		store: s,
+	}
+
+	return s.tagRepository
+}
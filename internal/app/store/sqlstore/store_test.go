@@ -0,0 +1,60 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store/storetest"
+)
+
+// testStore returns store on top of in-memory SQLite database with applied migrations
+func testStore(t *testing.T) *SQLStore {
+	t.Helper()
+
+	s, err := NewStore(context.Background(), "sqlite", ":memory:", store.Timeouts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		s.Close(context.Background())
+	})
+
+	if _, err = s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestSQLStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Storer {
+		return testStore(t)
+	})
+}
+
+func TestSQLStore_Migrate(t *testing.T) {
+	s := testStore(t)
+
+	// Second run has nothing to apply
+	applied, err := s.Migrate(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	assert.NoError(t, s.EnsureIndexes(context.Background()))
+}
+
+func TestNewStore_UnsupportedDriver(t *testing.T) {
+	_, err := NewStore(context.Background(), "mysql", "", store.Timeouts{})
+
+	assert.Error(t, err)
+}
+
+func TestDialect_Rebind(t *testing.T) {
+	query := "SELECT id FROM posts WHERE slug = ? AND deleted = ?"
+
+	assert.Equal(t, query, sqliteDialect.rebind(query))
+	assert.Equal(t, "SELECT id FROM posts WHERE slug = $1 AND deleted = $2", postgresDialect.rebind(query))
+}
@@ -0,0 +1,166 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TagRepository implements ITagRepository
+type TagRepository struct {
+	store *SQLStore
+}
+
+func tagArgs(tag *models.Tag) []interface{} {
+	return []interface{}{objectID{&tag.ID}, tag.Title, tag.Slug, tag.Deleted}
+}
+
+func scanTag(sc scanner) (*models.Tag, error) {
+	tag := &models.Tag{}
+
+	if err := sc.Scan(objectID{&tag.ID}, &tag.Title, &tag.Slug, &tag.Deleted, nullTime{&tag.DeletedAt}, &tag.DeletedBy, &tag.Version); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Create save new tag
+func (t TagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+
+	ftag, _ := t.FindBySlug(ctx, tag.Slug)
+	if ftag != nil {
+		return helpers.ErrTagAlreadyExist
+	}
+
+	_, err := t.store.exec(ctx, tagsTable.insert(), tagArgs(tag)...)
+
+	return t.store.duplicateErr(err, "slug", helpers.ErrTagAlreadyExist)
+}
+
+func (t TagRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.Tag, error) {
+	var tag *models.Tag
+
+	err := t.store.queryRow(ctx, "SELECT "+tagsTable.selectColumns("")+" FROM tags WHERE "+where, args, func(sc scanner) error {
+		var err error
+		tag, err = scanTag(sc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// FindBySlug lookup tag by it slug
+func (t TagRepository) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	return t.findOne(ctx, "slug = ? AND deleted = ?", slug, false)
+}
+
+// FindByID lookup tag by it ID
+func (t TagRepository) FindByID(ctx context.Context, ID primitive.ObjectID) (*models.Tag, error) {
+	return t.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), false)
+}
+
+// find return tags selected by statement with "?" placeholders
+func (t TagRepository) find(ctx context.Context, query string, args []interface{}) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, 0)
+
+	err := t.store.query(ctx, query, args, func(sc scanner) error {
+		tag, err := scanTag(sc)
+		if err != nil {
+			return err
+		}
+
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// List return tags selected by query
+func (t TagRepository) List(ctx context.Context, q store.ListQuery) ([]*models.Tag, error) {
+	where, args := tagsTable.where(t.store.dialect, q, "")
+
+	return t.find(ctx, "SELECT "+tagsTable.selectColumns("")+" FROM tags"+where+tagsTable.orderLimit(t.store.dialect, q, ""), args)
+}
+
+// ListByIDs return live tags with given IDs sorted by title
+func (t TagRepository) ListByIDs(ctx context.Context, IDs []primitive.ObjectID) ([]*models.Tag, error) {
+	if len(IDs) == 0 {
+		return make([]*models.Tag, 0), nil
+	}
+
+	args := []interface{}{false}
+	for _, ID := range IDs {
+		args = append(args, ID.Hex())
+	}
+
+	return t.find(ctx, "SELECT "+tagsTable.selectColumns("")+" FROM tags WHERE deleted = ? AND id IN ("+
+		strings.TrimSuffix(strings.Repeat("?, ", len(IDs)), ", ")+") ORDER BY title", args)
+}
+
+// Update validate tag and try to save it
+func (t TagRepository) Update(ctx context.Context, updatedTag *models.Tag) error {
+	if err := updatedTag.Validate(); err != nil {
+		return err
+	}
+
+	args := append(tagArgs(updatedTag)[1:], updatedTag.ID.Hex())
+	err := t.store.updateVersion(ctx, tagsTable, tagsTable.update(), args, updatedTag.ID, &updatedTag.Version)
+
+	return t.store.duplicateErr(err, "slug", helpers.ErrTagAlreadyExist)
+}
+
+// Patch validate tag and save only its given fields
+func (t TagRepository) Patch(ctx context.Context, updatedTag *models.Tag, fields []string) error {
+	if err := updatedTag.Validate(); err != nil {
+		return err
+	}
+
+	err := t.store.patchVersion(ctx, tagsTable, tagArgs(updatedTag), fields, updatedTag.ID, &updatedTag.Version)
+
+	return t.store.duplicateErr(err, "slug", helpers.ErrTagAlreadyExist)
+}
+
+// Delete moves tag to trash
+func (t TagRepository) Delete(ctx context.Context, deletedID primitive.ObjectID, deletedBy string) error {
+	return t.store.moveToTrash(ctx, tagsTable, deletedID, deletedBy)
+}
+
+// Restore brings tag back from trash unless its slug is taken by another tag
+func (t TagRepository) Restore(ctx context.Context, ID primitive.ObjectID) error {
+	tag, err := t.findOne(ctx, "id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	if ftag, _ := t.FindBySlug(ctx, tag.Slug); ftag != nil {
+		return helpers.ErrTagAlreadyExist
+	}
+
+	return t.store.duplicateErr(t.store.restore(ctx, tagsTable, ID), "slug", helpers.ErrTagAlreadyExist)
+}
+
+// Purge permanently removes tag from trash
+func (t TagRepository) Purge(ctx context.Context, ID primitive.ObjectID) error {
+	return t.store.purge(ctx, tagsTable, ID)
+}
+
+// PurgeDeletedBefore permanently removes tags deleted before given time
+func (t TagRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return t.store.purgeDeletedBefore(ctx, tagsTable, before)
+}
@@ -0,0 +1,62 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// moveToTrash marks live row of table as deleted
+// Repeated deletion keeps original deleted_at and deleted_by
+func (s *SQLStore) moveToTrash(ctx context.Context, t table, ID primitive.ObjectID, deletedBy string) error {
+	_, err := s.exec(ctx, "UPDATE "+t.name+" SET deleted = ?, deleted_at = ?, deleted_by = ? WHERE id = ? AND deleted = ?",
+		true, time.Now().UTC(), deletedBy, ID.Hex(), false)
+
+	return err
+}
+
+// restore brings deleted row of table back
+func (s *SQLStore) restore(ctx context.Context, t table, ID primitive.ObjectID) error {
+	res, err := s.exec(ctx, "UPDATE "+t.name+" SET deleted = ?, deleted_at = NULL, deleted_by = '' WHERE id = ? AND deleted = ?",
+		false, ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	return affected(res.RowsAffected())
+}
+
+// purge permanently removes deleted row of table
+func (s *SQLStore) purge(ctx context.Context, t table, ID primitive.ObjectID) error {
+	res, err := s.exec(ctx, "DELETE FROM "+t.name+" WHERE id = ? AND deleted = ?", ID.Hex(), true)
+	if err != nil {
+		return err
+	}
+
+	return affected(res.RowsAffected())
+}
+
+// purgeDeletedBefore permanently removes rows of table deleted before given time
+func (s *SQLStore) purgeDeletedBefore(ctx context.Context, t table, before time.Time) (int64, error) {
+	res, err := s.exec(ctx, "DELETE FROM "+t.name+" WHERE deleted = ? AND deleted_at < ?", true, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// affected returns store.ErrNotFound when statement changed nothing
+func affected(n int64, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserRepository implements IUserRepository
+type UserRepository struct {
+	store *SQLStore
+}
+
+// Create save new user
+func (u UserRepository) Create(ctx context.Context, usr *models.User) error {
+	if err := usr.Validate(); err != nil {
+		return err
+	}
+
+	// If username already taken
+	fusr, _ := u.FindByUsername(ctx, usr.Username)
+	if fusr != nil {
+		return helpers.ErrUserAlreadyExist
+	}
+
+	// If email already taken
+	if usr.Email != "" {
+		fusr, _ = u.FindByEmail(ctx, usr.Email)
+		if fusr != nil {
+			return helpers.ErrEmailAlreadyExist
+		}
+	}
+
+	if err := usr.BeforeSave(); err != nil {
+		return err
+	}
+
+	_, err := u.store.exec(ctx, usersTable.insert(),
+		objectID{&usr.ID}, usr.Username, usr.EncryptedPassword, usr.Email, authorSlug(usr.Author), jsonColumn{usr.Author}, usr.Deleted)
+
+	switch {
+	case u.store.dialect.isUniqueViolation(err, "username"):
+		return helpers.ErrUserAlreadyExist
+	case u.store.dialect.isUniqueViolation(err, "email"):
+		return helpers.ErrEmailAlreadyExist
+	}
+
+	return err
+}
+
+func (u UserRepository) findOne(ctx context.Context, where string, args ...interface{}) (*models.User, error) {
+	user := &models.User{}
+
+	err := u.store.queryRow(ctx, "SELECT "+usersTable.selectColumns("")+" FROM users WHERE "+where, args, func(sc scanner) error {
+		var slug string // Copy of author slug for lookup, profile holds it too
+		return sc.Scan(objectID{&user.ID}, &user.Username, &user.EncryptedPassword, &user.Email, &slug, jsonColumn{&user.Author}, &user.Deleted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// FindByUsername look up user by his username
+func (u UserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return u.findOne(ctx, "username = ? AND deleted = ?", username, false)
+}
+
+// FindByEmail look up user by his email
+func (u UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return u.findOne(ctx, "email = ? AND deleted = ?", email, false)
+}
+
+// FindByAuthorSlug look up user by slug of his author profile
+func (u UserRepository) FindByAuthorSlug(ctx context.Context, slug string) (*models.User, error) {
+	return u.findOne(ctx, "author_slug = ? AND deleted = ?", slug, false)
+}
+
+// UpdateAuthor replaces author profile of user
+func (u UserRepository) UpdateAuthor(ctx context.Context, username string, author *models.Author) error {
+	if err := author.Validate(); err != nil {
+		return err
+	}
+
+	// Slug of another author is taken
+	fusr, _ := u.FindByAuthorSlug(ctx, author.Slug)
+	if fusr != nil && fusr.Username != username {
+		return helpers.ErrAuthorAlreadyExist
+	}
+
+	res, err := u.store.exec(ctx, "UPDATE users SET author_slug = ?, author = ? WHERE username = ? AND deleted = ?",
+		author.Slug, jsonColumn{author}, username, false)
+	if err != nil {
+		return u.store.duplicateErr(err, "author_slug", helpers.ErrAuthorAlreadyExist)
+	}
+
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		return store.ErrNotFound
+	}
+
+	return err
+}
+
+// authorSlug returns slug of author profile or empty string when there is no profile
+func authorSlug(author *models.Author) string {
+	if author == nil {
+		return ""
+	}
+
+	return author.Slug
+}
+
+// Delete marks user as deleted
+func (u UserRepository) Delete(ctx context.Context, deletedID primitive.ObjectID) error {
+	_, err := u.store.exec(ctx, "UPDATE users SET deleted = ? WHERE id = ?", true, deletedID.Hex())
+
+	return err
+}
+
+// Login checks user credentials and returns new token
+func (u UserRepository) Login(ctx context.Context, username, password, secret string) (string, time.Time, error) {
+	fusr, err := u.FindByUsername(ctx, username)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	fusr.Password = password
+
+	token, expTime, err := fusr.DoLogin(secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expTime, nil
+}
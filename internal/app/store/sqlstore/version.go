@@ -0,0 +1,64 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// updateVersion runs versioned UPDATE statement of table with version appended to args
+// Version is incremented only when row was updated
+func (s *SQLStore) updateVersion(ctx context.Context, t table, query string, args []interface{}, ID primitive.ObjectID, version *int64) error {
+	res, err := s.exec(ctx, query, append(args, *version)...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return s.versionConflict(ctx, t, ID)
+	}
+
+	*version++
+
+	return nil
+}
+
+// versionConflict tells whether update changed nothing because of missing row or stale version
+func (s *SQLStore) versionConflict(ctx context.Context, t table, ID primitive.ObjectID) error {
+	var n int64
+
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM "+t.name+" WHERE id = ?", []interface{}{ID.Hex()}, func(sc scanner) error {
+		return sc.Scan(&n)
+	})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	return store.ErrVersionConflict
+}
+
+// patchVersion updates only columns of given fields with values taken from args of full row
+// args are values of all columns in order of t.columns, version is incremented only when row was updated
+func (s *SQLStore) patchVersion(ctx context.Context, t table, args []interface{}, fields []string, ID primitive.ObjectID, version *int64) error {
+	query, positions, err := t.patch(fields)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0, len(positions)+1)
+	for _, pos := range positions {
+		values = append(values, args[pos])
+	}
+
+	return s.updateVersion(ctx, t, query, append(values, ID.Hex()), ID, version)
+}
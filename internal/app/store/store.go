@@ -1,7 +1,13 @@
 package store
 
+import (
+	"context"
+	"time"
+)
+
 // Storer defines interface for app's stores
 type Storer interface {
+	Close(context.Context) error
 	Posts() IPostRepository
 	Categories() ICategoryRepository
 	Materials() IMaterialRepository
@@ -9,4 +15,26 @@ type Storer interface {
 	Users() IUserRepository
 	Services() IServiceRepository
 	Pages() IPageRepository
+	Tags() ITagRepository
+	Revisions() IRevisionRepository
+	Redirects() IRedirectRepository
+	// WithTransaction runs fn so that changes made with ctx passed to it are applied all or nothing
+	// Stores without transaction support run fn as is, nested calls join outer transaction
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Timeouts limits duration of each class of store operations
+// Deadline of the incoming context is kept when it is shorter
+type Timeouts struct {
+	Read      time.Duration
+	Write     time.Duration
+	Aggregate time.Duration
+}
+
+// Migrator is implemented by stores which manage their schema
+type Migrator interface {
+	// EnsureIndexes creates indexes required by repositories
+	EnsureIndexes(context.Context) error
+	// Migrate applies pending migrations and returns descriptions of applied ones
+	Migrate(context.Context) ([]string, error)
 }
@@ -0,0 +1,117 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testDeleteCategory(t *testing.T, newStore NewStore) {
+	testCases := []struct {
+		name       string
+		policy     store.DeletePolicy
+		reassign   bool
+		wantErr    error
+		wantLive   int64 // Live posts of deleted category
+		wantMoved  int64 // Live posts of other category
+		wantExists bool  // Category is still live
+	}{
+		{name: "Restrict by default", policy: "", wantErr: helpers.ErrCategoryNotEmpty, wantLive: 2, wantExists: true},
+		{name: "Restrict", policy: store.DeleteRestrict, wantErr: helpers.ErrCategoryNotEmpty, wantLive: 2, wantExists: true},
+		{name: "Reassign", policy: store.DeleteReassign, reassign: true, wantMoved: 2},
+		{name: "Reassign without target", policy: store.DeleteReassign, wantErr: helpers.ErrNoReassignTarget, wantLive: 2, wantExists: true},
+		{name: "Cascade", policy: store.DeleteCascade},
+		{name: "Unknown policy", policy: "orphan", wantErr: helpers.ErrUnknownDeletePolicy, wantLive: 2, wantExists: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newStore(t)
+
+			cat, other := Category("Налоги и отчетность"), Category("Бухгалтерия")
+			assert.NoError(t, s.Categories().Create(ctx, cat))
+			assert.NoError(t, s.Categories().Create(ctx, other))
+			assert.NoError(t, s.Posts().Create(ctx, Post("Первая запись", cat.ID)))
+			assert.NoError(t, s.Posts().Create(ctx, Post("Вторая запись", cat.ID)))
+
+			var reassignTo primitive.ObjectID
+			if tc.reassign {
+				reassignTo = other.ID
+			}
+
+			err := store.DeleteCategory(ctx, s, cat.ID, "editor", tc.policy, reassignTo)
+			assert.Equal(t, tc.wantErr, err)
+
+			live, err := s.Posts().Count(ctx, store.ListQuery{CategoryID: cat.ID})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantLive, live)
+
+			moved, err := s.Posts().Count(ctx, store.ListQuery{CategoryID: other.ID})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantMoved, moved)
+
+			_, err = s.Categories().FindByID(ctx, cat.ID)
+			assert.Equal(t, tc.wantExists, err == nil)
+		})
+	}
+}
+
+func testDeleteCategoryNotFound(t *testing.T, newStore NewStore) {
+	s := newStore(t)
+
+	err := store.DeleteCategory(context.Background(), s, primitive.NewObjectID(), "editor", store.DeleteCascade, primitive.NilObjectID)
+	assert.Equal(t, store.ErrNotFound, err)
+}
+
+func testDeleteMatCategoryCascade(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	matcat := MatCategory("Бухгалтерия", "buhgalteriya")
+	assert.NoError(t, s.MatCategories().Create(ctx, matcat))
+	assert.NoError(t, s.Materials().Create(ctx, Material("Первый материал", matcat.ID)))
+
+	assert.Equal(t, helpers.ErrMatCategoryNotEmpty, store.DeleteMatCategory(ctx, s, matcat.ID, "editor", store.DeleteRestrict, primitive.NilObjectID))
+	assert.NoError(t, store.DeleteMatCategory(ctx, s, matcat.ID, "editor", store.DeleteCascade, primitive.NilObjectID))
+
+	trash, err := s.Materials().List(ctx, store.ListQuery{CategoryID: matcat.ID, Deleted: store.OnlyDeleted})
+	assert.NoError(t, err)
+	if assert.Len(t, trash, 1) {
+		assert.Equal(t, "editor", trash[0].DeletedBy)
+	}
+}
+
+func testWithTransactionRollback(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	cat := Category("Налоги и отчетность")
+	errFailed := errors.New("failed")
+
+	err := s.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.Categories().Create(ctx, cat); err != nil {
+			return err
+		}
+
+		// Nested call joins outer transaction
+		return s.WithTransaction(ctx, func(ctx context.Context) error {
+			return errFailed
+		})
+	})
+	assert.Equal(t, errFailed, err)
+
+	_, err = s.Categories().FindByID(ctx, cat.ID)
+	assert.Equal(t, store.ErrNotFound, err)
+
+	assert.NoError(t, s.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.Categories().Create(ctx, cat)
+	}))
+
+	_, err = s.Categories().FindByID(ctx, cat.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,60 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testPostPatch(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	patched := *post
+	patched.Title = "Новый заголовок"
+	patched.PageData = nil
+	patched.MetaDesc = "Это описание не указано в списке полей и не должно сохраниться"
+
+	assert.NoError(t, s.Posts().Patch(ctx, &patched, []string{"pagedata", "title"}))
+	assert.Equal(t, int64(1), patched.Version)
+
+	found, err := s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Новый заголовок", found.Title)
+	assert.Empty(t, found.PageData)
+	assert.Equal(t, post.MetaDesc, found.MetaDesc)
+	assert.Equal(t, int64(1), found.Version)
+
+	// Patch of stale version is rejected
+	assert.Equal(t, store.ErrVersionConflict, s.Posts().Patch(ctx, post, []string{"title"}))
+	assert.Equal(t, int64(0), post.Version)
+
+	missing := Post("Несуществующая запись", post.CategoryID)
+	assert.Equal(t, store.ErrNotFound, s.Posts().Patch(ctx, missing, []string{"title"}))
+
+	invalid := *found
+	invalid.Title = ""
+	assert.Error(t, s.Posts().Patch(ctx, &invalid, []string{"title"}))
+}
+
+func testPagePatch(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	page := Page("/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+
+	page.MetaDesc = "Новое описание страницы о компании для поисковых систем"
+	assert.NoError(t, s.Pages().Patch(ctx, page, []string{"desc"}))
+
+	found, err := s.Pages().FindByID(ctx, page.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Новое описание страницы о компании для поисковых систем", found.MetaDesc)
+	assert.Equal(t, int64(1), found.Version)
+}
@@ -0,0 +1,433 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testPostRepository(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+
+	assert.NoError(t, s.Posts().Create(ctx, post))
+	assert.Equal(t, helpers.ErrPostAlreadyExist, s.Posts().Create(ctx, Post("Первая запись", post.CategoryID)))
+
+	found, err := s.Posts().FindBySlug(ctx, post.Slug)
+	assert.NoError(t, err)
+	assert.Equal(t, post.ID, found.ID)
+	assert.Equal(t, post.CategoryID, found.CategoryID)
+	assert.Equal(t, post.PageData, found.PageData)
+	assert.WithinDuration(t, post.Time, found.Time, time.Millisecond)
+
+	found.Title = "Обновленная запись"
+	assert.NoError(t, s.Posts().Update(ctx, found))
+
+	found, err = s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Обновленная запись", found.Title)
+
+	count, err := s.Posts().Count(ctx, store.ListQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, s.Posts().Delete(ctx, post.ID, "editor"))
+
+	_, err = s.Posts().FindByID(ctx, post.ID)
+	assert.Equal(t, store.ErrNotFound, err)
+
+	// Soft deleted post is still stored
+	all, err := s.Posts().List(ctx, store.ListQuery{Deleted: store.AnyDeleted})
+	assert.NoError(t, err)
+	if assert.Len(t, all, 1) {
+		assert.True(t, all[0].Deleted)
+	}
+
+	// Slug of deleted post may be reused
+	assert.NoError(t, s.Posts().Create(ctx, Post("Первая запись", post.CategoryID)))
+}
+
+func testPostUpdateDuplicateSlug(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	catID := primitive.NewObjectID()
+
+	first := Post("Первая запись", catID)
+	second := Post("Вторая запись", catID)
+	assert.NoError(t, s.Posts().Create(ctx, first))
+	assert.NoError(t, s.Posts().Create(ctx, second))
+
+	second.Slug = first.Slug
+	assert.Equal(t, helpers.ErrPostAlreadyExist, s.Posts().Update(ctx, second))
+}
+
+func testPostUpdateValidation(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	post.Title = ""
+	assert.Error(t, s.Posts().Update(ctx, post))
+
+	found, err := s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Первая запись", found.Title)
+}
+
+func testPostList(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	catID := primitive.NewObjectID()
+	base := time.Now()
+
+	for i, title := range []string{"Первая запись", "Вторая запись", "Третья запись"} {
+		post := Post(title, catID)
+		post.Time = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	testCases := []struct {
+		name  string
+		query store.ListQuery
+		want  []string
+	}{
+		{
+			name:  "Default order",
+			query: store.ListQuery{},
+			want:  []string{"Третья запись", "Вторая запись", "Первая запись"},
+		},
+		{
+			name:  "Offset and limit",
+			query: store.ListQuery{Offset: 1, Limit: 1},
+			want:  []string{"Вторая запись"},
+		},
+		{
+			name:  "Offset without limit",
+			query: store.ListQuery{Offset: 2},
+			want:  []string{"Первая запись"},
+		},
+		{
+			name:  "Oldest first",
+			query: store.ListQuery{Sort: store.SortOldest, Limit: 2},
+			want:  []string{"Первая запись", "Вторая запись"},
+		},
+		{
+			name:  "By title",
+			query: store.ListQuery{Sort: store.SortTitle},
+			want:  []string{"Вторая запись", "Первая запись", "Третья запись"},
+		},
+		{
+			name:  "Date range",
+			query: store.ListQuery{From: base.Add(30 * time.Minute), To: base.Add(90 * time.Minute)},
+			want:  []string{"Вторая запись"},
+		},
+		{
+			name:  "Text",
+			query: store.ListQuery{Text: "ТРЕТЬЯ"},
+			want:  []string{"Третья запись"},
+		},
+		{
+			name:  "Text is not regexp",
+			query: store.ListQuery{Text: ".*"},
+			want:  []string{},
+		},
+		{
+			name:  "Text is not pattern",
+			query: store.ListQuery{Text: "%"},
+			want:  []string{},
+		},
+		{
+			name:  "Other category",
+			query: store.ListQuery{CategoryID: primitive.NewObjectID()},
+			want:  []string{},
+		},
+		{
+			name:  "Only deleted",
+			query: store.ListQuery{Deleted: store.OnlyDeleted},
+			want:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			posts, err := s.Posts().List(ctx, tc.query)
+			assert.NoError(t, err)
+
+			titles := make([]string, 0)
+			for _, p := range posts {
+				titles = append(titles, p.Title)
+			}
+
+			assert.Equal(t, tc.want, titles)
+		})
+	}
+
+	// Count ignores pagination
+	count, err := s.Posts().Count(ctx, store.ListQuery{CategoryID: catID, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func testPostListPublishedWithCategory(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	cat := Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, cat))
+
+	base := time.Now()
+	for i, title := range []string{"Первая запись", "Вторая запись", "Третья запись", "Четвертая запись"} {
+		post := Post(title, cat.ID)
+		post.Time = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	// Post without category is skipped
+	assert.NoError(t, s.Posts().Create(ctx, Post("Запись без категории", primitive.NewObjectID())))
+
+	posts, err := s.Posts().ListPublishedWithCategory(ctx, store.ListQuery{Offset: 1, Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 2) {
+		assert.Equal(t, "Третья запись", posts[0].Title)
+		assert.Equal(t, "Вторая запись", posts[1].Title)
+		assert.Equal(t, cat.Slug, posts[0].CategorySlug)
+		assert.Equal(t, "/category/"+cat.Slug+"/"+posts[0].Slug, posts[0].GetURL())
+		assert.Empty(t, posts[0].MetaDesc)
+	}
+}
+
+func testPostListPublishedWithTotal(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	cat := Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, cat))
+
+	base := time.Now()
+	for i, title := range []string{"Первая запись", "Вторая запись", "Третья запись", "Четвертая запись"} {
+		post := Post(title, cat.ID)
+		post.Time = base.Add(time.Duration(i) * time.Hour)
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	// Deleted post is neither listed nor counted
+	deleted := Post("Удаленная запись", cat.ID)
+	assert.NoError(t, s.Posts().Create(ctx, deleted))
+	assert.NoError(t, s.Posts().Delete(ctx, deleted.ID, "editor"))
+
+	// Newest posts of missing and trashed categories have no URL, so they are neither listed nor counted
+	trashed := Category("Удаленная категория")
+	assert.NoError(t, s.Categories().Create(ctx, trashed))
+
+	for title, catID := range map[string]primitive.ObjectID{"Запись без категории": primitive.NewObjectID(), "Запись удаленной категории": trashed.ID} {
+		post := Post(title, catID)
+		post.Time = base.Add(24 * time.Hour)
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	assert.NoError(t, s.Categories().Delete(ctx, trashed.ID, "editor"))
+
+	testCases := []struct {
+		name  string
+		query store.ListQuery
+		want  []string
+		total int64
+	}{
+		{
+			name:  "First page",
+			query: store.ListQuery{Limit: 2},
+			want:  []string{"Четвертая запись", "Третья запись"},
+			total: 4,
+		},
+		{
+			name:  "Page",
+			query: store.ListQuery{Offset: 1, Limit: 2},
+			want:  []string{"Третья запись", "Вторая запись"},
+			total: 4,
+		},
+		{
+			name:  "Page out of range",
+			query: store.ListQuery{Offset: 10, Limit: 2},
+			want:  []string{},
+			total: 4,
+		},
+		{
+			name:  "Empty category",
+			query: store.ListQuery{CategoryID: primitive.NewObjectID(), Limit: 2},
+			want:  []string{},
+			total: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listing, err := s.Posts().ListPublishedWithTotal(ctx, tc.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.total, listing.Total)
+
+			titles := make([]string, 0)
+			for _, p := range listing.Posts {
+				titles = append(titles, p.Title)
+			}
+			assert.Equal(t, tc.want, titles)
+
+			if len(listing.Posts) > 0 {
+				assert.Equal(t, cat.Slug, listing.Posts[0].CategorySlug)
+				assert.Equal(t, cat.Title, listing.Posts[0].CategoryTitle)
+			}
+		})
+	}
+}
+
+func testPostListByStatus(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	now := time.Now()
+
+	category := Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, category))
+
+	published := Post("Опубликованная запись", category.ID)
+	expiring := Post("Запись до конца месяца", category.ID)
+	expiring.UnpublishAt = now.Add(-time.Minute)
+	draft := Post("Черновик записи", category.ID)
+	draft.Status = models.StatusDraft
+	scheduled := Post("Запись к сроку сдачи отчетности", category.ID)
+	scheduled.Status = models.StatusScheduled
+	scheduled.PublishAt = now.Add(time.Hour)
+	scheduled.Time = scheduled.PublishAt
+
+	for _, post := range []*models.Post{published, expiring, draft, scheduled} {
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	// Public listings contain only published posts
+	listing, err := s.Posts().ListPublishedWithTotal(ctx, store.ListQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), listing.Total)
+
+	posts, err := s.Posts().List(ctx, store.ListQuery{Status: models.StatusScheduled, To: now.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 1) {
+		assert.Equal(t, scheduled.ID, posts[0].ID)
+		assert.WithinDuration(t, scheduled.PublishAt, posts[0].PublishAt, time.Millisecond)
+		assert.True(t, posts[0].UnpublishAt.IsZero())
+	}
+
+	posts, err = s.Posts().List(ctx, store.ListQuery{Status: models.StatusScheduled, To: now})
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
+
+	// Posts without unpublish_at are never due
+	posts, err = s.Posts().List(ctx, store.ListQuery{Status: models.StatusPublished, UnpublishBefore: now})
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 1) {
+		assert.Equal(t, expiring.ID, posts[0].ID)
+	}
+}
+
+func testPostListByAuthor(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	category := Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, category))
+
+	first := Post("Первая запись автора", category.ID)
+	first.Author = "first_editor"
+	draft := Post("Черновик записи автора", category.ID)
+	draft.Author, draft.Status = "first_editor", models.StatusDraft
+	other := Post("Запись другого автора", category.ID)
+	other.Author = "second_editor"
+
+	for _, post := range []*models.Post{first, draft, other} {
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	posts, err := s.Posts().List(ctx, store.ListQuery{Author: "first_editor"})
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+
+	listing, err := s.Posts().ListPublishedWithTotal(ctx, store.ListQuery{Author: "first_editor"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), listing.Total)
+	if assert.Len(t, listing.Posts, 1) {
+		assert.Equal(t, first.ID, listing.Posts[0].ID)
+	}
+
+	found, err := s.Posts().FindByID(ctx, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "first_editor", found.Author)
+}
+
+func testPostReadingAndAnchors(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	post := Post("Запись с оглавлением", primitive.NewObjectID())
+	post.PageData = append([]models.Block{
+		{Type: "header", Data: &models.BlockData{Text: "Налоговый вычет", Level: 2, Anchor: "nalogovyy_vychet"}},
+	}, post.PageData...)
+	post.CountReading()
+
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	found, err := s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, found.WordCount)
+	assert.Equal(t, 1, found.ReadingTime)
+	assert.Equal(t, "nalogovyy_vychet", found.PageData[0].Data.Anchor)
+
+	found.PageData = found.PageData[1:]
+	found.CountReading()
+	assert.NoError(t, s.Posts().Patch(ctx, found, []string{"pagedata", "word_count", "reading_time"}))
+
+	found, err = s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.WordCount)
+}
+
+func testPostCanceledContext(t *testing.T, newStore NewStore) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newStore(t)
+
+	cancel()
+
+	assert.ErrorIs(t, s.Posts().Create(ctx, Post("Первая запись", primitive.NewObjectID())), context.Canceled)
+
+	_, err := s.Posts().List(context.Background(), store.ListQuery{})
+	assert.NoError(t, err)
+
+	_, err = s.Posts().List(ctx, store.ListQuery{Deleted: store.AnyDeleted})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func testMatCatListWithMaterials(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	matcat := MatCategory("Бухгалтерия", "buhgalteriya")
+	assert.NoError(t, s.MatCategories().Create(ctx, matcat))
+	assert.NoError(t, s.MatCategories().Create(ctx, MatCategory("Налоги", "nalogi")))
+
+	for i := 0; i < 5; i++ {
+		material := Material("Материал номер "+string(rune('A'+i)), matcat.ID)
+		material.Time = time.Now().Add(time.Duration(i) * time.Minute)
+		assert.NoError(t, s.Materials().Create(ctx, material))
+	}
+
+	mats, err := s.MatCategories().ListWithMaterials(ctx, store.ListQuery{}, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, mats, 2) {
+		assert.Len(t, mats[0].Materials, 3)
+		assert.Equal(t, "Материал номер E", mats[0].Materials[0].Title)
+		assert.Empty(t, mats[1].Materials)
+	}
+}
@@ -0,0 +1,34 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testRedirectRepository(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	first := models.NewRedirect(models.RedirectPost, primitive.NewObjectID(), "staraya_zapis")
+	first.Time = time.Now().Add(-time.Hour)
+	assert.NoError(t, s.Redirects().Create(ctx, first))
+
+	// Slug was reused and renamed by another post later
+	second := models.NewRedirect(models.RedirectPost, primitive.NewObjectID(), "staraya_zapis")
+	assert.NoError(t, s.Redirects().Create(ctx, second))
+
+	found, err := s.Redirects().FindBySlug(ctx, models.RedirectPost, "staraya_zapis")
+	assert.NoError(t, err)
+	assert.Equal(t, second.DocID, found.DocID)
+
+	_, err = s.Redirects().FindBySlug(ctx, models.RedirectCategory, "staraya_zapis")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	assert.Error(t, s.Redirects().Create(ctx, models.NewRedirect("material", primitive.NewObjectID(), "slug")))
+}
@@ -0,0 +1,64 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testRevisionRepository(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+
+	first := models.NewPostRevision(post, "editor", "")
+	first.Time = time.Now().Add(-time.Hour)
+	assert.NoError(t, s.Revisions().Create(ctx, first))
+
+	post.Title = "Исправленная запись"
+	second := models.NewPostRevision(post, "admin", "Новый заголовок")
+	assert.NoError(t, s.Revisions().Create(ctx, second))
+
+	// Revisions of other documents are not listed
+	assert.NoError(t, s.Revisions().Create(ctx, models.NewPageRevision(Page("about"), "editor", "")))
+
+	found, err := s.Revisions().FindByID(ctx, first.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found.Post) {
+		assert.Equal(t, "Первая запись", found.Post.Title)
+		assert.Equal(t, post.PageData, found.Post.PageData)
+	}
+	assert.Nil(t, found.Page)
+
+	_, err = s.Revisions().FindByID(ctx, primitive.NewObjectID())
+	assert.Equal(t, store.ErrNotFound, err)
+
+	revs, err := s.Revisions().ListByDocument(ctx, post.ID, store.ListQuery{})
+	assert.NoError(t, err)
+	if assert.Len(t, revs, 2) {
+		assert.Equal(t, second.ID, revs[0].ID)
+		assert.Equal(t, "admin", revs[0].Author)
+		assert.Equal(t, "Новый заголовок", revs[0].Comment)
+		assert.Nil(t, revs[0].Post)
+		assert.Equal(t, first.ID, revs[1].ID)
+	}
+
+	revs, err = s.Revisions().ListByDocument(ctx, post.ID, store.ListQuery{Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, revs, 1) {
+		assert.Equal(t, first.ID, revs[0].ID)
+	}
+}
+
+func testRevisionCreateValidation(t *testing.T, newStore NewStore) {
+	s := newStore(t)
+	rev := models.NewPostRevision(Post("Первая запись", primitive.NewObjectID()), "editor", "")
+	rev.Post = nil
+
+	assert.Error(t, s.Revisions().Create(context.Background(), rev))
+}
@@ -0,0 +1,133 @@
+// Package storetest contains conformance tests which every store.Storer backend must pass
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/slug"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewStore returns empty store for a single test, backend is responsible for its cleanup
+type NewStore func(t *testing.T) store.Storer
+
+// Run runs the whole suite against stores returned by newStore
+func Run(t *testing.T, newStore NewStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, newStore NewStore)
+	}{
+		{name: "PostRepository", fn: testPostRepository},
+		{name: "PostRepository_UpdateDuplicateSlug", fn: testPostUpdateDuplicateSlug},
+		{name: "PostRepository_UpdateValidation", fn: testPostUpdateValidation},
+		{name: "PostRepository_List", fn: testPostList},
+		{name: "PostRepository_ListPublishedWithCategory", fn: testPostListPublishedWithCategory},
+		{name: "PostRepository_ListPublishedWithTotal", fn: testPostListPublishedWithTotal},
+		{name: "PostRepository_ListByStatus", fn: testPostListByStatus},
+		{name: "PostRepository_ListByAuthor", fn: testPostListByAuthor},
+		{name: "PostRepository_ListByTag", fn: testPostListByTag},
+		{name: "PostRepository_ReadingAndAnchors", fn: testPostReadingAndAnchors},
+		{name: "PostRepository_CanceledContext", fn: testPostCanceledContext},
+		{name: "PostRepository_Patch", fn: testPostPatch},
+		{name: "PostRepository_UpdateVersion", fn: testPostUpdateVersion},
+		{name: "PostRepository_ReassignCategoryVersion", fn: testPostReassignCategoryVersion},
+		{name: "PostRepository_Trash", fn: testPostTrash},
+		{name: "PostRepository_PurgeDeletedBefore", fn: testPostPurgeDeletedBefore},
+		{name: "PageRepository_Patch", fn: testPagePatch},
+		{name: "PageRepository_UpdateVersion", fn: testPageUpdateVersion},
+		{name: "PageRepository_Trash", fn: testPageTrash},
+		{name: "MatCatRepository_ListWithMaterials", fn: testMatCatListWithMaterials},
+		{name: "TagRepository", fn: testTagRepository},
+		{name: "RevisionRepository", fn: testRevisionRepository},
+		{name: "RevisionRepository_CreateValidation", fn: testRevisionCreateValidation},
+		{name: "RedirectRepository", fn: testRedirectRepository},
+		{name: "UserRepository_UpdateAuthor", fn: testUserUpdateAuthor},
+		{name: "DeleteCategory", fn: testDeleteCategory},
+		{name: "DeleteCategory_NotFound", fn: testDeleteCategoryNotFound},
+		{name: "DeleteMatCategory_Cascade", fn: testDeleteMatCategoryCascade},
+		{name: "WithTransaction_Rollback", fn: testWithTransactionRollback},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newStore)
+		})
+	}
+}
+
+// Category returns valid category with slug made from title
+func Category(title string) *models.Category {
+	return &models.Category{
+		ID:       primitive.NewObjectID(),
+		Title:    title,
+		Subtitle: "Подзаголовок категории достаточной длины",
+		Slug:     slug.Make(title, slug.Legacy, 0),
+		MetaDesc: "Описание категории для поисковых систем достаточной длины",
+	}
+}
+
+// Post returns valid published post of category catID with slug made from title
+func Post(title string, catID primitive.ObjectID) *models.Post {
+	return &models.Post{
+		ID:         primitive.NewObjectID(),
+		Title:      title,
+		Snippet:    "Короткое описание записи, которое показывается в карточке",
+		Slug:       slug.Make(title, slug.Legacy, 0),
+		CategoryID: catID,
+		Time:       time.Now(),
+		MetaDesc:   "Описание записи для поисковых систем достаточной длины",
+		PostImg:    "/uploads/images/post.jpg",
+		Status:     models.StatusPublished,
+		PageData: []models.Block{
+			{Type: "paragraph", Data: &models.BlockData{Text: "Текст записи"}},
+		},
+	}
+}
+
+// Page returns valid published page served at url
+func Page(url string) *models.Page {
+	return &models.Page{
+		ID:       primitive.NewObjectID(),
+		Title:    "О компании",
+		Subtitle: "Подзаголовок страницы достаточной длины для валидации",
+		MetaDesc: "Описание страницы для поисковых систем достаточной длины",
+		URL:      url,
+		Status:   models.StatusPublished,
+	}
+}
+
+// MatCategory returns valid category of materials
+func MatCategory(title, slug string) *models.MatCategory {
+	return &models.MatCategory{
+		ID:    primitive.NewObjectID(),
+		Title: title,
+		Slug:  slug,
+		Desc:  "Документы и шаблоны для ведения бухгалтерского учета организации",
+	}
+}
+
+// Material returns valid material of category matcatID with slug made from title
+func Material(title string, matcatID primitive.ObjectID) *models.Material {
+	return &models.Material{
+		ID:            primitive.NewObjectID(),
+		Title:         title,
+		MatCategoryID: matcatID,
+		Slug:          slug.Make(title, slug.Legacy, 0),
+		Desc:          "Описание материала, которое достаточно длинное для валидации",
+		Time:          time.Now(),
+		FileLink:      "/uploads/documents/file.pdf",
+	}
+}
+
+// Tag returns valid tag with slug made from title
+func Tag(title string) *models.Tag {
+	return &models.Tag{
+		ID:    primitive.NewObjectID(),
+		Title: title,
+		Slug:  slug.Make(title, slug.Legacy, 0),
+	}
+}
@@ -0,0 +1,90 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testTagRepository(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	vat, reports, sole := Tag("НДС"), Tag("Отчетность"), Tag("ИП")
+	for _, tag := range []*models.Tag{vat, reports, sole} {
+		assert.NoError(t, s.Tags().Create(ctx, tag))
+	}
+	assert.Equal(t, helpers.ErrTagAlreadyExist, s.Tags().Create(ctx, Tag("НДС")))
+
+	assert.NoError(t, s.Tags().Delete(ctx, sole.ID, "admin"))
+
+	// Deleted and unknown tags are skipped
+	tags, err := s.Tags().ListByIDs(ctx, []primitive.ObjectID{reports.ID, sole.ID, vat.ID, primitive.NewObjectID()})
+	assert.NoError(t, err)
+	if assert.Len(t, tags, 2) {
+		assert.Equal(t, vat.ID, tags[0].ID)
+		assert.Equal(t, reports.ID, tags[1].ID)
+	}
+
+	tags, err = s.Tags().ListByIDs(ctx, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, tags)
+	assert.Empty(t, tags)
+
+	assert.NoError(t, s.Tags().Restore(ctx, sole.ID))
+
+	found, err := s.Tags().FindBySlug(ctx, sole.Slug)
+	assert.NoError(t, err)
+	assert.Equal(t, sole.ID, found.ID)
+}
+
+func testPostListByTag(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	category := Category("Налоги и отчетность")
+	assert.NoError(t, s.Categories().Create(ctx, category))
+
+	vat, reports := primitive.NewObjectID(), primitive.NewObjectID()
+
+	both := Post("НДС в отчетности ИП", category.ID)
+	both.TagIDs = []primitive.ObjectID{vat, reports}
+	onlyVat := Post("Вычеты по НДС", category.ID)
+	onlyVat.TagIDs = []primitive.ObjectID{vat}
+	untagged := Post("Новости месяца", category.ID)
+
+	for _, post := range []*models.Post{both, onlyVat, untagged} {
+		assert.NoError(t, s.Posts().Create(ctx, post))
+	}
+
+	posts, err := s.Posts().List(ctx, store.ListQuery{TagID: vat})
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+
+	listing, err := s.Posts().ListPublishedWithTotal(ctx, store.ListQuery{TagID: reports})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), listing.Total)
+	if assert.Len(t, listing.Posts, 1) {
+		assert.Equal(t, both.ID, listing.Posts[0].ID)
+	}
+
+	found, err := s.Posts().FindByID(ctx, both.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, both.TagIDs, found.TagIDs)
+
+	material := Material("Декларация по НДС", primitive.NewObjectID())
+	material.TagIDs = []primitive.ObjectID{vat}
+	assert.NoError(t, s.Materials().Create(ctx, material))
+	assert.NoError(t, s.Materials().Create(ctx, Material("Шаблон договора", material.MatCategoryID)))
+
+	materials, err := s.Materials().List(ctx, store.ListQuery{TagID: vat})
+	assert.NoError(t, err)
+	if assert.Len(t, materials, 1) {
+		assert.Equal(t, material.ID, materials[0].ID)
+	}
+}
@@ -0,0 +1,94 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testPostTrash(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	// Only deleted post may be restored or purged
+	assert.Equal(t, store.ErrNotFound, s.Posts().Restore(ctx, post.ID))
+	assert.Equal(t, store.ErrNotFound, s.Posts().Purge(ctx, post.ID))
+
+	assert.NoError(t, s.Posts().Delete(ctx, post.ID, "editor"))
+
+	trash, err := s.Posts().List(ctx, store.ListQuery{Deleted: store.OnlyDeleted})
+	assert.NoError(t, err)
+	if assert.Len(t, trash, 1) {
+		assert.Equal(t, "editor", trash[0].DeletedBy)
+		assert.WithinDuration(t, time.Now(), trash[0].DeletedAt, time.Second)
+	}
+
+	assert.NoError(t, s.Posts().Restore(ctx, post.ID))
+
+	found, err := s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, found.DeletedBy)
+	assert.True(t, found.DeletedAt.IsZero())
+
+	// Slug was taken while post was in trash
+	assert.NoError(t, s.Posts().Delete(ctx, post.ID, "editor"))
+	assert.NoError(t, s.Posts().Create(ctx, Post("Первая запись", post.CategoryID)))
+	assert.Equal(t, helpers.ErrPostAlreadyExist, s.Posts().Restore(ctx, post.ID))
+
+	assert.NoError(t, s.Posts().Purge(ctx, post.ID))
+	assert.Equal(t, store.ErrNotFound, s.Posts().Purge(ctx, post.ID))
+
+	count, err := s.Posts().Count(ctx, store.ListQuery{Deleted: store.AnyDeleted})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func testPostPurgeDeletedBefore(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	catID := primitive.NewObjectID()
+
+	expired := Post("Первая запись", catID)
+	live := Post("Вторая запись", catID)
+	assert.NoError(t, s.Posts().Create(ctx, expired))
+	assert.NoError(t, s.Posts().Create(ctx, live))
+	assert.NoError(t, s.Posts().Delete(ctx, expired.ID, "editor"))
+
+	n, err := s.Posts().PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	n, err = s.Posts().PurgeDeletedBefore(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	all, err := s.Posts().List(ctx, store.ListQuery{Deleted: store.AnyDeleted})
+	assert.NoError(t, err)
+	if assert.Len(t, all, 1) {
+		assert.Equal(t, live.ID, all[0].ID)
+	}
+}
+
+func testPageTrash(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	page := Page("/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+	assert.NoError(t, s.Pages().Delete(ctx, page.ID, "editor"))
+
+	_, err := s.Pages().FindByURL(ctx, page.URL)
+	assert.Equal(t, store.ErrNotFound, err)
+
+	// URL of deleted page may be reused
+	assert.NoError(t, s.Pages().Create(ctx, Page("/about")))
+	assert.Equal(t, helpers.ErrPageAlreadyExist, s.Pages().Restore(ctx, page.ID))
+}
@@ -0,0 +1,45 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/helpers"
+	"github.com/the-NZA/acg-nikolaev/internal/app/models"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testUserUpdateAuthor(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	// Password hashing is slow, so only two users are created
+	for _, username := range []string{"first_editor", "second_editor"} {
+		assert.NoError(t, s.Users().Create(ctx, &models.User{
+			ID:       primitive.NewObjectID(),
+			Username: username,
+			Password: "password_of_" + username,
+			Email:    username + "@acg-nikolaev.ru",
+		}))
+	}
+
+	_, err := s.Users().FindByAuthorSlug(ctx, "ivan-petrov")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	author := &models.Author{Slug: "ivan-petrov", Name: "Иван Петров", Position: "Аттестованный аудитор"}
+	assert.NoError(t, s.Users().UpdateAuthor(ctx, "first_editor", author))
+
+	found, err := s.Users().FindByAuthorSlug(ctx, "ivan-petrov")
+	assert.NoError(t, err)
+	assert.Equal(t, "first_editor", found.Username)
+	assert.Equal(t, author, found.Author)
+
+	// Author may save own profile again, but not take slug of another one
+	assert.NoError(t, s.Users().UpdateAuthor(ctx, "first_editor", author))
+	assert.Equal(t, helpers.ErrAuthorAlreadyExist, s.Users().UpdateAuthor(ctx, "second_editor", author))
+
+	assert.Error(t, s.Users().UpdateAuthor(ctx, "first_editor", &models.Author{Slug: "ivan-petrov"}))
+	assert.Equal(t, store.ErrNotFound, s.Users().UpdateAuthor(ctx, "unknown_editor", &models.Author{Slug: "anna", Name: "Анна"}))
+}
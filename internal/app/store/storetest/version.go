@@ -0,0 +1,70 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/the-NZA/acg-nikolaev/internal/app/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testPostUpdateVersion(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	first, err := s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	second := *first
+
+	first.Title = "Правка первого редактора"
+	assert.NoError(t, s.Posts().Update(ctx, first))
+	assert.Equal(t, int64(1), first.Version)
+
+	// Second editor saves post read before the first update
+	second.Title = "Правка второго редактора"
+	assert.Equal(t, store.ErrVersionConflict, s.Posts().Update(ctx, &second))
+	assert.Equal(t, int64(0), second.Version)
+
+	found, err := s.Posts().FindByID(ctx, post.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Правка первого редактора", found.Title)
+	assert.Equal(t, int64(1), found.Version)
+
+	missing := Post("Несуществующая запись", post.CategoryID)
+	assert.Equal(t, store.ErrNotFound, s.Posts().Update(ctx, missing))
+}
+
+func testPageUpdateVersion(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	page := Page("/about")
+
+	assert.NoError(t, s.Pages().Create(ctx, page))
+
+	page.Title = "О нас"
+	assert.NoError(t, s.Pages().Update(ctx, page))
+	assert.NoError(t, s.Pages().Update(ctx, page))
+	assert.Equal(t, int64(2), page.Version)
+
+	page.Version = 1
+	assert.Equal(t, store.ErrVersionConflict, s.Pages().Update(ctx, page))
+}
+
+func testPostReassignCategoryVersion(t *testing.T, newStore NewStore) {
+	ctx := context.Background()
+	s := newStore(t)
+	post := Post("Первая запись", primitive.NewObjectID())
+
+	assert.NoError(t, s.Posts().Create(ctx, post))
+
+	n, err := s.Posts().ReassignCategory(ctx, post.CategoryID, primitive.NewObjectID())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// Editor who read post before reassign must not bring old category back
+	assert.Equal(t, store.ErrVersionConflict, s.Posts().Update(ctx, post))
+}
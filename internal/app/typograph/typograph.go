@@ -0,0 +1,148 @@
+// Package typograph applies rules of Russian typography to text:
+// «ёлочки» quotes with „лапки“ inside them, em dashes, ellipsis and non-breaking spaces
+// after short words, inside numbers and before units
+// Processing is idempotent, text processed twice is the same as processed once
+package typograph
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Mode tells when typography is applied to content
+type Mode int
+
+const (
+	Off      Mode = iota // Text goes out as typed
+	OnSave               // Text is processed before post or page is saved
+	OnRender             // Text is processed when page is rendered, saved text is left as typed
+)
+
+// ErrUnknownMode is returned by ParseMode for unsupported name
+var ErrUnknownMode = errors.New("Typograph mode must be one of off, save or render")
+
+// ParseMode returns mode by its name from config
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "", "off":
+		return Off, nil
+	case "save":
+		return OnSave, nil
+	case "render":
+		return OnRender, nil
+	}
+
+	return Off, ErrUnknownMode
+}
+
+const nbsp = "\u00a0"
+
+var (
+	tags     = regexp.MustCompile(`<[^>]*>`)
+	ellipsis = regexp.MustCompile(`\.{3,}`)
+	dialogue = regexp.MustCompile(`^(?:--?|–|—)[ \x{00a0}]+`)
+	dash     = regexp.MustCompile(`(^|[^\s\x{00a0}])[ \x{00a0}]+(?:--?|–|—)[ \x{00a0}]+`)
+	short    = regexp.MustCompile(`(?i)(^|[\s\x{00a0}(«„])(а|в|во|и|к|ко|о|об|обо|от|с|со|у|я|на|по|за|из|изо|до|не|ни|но|да|для|без|под|над|при|про) +`)
+	number   = regexp.MustCompile(`(^|\D)(\d{1,3}) (\d{3})(\D|$)`)
+	unit     = regexp.MustCompile(`(\d) +(%|‰|₽|\$|€|°|руб\.?|коп\.?|тыс\.?|млн\.?|млрд\.?|трлн\.?|шт\.?|г\.|гг\.|кг|км|см|мм|м|лет|год|года|мес\.?|дн\.?)([^\p{L}\d]|$)`)
+	sign     = regexp.MustCompile(`([№§]) *(\d)`)
+)
+
+// Text returns plain text with typography, e.g. title of post
+func Text(text string) string {
+	return process(text, &quotes{})
+}
+
+// HTML returns inline markup with typography applied to its text, tags are kept as is
+// Quotes are paired across tags, so quote opened before <b> is closed after </b>
+func HTML(text string) string {
+	b := &strings.Builder{}
+	q := &quotes{}
+	last := 0
+
+	for _, m := range tags.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(process(html.UnescapeString(text[last:m[0]]), q)))
+		b.WriteString(text[m[0]:m[1]])
+		last = m[1]
+	}
+
+	b.WriteString(html.EscapeString(process(html.UnescapeString(text[last:]), q)))
+
+	return b.String()
+}
+
+func process(text string, q *quotes) string {
+	text = q.replace(text)
+	text = ellipsis.ReplaceAllString(text, "…")
+	text = dialogue.ReplaceAllString(text, "— ")
+	text = dash.ReplaceAllString(text, "${1}"+nbsp+"— ")
+	text = repeat(short, text, "${1}${2}"+nbsp)
+	text = repeat(number, text, "${1}${2}"+nbsp+"${3}${4}")
+	text = unit.ReplaceAllString(text, "${1}"+nbsp+"${2}${3}")
+	text = sign.ReplaceAllString(text, "${1}"+nbsp+"${2}")
+
+	return text
+}
+
+// repeat replaces matches until text stops changing, so adjacent matches sharing separator are replaced too
+func repeat(re *regexp.Regexp, text, repl string) string {
+	for {
+		replaced := re.ReplaceAllString(text, repl)
+		if replaced == text {
+			return text
+		}
+
+		text = replaced
+	}
+}
+
+// quotes replaces straight quotes by «ёлочки» and nested ones by „лапки“
+// Typed quotes of both kinds are counted too, so processed text keeps its nesting
+type quotes struct {
+	prev  rune // Last rune of text seen before, zero at start
+	depth int  // Number of open quotes
+}
+
+func (q *quotes) replace(text string) string {
+	b := &strings.Builder{}
+
+	for _, r := range text {
+		switch r {
+		case '"':
+			if q.opening() {
+				r = '«'
+				if q.depth > 0 {
+					r = '„'
+				}
+				q.depth++
+			} else {
+				r = '»'
+				if q.depth > 1 {
+					r = '“'
+				}
+				if q.depth > 0 {
+					q.depth--
+				}
+			}
+		case '«', '„':
+			q.depth++
+		case '»', '“':
+			if q.depth > 0 {
+				q.depth--
+			}
+		}
+
+		b.WriteRune(r)
+		q.prev = r
+	}
+
+	return b.String()
+}
+
+// opening tells whether quote after previous rune opens quotation
+func (q *quotes) opening() bool {
+	return q.prev == 0 || unicode.IsSpace(q.prev) || strings.ContainsRune("([{«„-–—/", q.prev)
+}
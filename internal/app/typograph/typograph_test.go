@@ -0,0 +1,224 @@
+package typograph
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// update rewrites golden file of corpus with current output: go test ./internal/app/typograph -update
+var update = flag.Bool("update", false, "update golden files")
+
+// visible shows non-breaking spaces as ~, so expected text is readable
+func visible(text string) string {
+	return strings.ReplaceAll(text, nbsp, "~")
+}
+
+func TestText(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Quotes",
+			input: `Программа "1С" для бизнеса`,
+			want:  `Программа «1С» для~бизнеса`,
+		},
+		{
+			name:  "Nested quotes",
+			input: `ООО "Фирма "Восток""`,
+			want:  `ООО «Фирма „Восток“»`,
+		},
+		{
+			name:  "Quote at start and in brackets",
+			input: `"Итоги" ("новые")`,
+			want:  `«Итоги» («новые»)`,
+		},
+		{
+			name:  "Dash between words",
+			input: "Налог - это платеж",
+			want:  "Налог~— это платеж",
+		},
+		{
+			name:  "Double hyphen and en dash",
+			input: "Раз -- два – три",
+			want:  "Раз~— два~— три",
+		},
+		{
+			name:  "Hyphen in word is kept",
+			input: "Из-за 25-го числа",
+			want:  "Из-за 25-го числа",
+		},
+		{
+			name:  "Dialogue",
+			input: "- Когда сдавать?",
+			want:  "— Когда сдавать?",
+		},
+		{
+			name:  "Short words",
+			input: "Отчет в налоговую и в фонд",
+			want:  "Отчет в~налоговую и~в~фонд",
+		},
+		{
+			name:  "Short word in capitals",
+			input: "В течение года",
+			want:  "В~течение года",
+		},
+		{
+			name:  "Longer words are left",
+			input: "Вот вклад",
+			want:  "Вот вклад",
+		},
+		{
+			name:  "Percent",
+			input: "Ставка 15 %",
+			want:  "Ставка 15~%",
+		},
+		{
+			name:  "Thousands and currency",
+			input: "Сумма 1 000 руб.",
+			want:  "Сумма 1~000~руб.",
+		},
+		{
+			name:  "Millions",
+			input: "Лимит 265 800 000 ₽",
+			want:  "Лимит 265~800~000~₽",
+		},
+		{
+			name:  "Year is not joined with next number",
+			input: "В 2021 100 человек",
+			want:  "В~2021 100 человек",
+		},
+		{
+			name:  "Unit is not prefix of word",
+			input: "С 5 марта",
+			want:  "С~5 марта",
+		},
+		{
+			name:  "Year abbreviation",
+			input: "С 2024 г. ставка выросла",
+			want:  "С~2024~г. ставка выросла",
+		},
+		{
+			name:  "Number sign",
+			input: "Форма №4 и § 2",
+			want:  "Форма №~4 и~§~2",
+		},
+		{
+			name:  "Ellipsis",
+			input: "И так далее...",
+			want:  "И~так далее…",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Text(tc.input)
+			assert.Equal(t, tc.want, visible(got))
+			assert.Equal(t, got, Text(got), "typography must be idempotent")
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Tags are kept",
+			input: `<b>Налог</b> - это <a href="/category/news">платеж</a>`,
+			want:  `<b>Налог</b>~— это <a href="/category/news">платеж</a>`,
+		},
+		{
+			name:  "Quotes across tags",
+			input: `Компания "<i>Восток</i>"`,
+			want:  `Компания «<i>Восток</i>»`,
+		},
+		{
+			name:  "Escaped quotes",
+			input: `Программа &#34;1С&#34; &amp; сервисы`,
+			want:  `Программа «1С» &amp; сервисы`,
+		},
+		{
+			name:  "Entities",
+			input: `Сумма&nbsp;1 000 руб.`,
+			want:  `Сумма~1~000~руб.`,
+		},
+		{
+			name:  "Dash after tag",
+			input: `<b>УСН</b> - упрощенная система`,
+			want:  `<b>УСН</b>~— упрощенная система`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := HTML(tc.input)
+			assert.Equal(t, tc.want, visible(got))
+			assert.Equal(t, got, HTML(got), "typography must be idempotent")
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		want    Mode
+		wantErr bool
+	}{
+		{name: "", want: Off},
+		{name: "off", want: Off},
+		{name: "save", want: OnSave},
+		{name: "render", want: OnRender},
+		{name: "always", want: Off, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mode, err := ParseMode(tc.name)
+			assert.Equal(t, tc.want, mode)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+// TestCorpus processes paragraphs of real articles line by line
+// Non-breaking spaces of golden file are shown as ~
+func TestCorpus(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "corpus.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	got := make([]string, len(lines))
+
+	for i, line := range lines {
+		got[i] = Text(line)
+		assert.Equal(t, got[i], Text(got[i]), "typography must be idempotent: %s", line)
+		got[i] = visible(got[i])
+	}
+
+	golden := filepath.Join("testdata", "corpus.golden.txt")
+	output := strings.Join(got, "\n") + "\n"
+
+	if *update {
+		if err = os.WriteFile(golden, []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, string(want), output)
+}